@@ -3,29 +3,75 @@ package main
 import (
 	"github.com/supwr/pismo-transactions/api/handler"
 	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/authorization"
+	"github.com/supwr/pismo-transactions/internal/billing"
+	"github.com/supwr/pismo-transactions/internal/fee"
+	"github.com/supwr/pismo-transactions/internal/idempotency"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"github.com/supwr/pismo-transactions/internal/ledger"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"github.com/supwr/pismo-transactions/internal/outbox"
 	"github.com/supwr/pismo-transactions/internal/transaction"
+	"github.com/supwr/pismo-transactions/internal/webhook"
 	"github.com/supwr/pismo-transactions/pkg/clock"
 	"github.com/supwr/pismo-transactions/pkg/database"
+	"github.com/supwr/pismo-transactions/pkg/logging"
+	"github.com/supwr/pismo-transactions/pkg/metrics"
+	"github.com/supwr/pismo-transactions/pkg/server"
+	"github.com/supwr/pismo-transactions/pkg/tracing"
 
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
+	"gorm.io/gorm"
 	"log/slog"
+	"net/http"
 	"os"
+	"time"
 )
 
 func createApp(o ...fx.Option) *fx.App {
 	options := []fx.Option{
+		// the server bounds its own shutdown with HTTP_SHUTDOWN_DELAY and HTTP_SHUTDOWN_TIMEOUT
+		fx.StopTimeout(time.Minute),
 		database.Module(),
+		metrics.Module(),
+		tracing.Module(),
 		fx.Provide(
 			newLogger,
 			newClock,
+			newRouter,
+			newServer,
+			logging.NewConfig,
+			webhook.NewConfig,
+			server.NewConfig,
+			server.NewState,
 
 			//handlers
 			newAccountHandler,
 			newTransactionHandler,
+			newOperationTypeHandler,
+			newInvoiceHandler,
+			newFeeHandler,
+			newAuthorizationHandler,
+			newWebhookHandler,
+			newHealthHandler,
 
 			//services
 			newAccountService,
 			newTransactionService,
+			newTransactionMetrics,
+			newIdempotencyService,
+			newInstallmentService,
+			newOperationTypeService,
+			newLedgerService,
+			newBillingService,
+			newFeeService,
+			newAuthorizationService,
+			newOutboxService,
+			newWebhookService,
 
 			// repositories
 			fx.Annotate(
@@ -36,30 +82,209 @@ func createApp(o ...fx.Option) *fx.App {
 				transaction.NewRepository,
 				fx.As(new(transaction.RepositoryInterface)),
 			),
+			fx.Annotate(
+				idempotency.NewRepository,
+				fx.As(new(idempotency.RepositoryInterface)),
+			),
+			fx.Annotate(
+				installment.NewRepository,
+				fx.As(new(installment.RepositoryInterface)),
+			),
+			fx.Annotate(
+				operationtype.NewRepository,
+				fx.As(new(operationtype.RepositoryInterface)),
+			),
+			fx.Annotate(
+				ledger.NewRepository,
+				fx.As(new(ledger.RepositoryInterface)),
+			),
+			fx.Annotate(
+				billing.NewRepository,
+				fx.As(new(billing.RepositoryInterface)),
+			),
+			fx.Annotate(
+				fee.NewRepository,
+				fx.As(new(fee.RepositoryInterface)),
+			),
+			fx.Annotate(
+				authorization.NewRepository,
+				fx.As(new(authorization.RepositoryInterface)),
+			),
+			fx.Annotate(
+				outbox.NewRepository,
+				fx.As(new(outbox.RepositoryInterface)),
+			),
+			fx.Annotate(
+				webhook.NewRepository,
+				fx.As(new(webhook.RepositoryInterface)),
+			),
 		),
 	}
 
 	return fx.New(append(options, o...)...)
 }
 
-func newLogger() *slog.Logger {
-	return slog.New(slog.NewTextHandler(os.Stderr, nil))
+func newLogger(cfg logging.Config) (*slog.Logger, error) {
+	h, err := logging.NewHandler(os.Stderr, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return slog.New(tracing.NewLogHandler(h)), nil
+}
+
+func newRouter(m *metrics.HTTP, tp trace.TracerProvider, cfg tracing.Config, l *slog.Logger) *gin.Engine {
+	// debug mode prints the routes and warnings to stdout, bypassing the logger
+	gin.SetMode(gin.ReleaseMode)
+
+	api := gin.New()
+	// handlers hand the gin context down to the services and the logger, which must see the span and the request id
+	api.ContextWithFallback = true
+	api.Use(
+		otelgin.Middleware(cfg.ServiceName, otelgin.WithTracerProvider(tp), otelgin.WithFilter(observed)),
+		logging.RequestIDMiddleware(),
+		logging.AccessLog(l, observed),
+		m.Middleware(),
+		handler.Problems(),
+		logging.Recovery(l),
+	)
+	api.NoRoute(handler.RouteNotFound)
+
+	return api
+}
+
+// observed leaves the requests of probes and scrapers out of the traces and the access log.
+func observed(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/healthz", "/readyz", "/metrics":
+		return false
+	}
+
+	return true
+}
+
+func newServer(
+	lc fx.Lifecycle,
+	s fx.Shutdowner,
+	cfg server.Config,
+	state *server.State,
+	api *gin.Engine,
+	l *slog.Logger,
+) *http.Server {
+	return server.NewServer(lc, s, cfg, state, api, l)
+}
+
+func newAccountHandler(s *account.Service, ls *ledger.Service, l *slog.Logger) *handler.AccountHandler {
+	return handler.NewAccountHandler(s, ls, l)
+}
+
+func newTransactionHandler(s *transaction.Service, i *idempotency.Service, l *slog.Logger) *handler.TransactionHandler {
+	return handler.NewTransactionHandler(s, i, l)
+}
+
+func newOperationTypeHandler(s *operationtype.Service, l *slog.Logger) *handler.OperationTypeHandler {
+	return handler.NewOperationTypeHandler(s, l)
+}
+
+func newInvoiceHandler(s *billing.Service, l *slog.Logger) *handler.InvoiceHandler {
+	return handler.NewInvoiceHandler(s, l)
+}
+
+func newFeeHandler(s *fee.Service, l *slog.Logger) *handler.FeeHandler {
+	return handler.NewFeeHandler(s, l)
+}
+
+func newAuthorizationHandler(s *authorization.Service, l *slog.Logger) *handler.AuthorizationHandler {
+	return handler.NewAuthorizationHandler(s, l)
+}
+
+func newWebhookHandler(s *webhook.Service, l *slog.Logger) *handler.WebhookHandler {
+	return handler.NewWebhookHandler(s, l)
+}
+
+func newAccountService(r account.RepositoryInterface, o *outbox.Service, c clock.Clock, tm database.TxManager) *account.Service {
+	return account.NewService(r, o, c, tm)
+}
+
+func newTransactionService(
+	r transaction.RepositoryInterface,
+	a *account.Service,
+	i *installment.Service,
+	o *operationtype.Service,
+	ls *ledger.Service,
+	e *outbox.Service,
+	m *transaction.Metrics,
+	c clock.Clock,
+	tm database.TxManager,
+) *transaction.Service {
+	return transaction.NewService(r, a, i, o, ls, e, m, c, tm)
+}
+
+func newTransactionMetrics(r prometheus.Registerer) *transaction.Metrics {
+	return transaction.NewMetrics(r)
+}
+
+func newIdempotencyService(r idempotency.RepositoryInterface, tm database.TxManager) *idempotency.Service {
+	return idempotency.NewService(r, tm)
+}
+
+func newInstallmentService(r installment.RepositoryInterface) *installment.Service {
+	return installment.NewService(r)
+}
+
+func newOperationTypeService(r operationtype.RepositoryInterface, c clock.Clock) *operationtype.Service {
+	return operationtype.NewService(r, c)
+}
+
+func newLedgerService(r ledger.RepositoryInterface) *ledger.Service {
+	return ledger.NewService(r)
+}
+
+func newBillingService(
+	r billing.RepositoryInterface,
+	a *account.Service,
+	i *installment.Service,
+	o *operationtype.Service,
+	t *transaction.Service,
+	c clock.Clock,
+	tm database.TxManager,
+) *billing.Service {
+	return billing.NewService(r, a, i, o, t, c, tm)
+}
+
+func newFeeService(
+	r fee.RepositoryInterface,
+	a *account.Service,
+	b *billing.Service,
+	o *operationtype.Service,
+	t *transaction.Service,
+	c clock.Clock,
+	tm database.TxManager,
+) *fee.Service {
+	return fee.NewService(r, a, b, o, t, c, tm)
 }
 
-func newAccountHandler(s *account.Service, l *slog.Logger) *handler.AccountHandler {
-	return handler.NewAccountHandler(s, l)
+func newAuthorizationService(
+	r authorization.RepositoryInterface,
+	a *account.Service,
+	o *operationtype.Service,
+	t *transaction.Service,
+	c clock.Clock,
+	tm database.TxManager,
+) *authorization.Service {
+	return authorization.NewService(r, a, o, t, c, tm)
 }
 
-func newTransactionHandler(s *transaction.Service, l *slog.Logger) *handler.TransactionHandler {
-	return handler.NewTransactionHandler(s, l)
+func newOutboxService(r outbox.RepositoryInterface, c clock.Clock) *outbox.Service {
+	return outbox.NewService(r, c)
 }
 
-func newAccountService(r account.RepositoryInterface) *account.Service {
-	return account.NewService(r)
+func newWebhookService(r webhook.RepositoryInterface, c clock.Clock, tm database.TxManager, cfg webhook.Config) *webhook.Service {
+	return webhook.NewService(r, c, tm, cfg)
 }
 
-func newTransactionService(r transaction.RepositoryInterface, a *account.Service, c clock.Clock) *transaction.Service {
-	return transaction.NewService(r, a, c)
+func newHealthHandler(db *gorm.DB, m *database.Migration, s *server.State, l *slog.Logger) *handler.HealthHandler {
+	return handler.NewHealthHandler(db, m, s, l)
 }
 
 func newClock() clock.Clock {
@@ -5,30 +5,78 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/shopspring/decimal"
 	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/ledger"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type AccountInputDTO struct {
-	DocumentNumber       account.Document `json:"document_number" swaggertype:"string" validate:"required"`
-	AvailableCreditLimit decimal.Decimal  `json:"available_credit_limit" validate:"required"`
+	DocumentNumber account.Document `json:"document_number" swaggertype:"string" validate:"required,document"`
+	CreditLimit    *decimal.Decimal `json:"credit_limit" swaggertype:"number" validate:"required_without=AvailableCreditLimit"`
+	// Deprecated: use credit_limit. Still accepted as the account's credit limit for older clients.
+	AvailableCreditLimit *decimal.Decimal `json:"available_credit_limit" swaggertype:"number"`
+	// ClosingDay and DueDay are the days of the month the invoice closes and is due, 3 and 10 when left out.
+	ClosingDay int `json:"closing_day" validate:"omitempty,min=1,max=28"`
+	DueDay     int `json:"due_day" validate:"omitempty,min=1,max=28"`
+}
+
+type CreditLimitInputDTO struct {
+	CreditLimit *decimal.Decimal `json:"credit_limit" swaggertype:"number" validate:"required"`
+	Reason      string           `json:"reason" validate:"required,max=255"`
+	Actor       string           `json:"actor" validate:"required,max=255"`
+}
+
+type CreditLimitChangeOutputDTO struct {
+	PreviousCreditLimit          decimal.Decimal `json:"previous_credit_limit"`
+	CreditLimit                  decimal.Decimal `json:"credit_limit"`
+	PreviousAvailableCreditLimit decimal.Decimal `json:"previous_available_credit_limit"`
+	AvailableCreditLimit         decimal.Decimal `json:"available_credit_limit"`
+	Reason                       string          `json:"reason"`
+	Actor                        string          `json:"actor"`
+	CreatedAt                    time.Time       `json:"created_at"`
+}
+
+type AccountPatchInputDTO struct {
+	DocumentNumber *account.Document `json:"document_number" swaggertype:"string" validate:"omitempty,document"`
+	Status         *string           `json:"status" validate:"omitempty,oneof=ACTIVE BLOCKED CLOSED"`
+	// Reason is required when blocking or closing the account.
+	Reason     string `json:"reason" validate:"max=255"`
+	ClosingDay *int   `json:"closing_day" validate:"omitempty,min=1,max=28"`
+	DueDay     *int   `json:"due_day" validate:"omitempty,min=1,max=28"`
 }
 
 type AccountOutputDTO struct {
 	AccountID            int              `json:"account_id"`
 	DocumentNumber       account.Document `json:"document_number" swaggertype:"string"`
+	DocumentType         string           `json:"document_type"`
+	CreditLimit          decimal.Decimal  `json:"credit_limit"`
 	AvailableCreditLimit decimal.Decimal  `json:"available_credit_limit"`
+	Status               string           `json:"status"`
+	StatusReason         string           `json:"status_reason,omitempty"`
+	StatusChangedAt      *time.Time       `json:"status_changed_at,omitempty"`
+	ClosingDay           int              `json:"closing_day"`
+	DueDay               int              `json:"due_day"`
+}
+
+type AccountBalanceOutputDTO struct {
+	AccountID   int             `json:"account_id"`
+	Receivables decimal.Decimal `json:"receivables"`
+	Credit      decimal.Decimal `json:"credit"`
+	Balance     decimal.Decimal `json:"balance"`
 }
 
 type AccountHandler struct {
 	AccountService *account.Service
+	ledgerService  *ledger.Service
 	logger         *slog.Logger
 }
 
-func NewAccountHandler(s *account.Service, l *slog.Logger) *AccountHandler {
+func NewAccountHandler(s *account.Service, ls *ledger.Service, l *slog.Logger) *AccountHandler {
 	return &AccountHandler{
 		AccountService: s,
+		ledgerService:  ls,
 		logger:         l,
 	}
 }
@@ -41,47 +89,41 @@ func NewAccountHandler(s *account.Service, l *slog.Logger) *AccountHandler {
 // @Produce      json
 // @Param        request   body      AccountInputDTO  true  "Account properties"
 // @Success      201
-// @Failure      500
-// @Failure      400
+// @Failure      500 {object} Problem
+// @Failure      400 {object} Problem
 // @Router       /accounts [post]
 func (h *AccountHandler) CreateAccount(ctx *gin.Context) {
 	var err error
 	var input AccountInputDTO
 
-	if err = ctx.BindJSON(&input); err != nil {
+	if err = ctx.ShouldBindJSON(&input); err != nil {
 		h.logger.ErrorContext(ctx, "error reading body", slog.Any("error", err))
-		ctx.JSON(http.StatusInternalServerError, nil)
+		_ = ctx.Error(ErrMalformedBody)
 		return
 	}
 
 	validation := validate(input).Errors
 	if len(validation) > 0 {
-		h.logger.ErrorContext(ctx, "invalid payload", slog.Any("error", err))
-		ctx.JSON(http.StatusBadRequest, validation)
+		h.logger.ErrorContext(ctx, "invalid payload", slog.Any("validation", validation))
+		_ = ctx.Error(&ValidationError{Fields: validation})
 		return
 	}
 
-	if input.AvailableCreditLimit.LessThan(decimal.Zero) {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
-		return
+	creditLimit := input.CreditLimit
+	if creditLimit == nil {
+		creditLimit = input.AvailableCreditLimit
 	}
 
-	acc := &account.Account{Document: input.DocumentNumber, AvailableCreditLimit: input.AvailableCreditLimit}
+	acc := &account.Account{
+		Document:    input.DocumentNumber,
+		CreditLimit: *creditLimit,
+		ClosingDay:  input.ClosingDay,
+		DueDay:      input.DueDay,
+	}
 
 	if err = h.AccountService.Create(ctx, acc); err != nil {
 		h.logger.ErrorContext(ctx, "error creating account", slog.Any("error", err))
-		if errors.Is(err, account.ErrAccountAlreadyExists) {
-			ctx.JSON(http.StatusBadRequest, gin.H{
-				"error": err.Error(),
-			})
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": ErrCreateAccount.Error(),
-		})
+		_ = ctx.Error(errors.Join(err, ErrCreateAccount))
 		return
 	}
 
@@ -97,39 +139,237 @@ func (h *AccountHandler) CreateAccount(ctx *gin.Context) {
 // @Produce      json
 // @Param        accountId   path      integer  true  "Account id"
 // @Success      200 {object} AccountOutputDTO
-// @Failure      500
-// @Failure      404
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
 // @Router       /accounts/{accountId} [get]
 func (h *AccountHandler) GetAccountById(ctx *gin.Context) {
 	id, err := strconv.Atoi(ctx.Param("accountId"))
 	if err != nil {
 		h.logger.ErrorContext(ctx, "error getting account id", slog.Any("error", err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": err,
+		_ = ctx.Error(invalidField("accountId"))
+		return
+	}
+
+	acc, err := h.AccountService.FindById(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error finding account by id", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	if acc == nil {
+		h.logger.ErrorContext(ctx, "account not found")
+		_ = ctx.Error(account.ErrAccountNotFound)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "account found successfully", slog.Any("account", acc))
+	ctx.JSON(http.StatusOK, newAccountOutputDTO(acc))
+}
+
+// UpdateAccount godoc
+// @Summary      Update account
+// @Description  Change the account document, status or billing days. Blocked accounts only accept payments, and closing requires a zero balance.
+// @Tags         Accounts
+// @Accept       json
+// @Produce      json
+// @Param        accountId   path      integer               true  "Account id"
+// @Param        request     body      AccountPatchInputDTO  true  "Account properties to change"
+// @Success      200 {object} AccountOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      422 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /accounts/{accountId} [patch]
+func (h *AccountHandler) UpdateAccount(ctx *gin.Context) {
+	var input AccountPatchInputDTO
+
+	id, err := strconv.Atoi(ctx.Param("accountId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting account id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("accountId"))
+		return
+	}
+
+	if err = ctx.ShouldBindJSON(&input); err != nil {
+		h.logger.ErrorContext(ctx, "error reading body", slog.Any("error", err))
+		_ = ctx.Error(ErrMalformedBody)
+		return
+	}
+
+	validation := validate(input).Errors
+	if len(validation) > 0 {
+		h.logger.ErrorContext(ctx, "invalid payload", slog.Any("validation", validation))
+		_ = ctx.Error(&ValidationError{Fields: validation})
+		return
+	}
+
+	acc, err := h.AccountService.Update(ctx, id, account.Patch{
+		Document:   input.DocumentNumber,
+		Status:     input.Status,
+		Reason:     input.Reason,
+		ClosingDay: input.ClosingDay,
+		DueDay:     input.DueDay,
+	})
+
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error updating account", slog.Any("error", err))
+		_ = ctx.Error(errors.Join(err, ErrUpdateAccount))
+		return
+	}
+
+	h.logger.InfoContext(ctx, "account updated successfully", slog.Any("account", acc))
+	ctx.JSON(http.StatusOK, newAccountOutputDTO(acc))
+}
+
+// ChangeCreditLimit godoc
+// @Summary      Change credit limit
+// @Description  Set the account credit limit. The available limit moves by the same amount, so what is already in use stays in use.
+// @Tags         Accounts
+// @Accept       json
+// @Produce      json
+// @Param        accountId   path      integer              true  "Account id"
+// @Param        request     body      CreditLimitInputDTO  true  "New credit limit"
+// @Success      200 {object} AccountOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      422 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /accounts/{accountId}/credit-limit [put]
+func (h *AccountHandler) ChangeCreditLimit(ctx *gin.Context) {
+	var input CreditLimitInputDTO
+
+	id, err := strconv.Atoi(ctx.Param("accountId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting account id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("accountId"))
+		return
+	}
+
+	if err = ctx.ShouldBindJSON(&input); err != nil {
+		h.logger.ErrorContext(ctx, "error reading body", slog.Any("error", err))
+		_ = ctx.Error(ErrMalformedBody)
+		return
+	}
+
+	validation := validate(input).Errors
+	if len(validation) > 0 {
+		h.logger.ErrorContext(ctx, "invalid payload", slog.Any("validation", validation))
+		_ = ctx.Error(&ValidationError{Fields: validation})
+		return
+	}
+
+	acc, err := h.AccountService.ChangeCreditLimit(ctx, id, *input.CreditLimit, input.Reason, input.Actor)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error changing credit limit", slog.Any("error", err))
+		_ = ctx.Error(errors.Join(err, ErrUpdateAccount))
+		return
+	}
+
+	h.logger.InfoContext(ctx, "credit limit changed successfully", slog.Any("account", acc))
+	ctx.JSON(http.StatusOK, newAccountOutputDTO(acc))
+}
+
+// GetCreditLimitHistory godoc
+// @Summary      Show credit limit history
+// @Description  List the credit limit changes of the account, newest first
+// @Tags         Accounts
+// @Produce      json
+// @Param        accountId   path      integer  true  "Account id"
+// @Success      200 {array} CreditLimitChangeOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /accounts/{accountId}/credit-limit/history [get]
+func (h *AccountHandler) GetCreditLimitHistory(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("accountId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting account id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("accountId"))
+		return
+	}
+
+	changes, err := h.AccountService.CreditLimitHistory(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error finding credit limit history", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	output := make([]CreditLimitChangeOutputDTO, 0, len(changes))
+	for _, c := range changes {
+		output = append(output, CreditLimitChangeOutputDTO{
+			PreviousCreditLimit:          c.PreviousCreditLimit,
+			CreditLimit:                  c.CreditLimit,
+			PreviousAvailableCreditLimit: c.PreviousAvailableCreditLimit,
+			AvailableCreditLimit:         c.AvailableCreditLimit,
+			Reason:                       c.Reason,
+			Actor:                        c.Actor,
+			CreatedAt:                    c.CreatedAt,
 		})
+	}
+
+	ctx.JSON(http.StatusOK, output)
+}
 
+// GetAccountBalance godoc
+// @Summary      Show account balance
+// @Description  Get the account balance derived from its ledger postings: what it owes, the credit it has left and the net of both
+// @Tags         Accounts
+// @Produce      json
+// @Param        accountId   path      integer  true  "Account id"
+// @Success      200 {object} AccountBalanceOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /accounts/{accountId}/balance [get]
+func (h *AccountHandler) GetAccountBalance(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("accountId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting account id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("accountId"))
 		return
 	}
 
 	acc, err := h.AccountService.FindById(ctx, id)
 	if err != nil {
 		h.logger.ErrorContext(ctx, "error finding account by id", slog.Any("error", err))
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+		_ = ctx.Error(err)
 		return
 	}
 
 	if acc == nil {
 		h.logger.ErrorContext(ctx, "account not found")
-		ctx.JSON(http.StatusNotFound, nil)
+		_ = ctx.Error(account.ErrAccountNotFound)
 		return
 	}
 
-	h.logger.InfoContext(ctx, "account found successfully", slog.Any("account", acc))
-	ctx.JSON(http.StatusOK, AccountOutputDTO{
+	balance, err := h.ledgerService.Balance(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting account balance", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AccountBalanceOutputDTO{
+		AccountID:   balance.AccountID,
+		Receivables: balance.Receivables,
+		Credit:      balance.Credit,
+		Balance:     balance.Net(),
+	})
+}
+
+func newAccountOutputDTO(acc *account.Account) AccountOutputDTO {
+	return AccountOutputDTO{
 		AccountID:            acc.ID,
 		DocumentNumber:       acc.Document,
+		DocumentType:         acc.DocumentType,
+		CreditLimit:          acc.CreditLimit,
 		AvailableCreditLimit: acc.AvailableCreditLimit,
-	})
+		Status:               acc.Status,
+		StatusReason:         acc.StatusReason,
+		StatusChangedAt:      acc.StatusChangedAt,
+		ClosingDay:           acc.ClosingDay,
+		DueDay:               acc.DueDay,
+	}
 }
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/supwr/pismo-transactions/internal/account"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAccountHandler_ChangeCreditLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("missing credit limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// the repository has no expectations, so reaching the service fails the test
+		repo := account.NewMockRepositoryInterface(ctrl)
+		h := NewAccountHandler(account.NewService(repo, nil, nil, nil), nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+		api := gin.New()
+		api.Use(Problems())
+		api.PUT("/accounts/:accountId/credit-limit", h.ChangeCreditLimit)
+
+		body := strings.NewReader(`{"reason":"customer request","actor":"ops"}`)
+		res := httptest.NewRecorder()
+		api.ServeHTTP(res, httptest.NewRequest(http.MethodPut, "/accounts/1/credit-limit", body))
+
+		var problem Problem
+		assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &problem))
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, CodeValidationFailed, problem.Code)
+		assert.Len(t, problem.Errors, 1)
+		assert.Equal(t, "creditlimit", problem.Errors[0].Name)
+	})
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/supwr/pismo-transactions/internal/authorization"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AuthorizationInputDTO struct {
+	AccountId       int             `json:"account_id" validate:"required"`
+	OperationTypeId int             `json:"operation_type_id" validate:"required"`
+	Amount          decimal.Decimal `json:"amount" validate:"required"`
+	Installments    int             `json:"installments,omitempty" validate:"omitempty,min=1,max=24"`
+	InterestRate    decimal.Decimal `json:"interest_rate"`
+}
+
+type CaptureInputDTO struct {
+	// Amount to capture; the whole authorized amount is captured when omitted.
+	Amount *decimal.Decimal `json:"amount" swaggertype:"number"`
+}
+
+type AuthorizationOutputDTO struct {
+	AuthorizationID int             `json:"authorization_id"`
+	AccountID       int             `json:"account_id"`
+	OperationTypeID int             `json:"operation_type_id"`
+	Amount          decimal.Decimal `json:"amount"`
+	CapturedAmount  decimal.Decimal `json:"captured_amount"`
+	Installments    int             `json:"installments"`
+	InterestRate    decimal.Decimal `json:"interest_rate"`
+	Status          string          `json:"status"`
+	TransactionID   *int            `json:"transaction_id,omitempty"`
+	ExpiresAt       time.Time       `json:"expires_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+type AuthorizationHandler struct {
+	authorizationService *authorization.Service
+	logger               *slog.Logger
+}
+
+func NewAuthorizationHandler(s *authorization.Service, l *slog.Logger) *AuthorizationHandler {
+	return &AuthorizationHandler{
+		authorizationService: s,
+		logger:               l,
+	}
+}
+
+// CreateAuthorization godoc
+// @Summary      Create authorization
+// @Description  Hold the amount of a purchase or withdraw on the account's available limit, to be captured or voided later. Holds not captured in 7 days expire.
+// @Tags         Authorizations
+// @Accept       json
+// @Produce      json
+// @Param        request   body      AuthorizationInputDTO  true  "Authorization properties"
+// @Success      201 {object} AuthorizationOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      422 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /authorizations [post]
+func (h *AuthorizationHandler) CreateAuthorization(ctx *gin.Context) {
+	var input AuthorizationInputDTO
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		h.logger.ErrorContext(ctx, "error reading body", slog.Any("error", err))
+		_ = ctx.Error(ErrMalformedBody)
+		return
+	}
+
+	validation := validate(input).Errors
+	if len(validation) > 0 {
+		h.logger.ErrorContext(ctx, "invalid payload", slog.Any("validation", validation))
+		_ = ctx.Error(&ValidationError{Fields: validation})
+		return
+	}
+
+	a := &authorization.Authorization{
+		AccountID:       input.AccountId,
+		OperationTypeID: input.OperationTypeId,
+		Amount:          input.Amount,
+		Installments:    input.Installments,
+		InterestRate:    input.InterestRate,
+	}
+
+	if err := h.authorizationService.Authorize(ctx, a); err != nil {
+		h.logger.ErrorContext(ctx, "error creating authorization", slog.Any("error", err))
+		_ = ctx.Error(errors.Join(err, ErrCreateAuthorization))
+		return
+	}
+
+	h.logger.InfoContext(ctx, "authorization created successfully", slog.Any("authorization", a))
+	ctx.JSON(http.StatusCreated, newAuthorizationOutputDTO(a))
+}
+
+// GetAuthorizationById godoc
+// @Summary      Show authorization details
+// @Description  Get authorization by id
+// @Tags         Authorizations
+// @Produce      json
+// @Param        authorizationId   path      integer  true  "Authorization id"
+// @Success      200 {object} AuthorizationOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /authorizations/{authorizationId} [get]
+func (h *AuthorizationHandler) GetAuthorizationById(ctx *gin.Context) {
+	id, ok := h.authorizationID(ctx)
+	if !ok {
+		return
+	}
+
+	a, err := h.authorizationService.FindById(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error finding authorization by id", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	if a == nil {
+		h.logger.ErrorContext(ctx, "authorization not found")
+		_ = ctx.Error(authorization.ErrAuthorizationNotFound)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAuthorizationOutputDTO(a))
+}
+
+// CaptureAuthorization godoc
+// @Summary      Capture authorization
+// @Description  Settle an authorization, fully or partially, as a transaction. Whatever isn't captured goes back to the available limit.
+// @Tags         Authorizations
+// @Accept       json
+// @Produce      json
+// @Param        authorizationId   path      integer          true   "Authorization id"
+// @Param        request           body      CaptureInputDTO  false  "Capture properties"
+// @Success      201 {object} TransactionOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      422 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /authorizations/{authorizationId}/capture [post]
+func (h *AuthorizationHandler) CaptureAuthorization(ctx *gin.Context) {
+	var input CaptureInputDTO
+
+	id, ok := h.authorizationID(ctx)
+	if !ok {
+		return
+	}
+
+	// the body is optional, an empty one captures the whole authorized amount
+	if err := ctx.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.ErrorContext(ctx, "error reading body", slog.Any("error", err))
+		_ = ctx.Error(ErrMalformedBody)
+		return
+	}
+
+	t, err := h.authorizationService.Capture(ctx, id, input.Amount)
+	if err != nil {
+		h.respondAuthorizationError(ctx, "error capturing authorization", err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "authorization captured successfully", slog.Any("transaction", t))
+	ctx.JSON(http.StatusCreated, newTransactionOutputDTO(t))
+}
+
+// VoidAuthorization godoc
+// @Summary      Void authorization
+// @Description  Cancel an authorization and give its hold back to the available limit
+// @Tags         Authorizations
+// @Produce      json
+// @Param        authorizationId   path      integer  true  "Authorization id"
+// @Success      200 {object} AuthorizationOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      422 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /authorizations/{authorizationId}/void [post]
+func (h *AuthorizationHandler) VoidAuthorization(ctx *gin.Context) {
+	id, ok := h.authorizationID(ctx)
+	if !ok {
+		return
+	}
+
+	a, err := h.authorizationService.Void(ctx, id)
+	if err != nil {
+		h.respondAuthorizationError(ctx, "error voiding authorization", err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "authorization voided successfully", slog.Any("authorization", a))
+	ctx.JSON(http.StatusOK, newAuthorizationOutputDTO(a))
+}
+
+func (h *AuthorizationHandler) authorizationID(ctx *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param("authorizationId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting authorization id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("authorizationId"))
+		return 0, false
+	}
+
+	return id, true
+}
+
+func (h *AuthorizationHandler) respondAuthorizationError(ctx *gin.Context, message string, err error) {
+	h.logger.ErrorContext(ctx, message, slog.Any("error", err))
+	_ = ctx.Error(errors.Join(err, ErrUpdateAuthorization))
+}
+
+func newAuthorizationOutputDTO(a *authorization.Authorization) *AuthorizationOutputDTO {
+	return &AuthorizationOutputDTO{
+		AuthorizationID: a.ID,
+		AccountID:       a.AccountID,
+		OperationTypeID: a.OperationTypeID,
+		Amount:          a.Amount,
+		CapturedAmount:  a.CapturedAmount,
+		Installments:    a.Installments,
+		InterestRate:    a.InterestRate,
+		Status:          a.Status,
+		TransactionID:   a.TransactionID,
+		ExpiresAt:       a.ExpiresAt,
+		CreatedAt:       a.CreatedAt,
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/supwr/pismo-transactions/internal/fee"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type FeeRateInputDTO struct {
+	Kind string          `json:"kind" validate:"required,oneof=REVOLVING_INTEREST LATE_FEE LATE_INTEREST"`
+	Rate decimal.Decimal `json:"rate" swaggertype:"number"`
+	// OperationTypeID is the internal operation type fees are posted with. Defaults to the one of the current rate.
+	OperationTypeID int `json:"operation_type_id"`
+	// ValidFrom is the first day the rate is charged, as YYYY-MM-DD.
+	ValidFrom string `json:"valid_from" validate:"required,datetime=2006-01-02"`
+}
+
+type FeeRateOutputDTO struct {
+	FeeRateID       int             `json:"fee_rate_id"`
+	Kind            string          `json:"kind"`
+	Rate            decimal.Decimal `json:"rate"`
+	OperationTypeID int             `json:"operation_type_id"`
+	ValidFrom       string          `json:"valid_from"`
+}
+
+type FeeHandler struct {
+	feeService *fee.Service
+	logger     *slog.Logger
+}
+
+func NewFeeHandler(s *fee.Service, l *slog.Logger) *FeeHandler {
+	return &FeeHandler{
+		feeService: s,
+		logger:     l,
+	}
+}
+
+// ListFeeRates godoc
+// @Summary      List fee rates
+// @Description  List the rates of revolving interest, late fee and late interest, including past and scheduled ones. Interest rates are monthly.
+// @Tags         Fees
+// @Produce      json
+// @Success      200 {array} FeeRateOutputDTO
+// @Failure      500 {object} Problem
+// @Router       /fee-rates [get]
+func (h *FeeHandler) ListFeeRates(ctx *gin.Context) {
+	rates, err := h.feeService.Rates(ctx)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error listing fee rates", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	output := make([]FeeRateOutputDTO, 0, len(rates))
+	for i := range rates {
+		output = append(output, newFeeRateOutputDTO(&rates[i]))
+	}
+
+	ctx.JSON(http.StatusOK, output)
+}
+
+// CreateFeeRate godoc
+// @Summary      Create fee rate
+// @Description  Schedule a new rate for a kind of fee. It replaces the current one from valid_from on.
+// @Tags         Fees
+// @Accept       json
+// @Produce      json
+// @Param        request   body      FeeRateInputDTO  true  "Fee rate properties"
+// @Success      201 {object} FeeRateOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /fee-rates [post]
+func (h *FeeHandler) CreateFeeRate(ctx *gin.Context) {
+	var input FeeRateInputDTO
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		h.logger.ErrorContext(ctx, "error reading body", slog.Any("error", err))
+		_ = ctx.Error(ErrMalformedBody)
+		return
+	}
+
+	validation := validate(input).Errors
+	if len(validation) > 0 {
+		h.logger.ErrorContext(ctx, "invalid payload", slog.Any("validation", validation))
+		_ = ctx.Error(&ValidationError{Fields: validation})
+		return
+	}
+
+	validFrom, _ := time.Parse(time.DateOnly, input.ValidFrom)
+
+	rate := &fee.Rate{
+		Kind:            input.Kind,
+		Rate:            input.Rate,
+		OperationTypeID: input.OperationTypeID,
+		ValidFrom:       validFrom,
+	}
+
+	if err := h.feeService.CreateRate(ctx, rate); err != nil {
+		h.logger.ErrorContext(ctx, "error creating fee rate", slog.Any("error", err))
+		_ = ctx.Error(errors.Join(err, ErrCreateFeeRate))
+		return
+	}
+
+	h.logger.InfoContext(ctx, "fee rate created successfully", slog.Any("rate", rate))
+	ctx.JSON(http.StatusCreated, newFeeRateOutputDTO(rate))
+}
+
+func newFeeRateOutputDTO(r *fee.Rate) FeeRateOutputDTO {
+	return FeeRateOutputDTO{
+		FeeRateID:       r.ID,
+		Kind:            r.Kind,
+		Rate:            r.Rate,
+		OperationTypeID: r.OperationTypeID,
+		ValidFrom:       r.ValidFrom.Format(time.DateOnly),
+	}
+}
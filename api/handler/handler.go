@@ -3,12 +3,26 @@ package handler
 import (
 	"errors"
 	"github.com/go-playground/validator/v10"
+	"github.com/supwr/pismo-transactions/internal/account"
 	"strings"
 )
 
 var (
-	ErrCreateAccount     = errors.New("Error creating account")
-	ErrCreateTransaction = errors.New("Error creating transaction")
+	ErrCreateAccount       = errors.New("Error creating account")
+	ErrUpdateAccount       = errors.New("Error updating account")
+	ErrCreateTransaction   = errors.New("Error creating transaction")
+	ErrReverseTransaction  = errors.New("Error reversing transaction")
+	ErrSaveOperationType   = errors.New("Error saving operation type")
+	ErrCreateFeeRate       = errors.New("Error creating fee rate")
+	ErrCreateAuthorization = errors.New("Error creating authorization")
+	ErrUpdateAuthorization = errors.New("Error updating authorization")
+	ErrCreateWebhook       = errors.New("Error creating webhook")
+	ErrUpdateWebhook       = errors.New("Error updating webhook")
+
+	ErrInternal                = errors.New("Internal server error")
+	ErrMalformedBody           = errors.New("Request body is not valid JSON")
+	ErrRouteNotFound           = errors.New("Route not found")
+	ErrInstallmentPlanNotFound = errors.New("Installment plan not found")
 )
 
 type Validation struct {
@@ -20,19 +34,30 @@ type Field struct {
 	Message string `json:"message"`
 }
 
+// messages overrides the default message for validation tags that need a more specific one.
+var messages = map[string]string{
+	"document": "invalid CPF or CNPJ",
+}
+
 func validate(body interface{}) *Validation {
 	var validation Validation
 	var fields []Field
 
 	v := validator.New(validator.WithRequiredStructEnabled())
+	_ = v.RegisterValidation("document", validateDocument)
 
 	err := v.Struct(body)
 
 	if err != nil {
 		for _, e := range err.(validator.ValidationErrors) {
+			message, ok := messages[e.Tag()]
+			if !ok {
+				message = "invalid or missing field"
+			}
+
 			fields = append(fields, Field{
 				Name:    strings.ToLower(e.Field()),
-				Message: "invalid or missing field",
+				Message: message,
 			})
 		}
 	}
@@ -41,3 +66,8 @@ func validate(body interface{}) *Validation {
 
 	return &validation
 }
+
+func validateDocument(fl validator.FieldLevel) bool {
+	_, err := account.Document(fl.Field().String()).Normalize().Type()
+	return err == nil
+}
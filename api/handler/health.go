@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"github.com/supwr/pismo-transactions/pkg/server"
+	"gorm.io/gorm"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	HealthStatusUp           = "UP"
+	HealthStatusDown         = "DOWN"
+	HealthStatusShuttingDown = "SHUTTING_DOWN"
+)
+
+// checkTimeout bounds each readiness check, so a hanging database fails the check instead of the probe.
+const checkTimeout = 2 * time.Second
+
+type HealthOutputDTO struct {
+	Status string                    `json:"status"`
+	Checks map[string]CheckOutputDTO `json:"checks,omitempty"`
+}
+
+type CheckOutputDTO struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Version and ExpectedVersion are the schema version of the database and the one the code was built with.
+	Version         *uint `json:"version,omitempty"`
+	ExpectedVersion *uint `json:"expected_version,omitempty"`
+	Dirty           bool  `json:"dirty,omitempty"`
+}
+
+type HealthHandler struct {
+	db        *gorm.DB
+	migration *database.Migration
+	state     *server.State
+	logger    *slog.Logger
+}
+
+func NewHealthHandler(db *gorm.DB, m *database.Migration, s *server.State, l *slog.Logger) *HealthHandler {
+	return &HealthHandler{
+		db:        db,
+		migration: m,
+		state:     s,
+		logger:    l,
+	}
+}
+
+// Liveness godoc
+// @Summary      Liveness check
+// @Description  Tell whether the process is up. It doesn't check any dependency, so a database outage doesn't get the API restarted.
+// @Tags         Health
+// @Produce      json
+// @Success      200 {object} HealthOutputDTO
+// @Router       /healthz [get]
+func (h *HealthHandler) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, HealthOutputDTO{Status: HealthStatusUp})
+}
+
+// Readiness godoc
+// @Summary      Readiness check
+// @Description  Tell whether the API can take traffic: the database answers and has every migration the code expects. It fails while the API is shutting down.
+// @Tags         Health
+// @Produce      json
+// @Success      200 {object} HealthOutputDTO
+// @Failure      503 {object} HealthOutputDTO
+// @Router       /readyz [get]
+func (h *HealthHandler) Readiness(ctx *gin.Context) {
+	if h.state.Stopping() {
+		ctx.JSON(http.StatusServiceUnavailable, HealthOutputDTO{Status: HealthStatusShuttingDown})
+		return
+	}
+
+	output := HealthOutputDTO{
+		Status: HealthStatusUp,
+		Checks: map[string]CheckOutputDTO{
+			"database":   h.checkDatabase(ctx),
+			"migrations": h.checkMigrations(ctx),
+		},
+	}
+
+	status := http.StatusOK
+
+	for name, check := range output.Checks {
+		if check.Status != HealthStatusUp {
+			h.logger.WarnContext(ctx, "readiness check failed", slog.String("check", name), slog.String("error", check.Error))
+			output.Status = HealthStatusDown
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	ctx.JSON(status, output)
+}
+
+func (h *HealthHandler) checkDatabase(ctx context.Context) CheckOutputDTO {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	db, err := h.db.DB()
+	if err == nil {
+		err = db.PingContext(ctx)
+	}
+
+	if err != nil {
+		return CheckOutputDTO{Status: HealthStatusDown, Error: err.Error()}
+	}
+
+	return CheckOutputDTO{Status: HealthStatusUp}
+}
+
+func (h *HealthHandler) checkMigrations(ctx context.Context) CheckOutputDTO {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	status, err := h.migration.Status(ctx)
+	if err != nil {
+		return CheckOutputDTO{Status: HealthStatusDown, Error: err.Error()}
+	}
+
+	check := CheckOutputDTO{
+		Status:          HealthStatusUp,
+		Version:         &status.Version,
+		ExpectedVersion: &status.Expected,
+		Dirty:           status.Dirty,
+	}
+
+	if !status.UpToDate() {
+		check.Status = HealthStatusDown
+		check.Error = "database schema is behind the code or a migration failed halfway"
+	}
+
+	return check
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/supwr/pismo-transactions/internal/billing"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type InvoiceOutputDTO struct {
+	InvoiceID       int                    `json:"invoice_id,omitempty"`
+	AccountID       int                    `json:"account_id"`
+	PeriodStart     time.Time              `json:"period_start"`
+	PeriodEnd       time.Time              `json:"period_end"`
+	DueDate         string                 `json:"due_date"`
+	PreviousBalance decimal.Decimal        `json:"previous_balance"`
+	Charges         decimal.Decimal        `json:"charges"`
+	Credits         decimal.Decimal        `json:"credits"`
+	Total           decimal.Decimal        `json:"total"`
+	MinimumPayment  decimal.Decimal        `json:"minimum_payment"`
+	PaidAmount      decimal.Decimal        `json:"paid_amount"`
+	Status          string                 `json:"status"`
+	Items           []InvoiceItemOutputDTO `json:"items,omitempty"`
+}
+
+type InvoiceItemOutputDTO struct {
+	TransactionID *int            `json:"transaction_id,omitempty"`
+	InstallmentID *int            `json:"installment_id,omitempty"`
+	Description   string          `json:"description"`
+	Amount        decimal.Decimal `json:"amount"`
+	OperationDate time.Time       `json:"operation_date"`
+}
+
+type InvoiceHandler struct {
+	billingService *billing.Service
+	logger         *slog.Logger
+}
+
+func NewInvoiceHandler(s *billing.Service, l *slog.Logger) *InvoiceHandler {
+	return &InvoiceHandler{
+		billingService: s,
+		logger:         l,
+	}
+}
+
+// ListAccountInvoices godoc
+// @Summary      List account invoices
+// @Description  List the closed invoices of an account, newest first, without their items
+// @Tags         Invoices
+// @Produce      json
+// @Param        accountId   path      integer  true  "Account id"
+// @Success      200 {array} InvoiceOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /accounts/{accountId}/invoices [get]
+func (h *InvoiceHandler) ListAccountInvoices(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("accountId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting account id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("accountId"))
+		return
+	}
+
+	invoices, err := h.billingService.FindInvoicesByAccount(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error listing invoices", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	output := make([]InvoiceOutputDTO, 0, len(invoices))
+	for i := range invoices {
+		output = append(output, newInvoiceOutputDTO(&invoices[i]))
+	}
+
+	ctx.JSON(http.StatusOK, output)
+}
+
+// GetCurrentInvoice godoc
+// @Summary      Show current invoice
+// @Description  Get the invoice of the cycle still running, with what was posted to it so far
+// @Tags         Invoices
+// @Produce      json
+// @Param        accountId   path      integer  true  "Account id"
+// @Success      200 {object} InvoiceOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /accounts/{accountId}/invoices/current [get]
+func (h *InvoiceHandler) GetCurrentInvoice(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("accountId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting account id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("accountId"))
+		return
+	}
+
+	invoice, err := h.billingService.Current(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting current invoice", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newInvoiceOutputDTO(invoice))
+}
+
+// GetInvoiceById godoc
+// @Summary      Show invoice details
+// @Description  Get a closed invoice by id, with its items
+// @Tags         Invoices
+// @Produce      json
+// @Param        invoiceId   path      integer  true  "Invoice id"
+// @Success      200 {object} InvoiceOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /invoices/{invoiceId} [get]
+func (h *InvoiceHandler) GetInvoiceById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("invoiceId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting invoice id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("invoiceId"))
+		return
+	}
+
+	invoice, err := h.billingService.FindInvoiceById(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error finding invoice", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	if invoice == nil {
+		h.logger.ErrorContext(ctx, "invoice not found")
+		_ = ctx.Error(billing.ErrInvoiceNotFound)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newInvoiceOutputDTO(invoice))
+}
+
+func newInvoiceOutputDTO(i *billing.Invoice) InvoiceOutputDTO {
+	output := InvoiceOutputDTO{
+		InvoiceID:       i.ID,
+		AccountID:       i.AccountID,
+		PeriodStart:     i.PeriodStart,
+		PeriodEnd:       i.PeriodEnd,
+		DueDate:         i.DueDate.Format(time.DateOnly),
+		PreviousBalance: i.PreviousBalance,
+		Charges:         i.Charges,
+		Credits:         i.Credits,
+		Total:           i.Total,
+		MinimumPayment:  i.MinimumPayment,
+		PaidAmount:      i.PaidAmount,
+		Status:          i.Status,
+	}
+
+	for _, item := range i.Items {
+		output.Items = append(output.Items, InvoiceItemOutputDTO{
+			TransactionID: item.TransactionID,
+			InstallmentID: item.InstallmentID,
+			Description:   item.Description,
+			Amount:        item.Amount,
+			OperationDate: item.OperationDate,
+		})
+	}
+
+	return output
+}
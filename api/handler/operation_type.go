@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type OperationTypeInputDTO struct {
+	Description         string `json:"description" validate:"required,max=100"`
+	Direction           string `json:"direction" validate:"required,oneof=DEBIT CREDIT"`
+	ConsumesCreditLimit *bool  `json:"consumes_credit_limit" validate:"required"`
+	Active              *bool  `json:"active" validate:"required"`
+	Internal            bool   `json:"internal"`
+}
+
+type OperationTypeOutputDTO struct {
+	OperationTypeID     int    `json:"operation_type_id"`
+	Description         string `json:"description"`
+	Direction           string `json:"direction"`
+	ConsumesCreditLimit bool   `json:"consumes_credit_limit"`
+	Active              bool   `json:"active"`
+	Internal            bool   `json:"internal"`
+	BuiltIn             bool   `json:"built_in"`
+}
+
+type OperationTypeHandler struct {
+	operationTypeService *operationtype.Service
+	logger               *slog.Logger
+}
+
+func NewOperationTypeHandler(s *operationtype.Service, l *slog.Logger) *OperationTypeHandler {
+	return &OperationTypeHandler{
+		operationTypeService: s,
+		logger:               l,
+	}
+}
+
+// ListOperationTypes godoc
+// @Summary      List operation types
+// @Description  List every operation type of the catalogue, including inactive ones
+// @Tags         Operation Types
+// @Produce      json
+// @Success      200 {array} OperationTypeOutputDTO
+// @Failure      500 {object} Problem
+// @Router       /operation-types [get]
+func (h *OperationTypeHandler) ListOperationTypes(ctx *gin.Context) {
+	operationTypes, err := h.operationTypeService.List(ctx)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error listing operation types", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	output := make([]OperationTypeOutputDTO, 0, len(operationTypes))
+	for _, o := range operationTypes {
+		output = append(output, newOperationTypeOutputDTO(&o))
+	}
+
+	ctx.JSON(http.StatusOK, output)
+}
+
+// GetOperationTypeById godoc
+// @Summary      Show operation type details
+// @Description  Get operation type by id
+// @Tags         Operation Types
+// @Produce      json
+// @Param        operationTypeId   path      integer  true  "Operation type id"
+// @Success      200 {object} OperationTypeOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /operation-types/{operationTypeId} [get]
+func (h *OperationTypeHandler) GetOperationTypeById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("operationTypeId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting operation type id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("operationTypeId"))
+		return
+	}
+
+	operationType, err := h.operationTypeService.FindById(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error finding operation type by id", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	if operationType == nil {
+		h.logger.ErrorContext(ctx, "operation type not found")
+		_ = ctx.Error(operationtype.ErrOperationTypeNotFound)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newOperationTypeOutputDTO(operationType))
+}
+
+// CreateOperationType godoc
+// @Summary      Create operation type
+// @Description  Add a new operation type to the catalogue
+// @Tags         Operation Types
+// @Accept       json
+// @Produce      json
+// @Param        request   body      OperationTypeInputDTO  true  "Operation type properties"
+// @Success      201 {object} OperationTypeOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /operation-types [post]
+func (h *OperationTypeHandler) CreateOperationType(ctx *gin.Context) {
+	operationType, ok := h.bindOperationType(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.operationTypeService.Create(ctx, operationType); err != nil {
+		h.respondOperationTypeError(ctx, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "operation type created successfully", slog.Any("operation_type", operationType))
+	ctx.JSON(http.StatusCreated, newOperationTypeOutputDTO(operationType))
+}
+
+// UpdateOperationType godoc
+// @Summary      Update operation type
+// @Description  Replace the properties of an operation type. Transactions already booked keep their amounts.
+// @Tags         Operation Types
+// @Accept       json
+// @Produce      json
+// @Param        operationTypeId   path      integer                true  "Operation type id"
+// @Param        request           body      OperationTypeInputDTO  true  "Operation type properties"
+// @Success      200 {object} OperationTypeOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      422 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /operation-types/{operationTypeId} [put]
+func (h *OperationTypeHandler) UpdateOperationType(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("operationTypeId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting operation type id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("operationTypeId"))
+		return
+	}
+
+	operationType, ok := h.bindOperationType(ctx)
+	if !ok {
+		return
+	}
+
+	operationType.ID = id
+
+	if err = h.operationTypeService.Update(ctx, operationType); err != nil {
+		h.respondOperationTypeError(ctx, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "operation type updated successfully", slog.Any("operation_type", operationType))
+	ctx.JSON(http.StatusOK, newOperationTypeOutputDTO(operationType))
+}
+
+// DeleteOperationType godoc
+// @Summary      Deactivate operation type
+// @Description  Deactivate an operation type. It is kept for the transactions already booked with it, but can't be used by new ones.
+// @Tags         Operation Types
+// @Param        operationTypeId   path      integer  true  "Operation type id"
+// @Success      204
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /operation-types/{operationTypeId} [delete]
+func (h *OperationTypeHandler) DeleteOperationType(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("operationTypeId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting operation type id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("operationTypeId"))
+		return
+	}
+
+	if err = h.operationTypeService.Deactivate(ctx, id); err != nil {
+		h.respondOperationTypeError(ctx, err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "operation type deactivated successfully", slog.Int("operation_type_id", id))
+	ctx.Status(http.StatusNoContent)
+}
+
+func (h *OperationTypeHandler) bindOperationType(ctx *gin.Context) (*operationtype.OperationType, bool) {
+	var input OperationTypeInputDTO
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		h.logger.ErrorContext(ctx, "error reading body", slog.Any("error", err))
+		_ = ctx.Error(ErrMalformedBody)
+		return nil, false
+	}
+
+	validation := validate(input).Errors
+	if len(validation) > 0 {
+		h.logger.ErrorContext(ctx, "invalid payload", slog.Any("validation", validation))
+		_ = ctx.Error(&ValidationError{Fields: validation})
+		return nil, false
+	}
+
+	return &operationtype.OperationType{
+		Description:         input.Description,
+		Direction:           input.Direction,
+		ConsumesCreditLimit: *input.ConsumesCreditLimit,
+		Active:              *input.Active,
+		Internal:            input.Internal,
+	}, true
+}
+
+func (h *OperationTypeHandler) respondOperationTypeError(ctx *gin.Context, err error) {
+	h.logger.ErrorContext(ctx, "error saving operation type", slog.Any("error", err))
+	_ = ctx.Error(errors.Join(err, ErrSaveOperationType))
+}
+
+func newOperationTypeOutputDTO(o *operationtype.OperationType) OperationTypeOutputDTO {
+	return OperationTypeOutputDTO{
+		OperationTypeID:     o.ID,
+		Description:         o.Description,
+		Direction:           o.Direction,
+		ConsumesCreditLimit: o.ConsumesCreditLimit,
+		Active:              o.Active,
+		Internal:            o.Internal,
+		BuiltIn:             o.BuiltIn,
+	}
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/authorization"
+	"github.com/supwr/pismo-transactions/internal/billing"
+	"github.com/supwr/pismo-transactions/internal/fee"
+	"github.com/supwr/pismo-transactions/internal/idempotency"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"github.com/supwr/pismo-transactions/internal/transaction"
+	"github.com/supwr/pismo-transactions/internal/webhook"
+	"net/http"
+)
+
+const ProblemContentType = "application/problem+json"
+
+const (
+	CodeValidationFailed = "validation_failed"
+	CodeMalformedBody    = "malformed_body"
+	CodeRouteNotFound    = "route_not_found"
+	CodeInternalError    = "internal_error"
+)
+
+// Problem is the RFC 7807 body of every error response. Code is stable and meant for clients to branch on, while
+// Detail is a human readable message that may change. Errors lists the offending fields of invalid requests.
+type Problem struct {
+	Type     string  `json:"type"`
+	Title    string  `json:"title"`
+	Status   int     `json:"status"`
+	Code     string  `json:"code"`
+	Detail   string  `json:"detail,omitempty"`
+	Instance string  `json:"instance,omitempty"`
+	Errors   []Field `json:"errors,omitempty"`
+}
+
+// ValidationError carries the fields that failed validation, from the body, the path or the query string.
+type ValidationError struct {
+	Fields []Field
+}
+
+func (e *ValidationError) Error() string {
+	return "Request has invalid or missing fields"
+}
+
+// invalidField is the validation error of a single field, usually a path id that isn't a number.
+func invalidField(name string) error {
+	return &ValidationError{Fields: []Field{{Name: name, Message: "invalid or missing field"}}}
+}
+
+type problemType struct {
+	err    error
+	status int
+	code   string
+}
+
+// problemTypes maps the errors handlers may record to their status and code. It's searched in order, so an error
+// joined with the handler error describing the failed operation, like ErrCreateAccount, is reported by its cause.
+var problemTypes = []problemType{
+	{account.ErrAccountAlreadyExists, http.StatusBadRequest, "account_already_exists"},
+	{account.ErrAccountNotFound, http.StatusNotFound, "account_not_found"},
+	{account.ErrInvalidDocument, http.StatusBadRequest, "invalid_document"},
+	{account.ErrAccountClosed, http.StatusUnprocessableEntity, "account_closed"},
+	{account.ErrInvalidStatus, http.StatusBadRequest, "invalid_status"},
+	{account.ErrInvalidStatusTransition, http.StatusUnprocessableEntity, "invalid_status_transition"},
+	{account.ErrStatusReasonRequired, http.StatusBadRequest, "status_reason_required"},
+	{account.ErrAccountBalanceNotZero, http.StatusUnprocessableEntity, "account_balance_not_zero"},
+	{account.ErrInvalidCreditLimit, http.StatusBadRequest, "invalid_credit_limit"},
+	{account.ErrCreditLimitBelowUsage, http.StatusUnprocessableEntity, "credit_limit_below_usage"},
+	{account.ErrChangeReasonRequired, http.StatusBadRequest, "change_reason_required"},
+	{account.ErrInvalidBillingDay, http.StatusBadRequest, "invalid_billing_day"},
+
+	{transaction.ErrOperationTypeNotFound, http.StatusNotFound, "operation_type_not_found"},
+	{transaction.ErrAccountNotFound, http.StatusNotFound, "account_not_found"},
+	{transaction.ErrAccountBlocked, http.StatusUnprocessableEntity, "account_blocked"},
+	{transaction.ErrAccountClosed, http.StatusUnprocessableEntity, "account_closed"},
+	{transaction.ErrInsuficientFunds, http.StatusUnprocessableEntity, "insufficient_funds"},
+	{transaction.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	{transaction.ErrInstallmentsNotAllowed, http.StatusBadRequest, "installments_not_allowed"},
+	{transaction.ErrOperationTypeNotAllowed, http.StatusBadRequest, "operation_type_not_allowed"},
+	{transaction.ErrTransactionNotFound, http.StatusNotFound, "transaction_not_found"},
+	{transaction.ErrTransactionNotReversible, http.StatusUnprocessableEntity, "transaction_not_reversible"},
+	{transaction.ErrInvalidReversalAmount, http.StatusBadRequest, "invalid_amount"},
+	{transaction.ErrReversalExceedsAmount, http.StatusUnprocessableEntity, "reversal_exceeds_amount"},
+
+	{installment.ErrInvalidInstallmentCount, http.StatusBadRequest, "invalid_installment_count"},
+	{installment.ErrInvalidInterestRate, http.StatusBadRequest, "invalid_interest_rate"},
+
+	{idempotency.ErrRequestMismatch, http.StatusUnprocessableEntity, "idempotency_key_reused"},
+	{idempotency.ErrKeyTooLong, http.StatusBadRequest, "idempotency_key_too_long"},
+
+	{operationtype.ErrOperationTypeNotFound, http.StatusNotFound, "operation_type_not_found"},
+	{operationtype.ErrInvalidDirection, http.StatusBadRequest, "invalid_direction"},
+	{operationtype.ErrInvalidDescription, http.StatusBadRequest, "invalid_description"},
+	{operationtype.ErrBuiltInOperationType, http.StatusUnprocessableEntity, "built_in_operation_type"},
+
+	{authorization.ErrAuthorizationNotFound, http.StatusNotFound, "authorization_not_found"},
+	{authorization.ErrAuthorizationNotPending, http.StatusUnprocessableEntity, "authorization_not_pending"},
+	{authorization.ErrAuthorizationExpired, http.StatusUnprocessableEntity, "authorization_expired"},
+	{authorization.ErrInvalidAmount, http.StatusBadRequest, "invalid_amount"},
+	{authorization.ErrCaptureExceedsAmount, http.StatusUnprocessableEntity, "capture_exceeds_amount"},
+	{authorization.ErrOperationTypeNotAllowed, http.StatusBadRequest, "operation_type_not_allowed"},
+
+	{fee.ErrInvalidKind, http.StatusBadRequest, "invalid_fee_kind"},
+	{fee.ErrInvalidRate, http.StatusBadRequest, "invalid_fee_rate"},
+	{fee.ErrOperationTypeRequired, http.StatusBadRequest, "operation_type_required"},
+	{fee.ErrOperationTypeNotFound, http.StatusNotFound, "operation_type_not_found"},
+	{fee.ErrOperationTypeNotAllowed, http.StatusBadRequest, "operation_type_not_allowed"},
+
+	{billing.ErrAccountNotFound, http.StatusNotFound, "account_not_found"},
+	{billing.ErrInvoiceNotFound, http.StatusNotFound, "invoice_not_found"},
+
+	{webhook.ErrSubscriptionNotFound, http.StatusNotFound, "webhook_not_found"},
+	{webhook.ErrDeliveryNotFound, http.StatusNotFound, "delivery_not_found"},
+	{webhook.ErrDeliveryInProgress, http.StatusConflict, "delivery_in_progress"},
+	{webhook.ErrInvalidURL, http.StatusBadRequest, "invalid_url"},
+	{webhook.ErrForbiddenHost, http.StatusBadRequest, "forbidden_host"},
+	{webhook.ErrInvalidEventTypes, http.StatusBadRequest, "invalid_event_types"},
+
+	{ErrMalformedBody, http.StatusBadRequest, CodeMalformedBody},
+	{ErrRouteNotFound, http.StatusNotFound, CodeRouteNotFound},
+	{ErrInstallmentPlanNotFound, http.StatusNotFound, "installment_plan_not_found"},
+
+	// the operation that failed, for errors nothing above explains
+	{ErrCreateAccount, http.StatusInternalServerError, CodeInternalError},
+	{ErrUpdateAccount, http.StatusInternalServerError, CodeInternalError},
+	{ErrCreateTransaction, http.StatusInternalServerError, CodeInternalError},
+	{ErrReverseTransaction, http.StatusInternalServerError, CodeInternalError},
+	{ErrSaveOperationType, http.StatusInternalServerError, CodeInternalError},
+	{ErrCreateFeeRate, http.StatusInternalServerError, CodeInternalError},
+	{ErrCreateAuthorization, http.StatusInternalServerError, CodeInternalError},
+	{ErrUpdateAuthorization, http.StatusInternalServerError, CodeInternalError},
+	{ErrCreateWebhook, http.StatusInternalServerError, CodeInternalError},
+	{ErrUpdateWebhook, http.StatusInternalServerError, CodeInternalError},
+}
+
+// NewProblem describes err as a problem. Only the messages of known errors reach the client, anything else is
+// reported as an internal error without details.
+func NewProblem(err error) *Problem {
+	var validation *ValidationError
+	if errors.As(err, &validation) {
+		return newProblem(http.StatusBadRequest, CodeValidationFailed, validation.Error(), validation.Fields)
+	}
+
+	for _, t := range problemTypes {
+		if errors.Is(err, t.err) {
+			return newProblem(t.status, t.code, t.err.Error(), nil)
+		}
+	}
+
+	return newProblem(http.StatusInternalServerError, CodeInternalError, ErrInternal.Error(), nil)
+}
+
+func newProblem(status int, code string, detail string, fields []Field) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+		Detail: detail,
+		Errors: fields,
+	}
+}
+
+// Problems responds to the last error a handler recorded with ctx.Error as a problem+json body, unless the
+// handler already wrote a response.
+func Problems() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+
+		problem := NewProblem(ctx.Errors.Last().Err)
+		problem.Instance = ctx.Request.URL.Path
+
+		ctx.Header("Content-Type", ProblemContentType)
+		ctx.JSON(problem.Status, problem)
+	}
+}
+
+// RouteNotFound answers requests to routes that don't exist.
+func RouteNotFound(ctx *gin.Context) {
+	_ = ctx.Error(ErrRouteNotFound)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/transaction"
+	"github.com/supwr/pismo-transactions/pkg/logging"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewProblem(t *testing.T) {
+	cases := map[string]struct {
+		err    error
+		status int
+		code   string
+		detail string
+		fields []Field
+	}{
+		"cause joined with the failed operation": {
+			err:    errors.Join(account.ErrAccountAlreadyExists, ErrCreateAccount),
+			status: http.StatusBadRequest,
+			code:   "account_already_exists",
+			detail: account.ErrAccountAlreadyExists.Error(),
+		},
+		"unexplained error of a known operation": {
+			err:    errors.Join(errors.New("connection reset"), ErrCreateAccount),
+			status: http.StatusInternalServerError,
+			code:   CodeInternalError,
+			detail: ErrCreateAccount.Error(),
+		},
+		"validation error": {
+			err:    &ValidationError{Fields: []Field{{Name: "amount", Message: "invalid or missing field"}}},
+			status: http.StatusBadRequest,
+			code:   CodeValidationFailed,
+			detail: "Request has invalid or missing fields",
+			fields: []Field{{Name: "amount", Message: "invalid or missing field"}},
+		},
+		"unknown error": {
+			err:    errors.New("pq: password authentication failed for user \"pismo\""),
+			status: http.StatusInternalServerError,
+			code:   CodeInternalError,
+			detail: ErrInternal.Error(),
+		},
+		"insufficient funds": {
+			err:    transaction.ErrInsuficientFunds,
+			status: http.StatusUnprocessableEntity,
+			code:   "insufficient_funds",
+			detail: transaction.ErrInsuficientFunds.Error(),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			problem := NewProblem(c.err)
+
+			assert.Equal(t, "about:blank", problem.Type)
+			assert.Equal(t, http.StatusText(c.status), problem.Title)
+			assert.Equal(t, c.status, problem.Status)
+			assert.Equal(t, c.code, problem.Code)
+			assert.Equal(t, c.detail, problem.Detail)
+			assert.Equal(t, c.fields, problem.Errors)
+		})
+	}
+}
+
+func TestProblems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("panic is answered as a problem", func(t *testing.T) {
+		// same order as the router: recovery runs inside Problems, so the error it records still gets a body
+		api := gin.New()
+		api.Use(Problems(), logging.Recovery(slog.New(slog.NewTextHandler(io.Discard, nil))))
+		api.GET("/panic", func(ctx *gin.Context) {
+			panic("nil map")
+		})
+
+		response := httptest.NewRecorder()
+		api.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+		var problem Problem
+		assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &problem))
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+		assert.Equal(t, ProblemContentType, response.Header().Get("Content-Type"))
+		assert.Equal(t, CodeInternalError, problem.Code)
+		assert.Equal(t, ErrInternal.Error(), problem.Detail)
+		assert.Equal(t, "/panic", problem.Instance)
+	})
+}
@@ -1,82 +1,513 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/shopspring/decimal"
+	"github.com/supwr/pismo-transactions/internal/idempotency"
 	"github.com/supwr/pismo-transactions/internal/transaction"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 )
 
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 type TransactionInputDTO struct {
 	AccountId       int             `json:"account_id" validate:"required"`
 	OperationTypeId int             `json:"operation_type_id" validate:"required"`
 	Amount          decimal.Decimal `json:"amount" validate:"required"`
+	Installments    int             `json:"installments,omitempty" validate:"omitempty,min=1,max=24"`
+	InterestRate    decimal.Decimal `json:"interest_rate"`
+}
+
+type ReversalInputDTO struct {
+	// Amount to refund; the whole remaining amount is reversed when omitted.
+	Amount *decimal.Decimal `json:"amount" swaggertype:"number"`
+}
+
+type TransactionOutputDTO struct {
+	TransactionID         int             `json:"transaction_id"`
+	AccountID             int             `json:"account_id"`
+	OperationTypeID       int             `json:"operation_type_id"`
+	Amount                decimal.Decimal `json:"amount"`
+	Balance               decimal.Decimal `json:"balance"`
+	OperationDate         time.Time       `json:"operation_date"`
+	Status                string          `json:"status"`
+	ReversedAmount        decimal.Decimal `json:"reversed_amount"`
+	ReversedTransactionID *int            `json:"reversed_transaction_id,omitempty"`
+}
+
+type InstallmentPlanOutputDTO struct {
+	InstallmentPlanID int                    `json:"installment_plan_id"`
+	TransactionID     int                    `json:"transaction_id"`
+	Principal         decimal.Decimal        `json:"principal"`
+	InterestRate      decimal.Decimal        `json:"interest_rate"`
+	InstallmentCount  int                    `json:"installment_count"`
+	Total             decimal.Decimal        `json:"total"`
+	Installments      []InstallmentOutputDTO `json:"installments"`
+}
+
+type InstallmentOutputDTO struct {
+	Number   int             `json:"number"`
+	Amount   decimal.Decimal `json:"amount"`
+	Interest decimal.Decimal `json:"interest"`
+	DueDate  string          `json:"due_date"`
+	Status   string          `json:"status"`
+}
+
+type DischargeOutputDTO struct {
+	PaymentTransactionID int             `json:"payment_transaction_id"`
+	TransactionID        int             `json:"transaction_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	CreatedAt            time.Time       `json:"created_at"`
+}
+
+type TransactionListOutputDTO struct {
+	Transactions []TransactionOutputDTO `json:"transactions"`
+	NextCursor   string                 `json:"next_cursor,omitempty"`
 }
 
 type TransactionHandler struct {
 	transactionService *transaction.Service
+	idempotencyService *idempotency.Service
 	logger             *slog.Logger
 }
 
-func NewTransactionHandler(s *transaction.Service, l *slog.Logger) *TransactionHandler {
+func NewTransactionHandler(s *transaction.Service, i *idempotency.Service, l *slog.Logger) *TransactionHandler {
 	return &TransactionHandler{
 		transactionService: s,
+		idempotencyService: i,
 		logger:             l,
 	}
 }
 
 // CreateTransaction godoc
 // @Summary      Create transaction
-// @Description  Add new transaction
+// @Description  Add new transaction. Requests carrying an Idempotency-Key are processed once; retries with the same key and payload replay the original response.
 // @Tags         Transactions
 // @Accept       json
 // @Produce      json
-// @Param        request   body      TransactionInputDTO  true  "Transaction properties"
-// @Success      201
-// @Failure      500
-// @Failure      400
+// @Param        Idempotency-Key  header    string               false  "Unique key identifying this request"
+// @Param        request          body      TransactionInputDTO  true   "Transaction properties"
+// @Success      201 {object} TransactionOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      422 {object} Problem
+// @Failure      400 {object} Problem
 // @Router       /transactions [post]
 func (h *TransactionHandler) CreateTransaction(ctx *gin.Context) {
 	var err error
 	var input TransactionInputDTO
 
-	if err = ctx.BindJSON(&input); err != nil {
+	if err = ctx.ShouldBindJSON(&input); err != nil {
 		h.logger.ErrorContext(ctx, "error reading body", slog.Any("error", err))
-		ctx.JSON(http.StatusInternalServerError, nil)
+		_ = ctx.Error(ErrMalformedBody)
 		return
 	}
 
 	validation := validate(input).Errors
 	if len(validation) > 0 {
-		h.logger.ErrorContext(ctx, "invalid payload", slog.Any("error", err))
-		ctx.JSON(http.StatusBadRequest, validation)
+		h.logger.ErrorContext(ctx, "invalid payload", slog.Any("validation", validation))
+		_ = ctx.Error(&ValidationError{Fields: validation})
+		return
+	}
+
+	key := ctx.GetHeader(IdempotencyKeyHeader)
+	if key == "" {
+		output, err := h.createTransaction(ctx, input)
+		if err != nil {
+			h.respondCreateTransactionError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, output)
+		return
+	}
+
+	payload, err := json.Marshal(input)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error hashing payload", slog.Any("error", err))
+		_ = ctx.Error(errors.Join(err, ErrCreateTransaction))
+		return
+	}
+
+	response, err := h.idempotencyService.Execute(ctx, key, idempotency.Hash(payload), func(ctx context.Context) (*idempotency.Response, error) {
+		output, err := h.createTransaction(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := json.Marshal(output)
+		if err != nil {
+			return nil, err
+		}
+
+		return &idempotency.Response{StatusCode: http.StatusCreated, Body: body}, nil
+	})
+
+	if err != nil {
+		h.respondCreateTransactionError(ctx, err)
+		return
+	}
+
+	ctx.Data(response.StatusCode, "application/json; charset=utf-8", response.Body)
+}
+
+// GetTransactionById godoc
+// @Summary      Show transaction details
+// @Description  Get transaction by id
+// @Tags         Transactions
+// @Produce      json
+// @Param        transactionId   path      integer  true  "Transaction id"
+// @Success      200 {object} TransactionOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /transactions/{transactionId} [get]
+func (h *TransactionHandler) GetTransactionById(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("transactionId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting transaction id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("transactionId"))
+		return
+	}
+
+	transact, err := h.transactionService.FindById(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error finding transaction by id", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	if transact == nil {
+		h.logger.ErrorContext(ctx, "transaction not found")
+		_ = ctx.Error(transaction.ErrTransactionNotFound)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTransactionOutputDTO(transact))
+}
+
+// ReverseTransaction godoc
+// @Summary      Reverse transaction
+// @Description  Refund a purchase or withdraw, fully or partially. The refund is booked as a reversal transaction linked to the original one.
+// @Tags         Transactions
+// @Accept       json
+// @Produce      json
+// @Param        transactionId   path      integer           true   "Transaction id"
+// @Param        request         body      ReversalInputDTO  false  "Reversal properties"
+// @Success      201 {object} TransactionOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      422 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /transactions/{transactionId}/reversal [post]
+func (h *TransactionHandler) ReverseTransaction(ctx *gin.Context) {
+	var input ReversalInputDTO
+
+	id, err := strconv.Atoi(ctx.Param("transactionId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting transaction id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("transactionId"))
+		return
+	}
+
+	// the body is optional, an empty one reverses whatever is left of the transaction
+	if err = ctx.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.ErrorContext(ctx, "error reading body", slog.Any("error", err))
+		_ = ctx.Error(ErrMalformedBody)
+		return
+	}
+
+	reversal, err := h.transactionService.Reverse(ctx, id, input.Amount)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error reversing transaction", slog.Any("error", err))
+		_ = ctx.Error(errors.Join(err, ErrReverseTransaction))
+		return
+	}
+
+	h.logger.InfoContext(ctx, "transaction reversed successfully", slog.Any("reversal", reversal))
+	ctx.JSON(http.StatusCreated, newTransactionOutputDTO(reversal))
+}
+
+// GetTransactionInstallmentPlan godoc
+// @Summary      Show installment plan
+// @Description  Get the installment plan of an installment buy, with the due date and status of each installment
+// @Tags         Transactions
+// @Produce      json
+// @Param        transactionId   path      integer  true  "Transaction id"
+// @Success      200 {object} InstallmentPlanOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /transactions/{transactionId}/installment-plan [get]
+func (h *TransactionHandler) GetTransactionInstallmentPlan(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("transactionId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting transaction id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("transactionId"))
+		return
+	}
+
+	plan, err := h.transactionService.FindInstallmentPlan(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error finding installment plan", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	if plan == nil {
+		h.logger.ErrorContext(ctx, "installment plan not found")
+		_ = ctx.Error(ErrInstallmentPlanNotFound)
+		return
+	}
+
+	output := InstallmentPlanOutputDTO{
+		InstallmentPlanID: plan.ID,
+		TransactionID:     plan.TransactionID,
+		Principal:         plan.Principal,
+		InterestRate:      plan.InterestRate,
+		InstallmentCount:  plan.InstallmentCount,
+		Total:             plan.Total,
+		Installments:      make([]InstallmentOutputDTO, 0, len(plan.Installments)),
+	}
+
+	for _, i := range plan.Installments {
+		output.Installments = append(output.Installments, InstallmentOutputDTO{
+			Number:   i.Number,
+			Amount:   i.Amount,
+			Interest: i.Interest,
+			DueDate:  i.DueDate.Format(time.DateOnly),
+			Status:   i.Status,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, output)
+}
+
+// GetTransactionDischarges godoc
+// @Summary      Show transaction discharges
+// @Description  List the discharges made by a payment, or the payments that settled a purchase or withdraw
+// @Tags         Transactions
+// @Produce      json
+// @Param        transactionId   path      integer  true  "Transaction id"
+// @Success      200 {array} DischargeOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /transactions/{transactionId}/discharges [get]
+func (h *TransactionHandler) GetTransactionDischarges(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("transactionId"))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting transaction id", slog.Any("error", err))
+		_ = ctx.Error(invalidField("transactionId"))
+		return
+	}
+
+	transact, err := h.transactionService.FindById(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error finding transaction by id", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	if transact == nil {
+		h.logger.ErrorContext(ctx, "transaction not found")
+		_ = ctx.Error(transaction.ErrTransactionNotFound)
+		return
+	}
+
+	discharges, err := h.transactionService.FindDischarges(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error finding discharges", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	output := make([]DischargeOutputDTO, 0, len(discharges))
+	for _, d := range discharges {
+		output = append(output, DischargeOutputDTO{
+			PaymentTransactionID: d.PaymentTransactionID,
+			TransactionID:        d.TransactionID,
+			Amount:               d.Amount,
+			CreatedAt:            d.CreatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, output)
+}
+
+// ListAccountTransactions godoc
+// @Summary      List account transactions
+// @Description  List the transactions of an account, newest first. Follow next_cursor to fetch the next page.
+// @Tags         Transactions
+// @Produce      json
+// @Param        accountId          path      integer  true   "Account id"
+// @Param        operation_type_id  query     integer  false  "Operation type id"
+// @Param        from               query     string   false  "Operation date lower bound, inclusive (RFC3339)"
+// @Param        to                 query     string   false  "Operation date upper bound, exclusive (RFC3339)"
+// @Param        min_amount         query     number   false  "Minimum absolute amount"
+// @Param        max_amount         query     number   false  "Maximum absolute amount"
+// @Param        cursor             query     string   false  "Cursor returned by the previous page"
+// @Param        limit              query     integer  false  "Page size, up to 100"
+// @Success      200 {object} TransactionListOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /accounts/{accountId}/transactions [get]
+func (h *TransactionHandler) ListAccountTransactions(ctx *gin.Context) {
+	filter, validation := parseTransactionFilter(ctx)
+	if len(validation) > 0 {
+		h.logger.ErrorContext(ctx, "invalid transaction filter", slog.Any("fields", validation))
+		_ = ctx.Error(&ValidationError{Fields: validation})
 		return
 	}
 
+	page, err := h.transactionService.List(ctx, filter)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error listing transactions", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	output := TransactionListOutputDTO{
+		Transactions: make([]TransactionOutputDTO, 0, len(page.Transactions)),
+		NextCursor:   page.NextCursor,
+	}
+
+	for _, t := range page.Transactions {
+		output.Transactions = append(output.Transactions, *newTransactionOutputDTO(&t))
+	}
+
+	ctx.JSON(http.StatusOK, output)
+}
+
+func (h *TransactionHandler) createTransaction(ctx context.Context, input TransactionInputDTO) (*TransactionOutputDTO, error) {
 	transact := &transaction.Transaction{
 		AccountID:       input.AccountId,
 		OperationTypeID: input.OperationTypeId,
 		Amount:          input.Amount,
+		Installments:    input.Installments,
+		InterestRate:    input.InterestRate,
 	}
 
-	if err = h.transactionService.Create(ctx, transact); err != nil {
-		h.logger.ErrorContext(ctx, "error creating transaction", slog.Any("error", err))
-		if errors.Is(err, transaction.ErrOperationTypeNotFound) || errors.Is(err, transaction.ErrAccountNotFound) {
-			ctx.JSON(http.StatusBadRequest, gin.H{
-				"error": err.Error(),
-			})
-			return
+	if err := h.transactionService.Create(ctx, transact); err != nil {
+		return nil, err
+	}
+
+	h.logger.InfoContext(ctx, "transaction created successfully", slog.Any("transaction", transact))
+	return newTransactionOutputDTO(transact), nil
+}
+
+func (h *TransactionHandler) respondCreateTransactionError(ctx *gin.Context, err error) {
+	h.logger.ErrorContext(ctx, "error creating transaction", slog.Any("error", err))
+	_ = ctx.Error(errors.Join(err, ErrCreateTransaction))
+}
+
+func newTransactionOutputDTO(t *transaction.Transaction) *TransactionOutputDTO {
+	return &TransactionOutputDTO{
+		TransactionID:         t.ID,
+		AccountID:             t.AccountID,
+		OperationTypeID:       t.OperationTypeID,
+		Amount:                t.Amount,
+		Balance:               t.Balance,
+		OperationDate:         t.OperationDate,
+		Status:                t.Status,
+		ReversedAmount:        t.ReversedAmount,
+		ReversedTransactionID: t.ReversedTransactionID,
+	}
+}
+
+func parseTransactionFilter(ctx *gin.Context) (transaction.Filter, []Field) {
+	var filter transaction.Filter
+	var fields []Field
+	var err error
+
+	invalid := func(name string) {
+		fields = append(fields, Field{Name: name, Message: "invalid or missing field"})
+	}
+
+	if filter.AccountID, err = strconv.Atoi(ctx.Param("accountId")); err != nil {
+		invalid("accountId")
+	}
+
+	if v := ctx.Query("operation_type_id"); v != "" {
+		if filter.OperationTypeID, err = strconv.Atoi(v); err != nil {
+			invalid("operation_type_id")
 		}
+	}
 
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": ErrCreateTransaction.Error(),
-		})
-		return
+	if v := ctx.Query("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 1 {
+			invalid("limit")
+		}
 	}
 
-	h.logger.InfoContext(ctx, "transaction created successfully", slog.Any("transaction", transact))
-	ctx.JSON(http.StatusCreated, nil)
-	return
+	if filter.From, err = queryTime(ctx, "from"); err != nil {
+		invalid("from")
+	}
+
+	if filter.To, err = queryTime(ctx, "to"); err != nil {
+		invalid("to")
+	}
+
+	if filter.MinAmount, err = queryAmount(ctx, "min_amount"); err != nil {
+		invalid("min_amount")
+	}
+
+	if filter.MaxAmount, err = queryAmount(ctx, "max_amount"); err != nil {
+		invalid("max_amount")
+	}
+
+	if v := ctx.Query("cursor"); v != "" {
+		if filter.After, err = transaction.DecodeCursor(v); err != nil {
+			invalid("cursor")
+		}
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		invalid("to")
+	}
+
+	if filter.MinAmount != nil && filter.MaxAmount != nil && filter.MinAmount.GreaterThan(*filter.MaxAmount) {
+		invalid("max_amount")
+	}
+
+	return filter, fields
+}
+
+func queryTime(ctx *gin.Context, name string) (*time.Time, error) {
+	v := ctx.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &date, nil
+}
+
+func queryAmount(ctx *gin.Context, name string) (*decimal.Decimal, error) {
+	v := ctx.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	amount, err := decimal.NewFromString(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if amount.IsNegative() {
+		return nil, errors.New("amount must not be negative")
+	}
+
+	return &amount, nil
 }
@@ -0,0 +1,347 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/supwr/pismo-transactions/internal/webhook"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type WebhookInputDTO struct {
+	URL        string   `json:"url" validate:"required,url"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=AccountCreated TransactionCreated CreditLimitChanged"`
+	// AccountID limits the webhook to the events of one account. All accounts' events are sent when omitted.
+	AccountID *int `json:"account_id"`
+	// Secret signs the deliveries. A random one is generated when omitted.
+	Secret string `json:"secret" validate:"omitempty,min=16"`
+}
+
+type WebhookOutputDTO struct {
+	WebhookID  int       `json:"webhook_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	AccountID  *int      `json:"account_id,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Secret is only returned when the webhook is created.
+	Secret string `json:"secret,omitempty"`
+}
+
+type DeliveryOutputDTO struct {
+	DeliveryID     int                        `json:"delivery_id"`
+	WebhookID      int                        `json:"webhook_id"`
+	EventID        int                        `json:"event_id"`
+	EventType      string                     `json:"event_type"`
+	Payload        json.RawMessage            `json:"payload" swaggertype:"object"`
+	Status         string                     `json:"status"`
+	AttemptCount   int                        `json:"attempt_count"`
+	NextAttemptAt  *time.Time                 `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int                       `json:"last_status_code,omitempty"`
+	LastError      *string                    `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time                 `json:"delivered_at,omitempty"`
+	Attempts       []DeliveryAttemptOutputDTO `json:"attempts,omitempty"`
+	CreatedAt      time.Time                  `json:"created_at"`
+}
+
+type DeliveryAttemptOutputDTO struct {
+	StatusCode  *int      `json:"status_code,omitempty"`
+	Error       *string   `json:"error,omitempty"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+type WebhookHandler struct {
+	webhookService *webhook.Service
+	logger         *slog.Logger
+}
+
+func NewWebhookHandler(s *webhook.Service, l *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: s,
+		logger:         l,
+	}
+}
+
+// ListWebhooks godoc
+// @Summary      List webhooks
+// @Description  List the active webhook subscriptions
+// @Tags         Webhooks
+// @Produce      json
+// @Success      200 {array} WebhookOutputDTO
+// @Failure      500 {object} Problem
+// @Router       /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(ctx *gin.Context) {
+	subscriptions, err := h.webhookService.Subscriptions(ctx)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error listing webhooks", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	output := make([]WebhookOutputDTO, 0, len(subscriptions))
+	for i := range subscriptions {
+		output = append(output, newWebhookOutputDTO(&subscriptions[i]))
+	}
+
+	ctx.JSON(http.StatusOK, output)
+}
+
+// CreateWebhook godoc
+// @Summary      Create webhook
+// @Description  Subscribe a URL to account and transaction events. Each delivery is signed with the secret in the X-Webhook-Signature header, as sha256=HMAC-SHA256(secret, "{X-Webhook-Timestamp}.{body}") in hex.
+// @Tags         Webhooks
+// @Accept       json
+// @Produce      json
+// @Param        request   body      WebhookInputDTO  true  "Webhook properties"
+// @Success      201 {object} WebhookOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(ctx *gin.Context) {
+	var input WebhookInputDTO
+
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		h.logger.ErrorContext(ctx, "error reading body", slog.Any("error", err))
+		_ = ctx.Error(ErrMalformedBody)
+		return
+	}
+
+	validation := validate(input).Errors
+	if len(validation) > 0 {
+		h.logger.ErrorContext(ctx, "invalid payload", slog.Any("validation", validation))
+		_ = ctx.Error(&ValidationError{Fields: validation})
+		return
+	}
+
+	subscription := &webhook.Subscription{
+		URL:        input.URL,
+		EventTypes: input.EventTypes,
+		AccountID:  input.AccountID,
+		Secret:     input.Secret,
+	}
+
+	if err := h.webhookService.CreateSubscription(ctx, subscription); err != nil {
+		h.logger.ErrorContext(ctx, "error creating webhook", slog.Any("error", err))
+		_ = ctx.Error(errors.Join(err, ErrCreateWebhook))
+		return
+	}
+
+	h.logger.InfoContext(ctx, "webhook created successfully", slog.Int("webhook_id", subscription.ID))
+
+	output := newWebhookOutputDTO(subscription)
+	output.Secret = subscription.Secret
+
+	ctx.JSON(http.StatusCreated, output)
+}
+
+// GetWebhookById godoc
+// @Summary      Show webhook details
+// @Description  Get webhook by id
+// @Tags         Webhooks
+// @Produce      json
+// @Param        webhookId   path      integer  true  "Webhook id"
+// @Success      200 {object} WebhookOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /webhooks/{webhookId} [get]
+func (h *WebhookHandler) GetWebhookById(ctx *gin.Context) {
+	id, ok := h.pathID(ctx, "webhookId")
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhookService.FindSubscriptionById(ctx, id)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error finding webhook by id", slog.Any("error", err))
+		_ = ctx.Error(err)
+		return
+	}
+
+	if subscription == nil {
+		h.logger.ErrorContext(ctx, "webhook not found")
+		_ = ctx.Error(webhook.ErrSubscriptionNotFound)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookOutputDTO(subscription))
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete webhook
+// @Description  Deactivate a webhook. Its pending deliveries are given up.
+// @Tags         Webhooks
+// @Param        webhookId   path      integer  true  "Webhook id"
+// @Success      204
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /webhooks/{webhookId} [delete]
+func (h *WebhookHandler) DeleteWebhook(ctx *gin.Context) {
+	id, ok := h.pathID(ctx, "webhookId")
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(ctx, id); err != nil {
+		h.respondWebhookError(ctx, "error deleting webhook", err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "webhook deleted successfully", slog.Int("webhook_id", id))
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary      List webhook deliveries
+// @Description  List the webhook's latest 100 deliveries, newest first
+// @Tags         Webhooks
+// @Produce      json
+// @Param        webhookId   path      integer  true  "Webhook id"
+// @Success      200 {array} DeliveryOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /webhooks/{webhookId}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(ctx *gin.Context) {
+	id, ok := h.pathID(ctx, "webhookId")
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhookService.Deliveries(ctx, id)
+	if err != nil {
+		h.respondWebhookError(ctx, "error listing webhook deliveries", err)
+		return
+	}
+
+	output := make([]DeliveryOutputDTO, 0, len(deliveries))
+	for i := range deliveries {
+		output = append(output, newDeliveryOutputDTO(&deliveries[i]))
+	}
+
+	ctx.JSON(http.StatusOK, output)
+}
+
+// GetWebhookDelivery godoc
+// @Summary      Show webhook delivery
+// @Description  Get a delivery with the log of its attempts
+// @Tags         Webhooks
+// @Produce      json
+// @Param        webhookId    path      integer  true  "Webhook id"
+// @Param        deliveryId   path      integer  true  "Delivery id"
+// @Success      200 {object} DeliveryOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /webhooks/{webhookId}/deliveries/{deliveryId} [get]
+func (h *WebhookHandler) GetWebhookDelivery(ctx *gin.Context) {
+	webhookID, ok := h.pathID(ctx, "webhookId")
+	if !ok {
+		return
+	}
+
+	id, ok := h.pathID(ctx, "deliveryId")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.FindDeliveryById(ctx, webhookID, id)
+	if err != nil {
+		h.respondWebhookError(ctx, "error finding webhook delivery", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newDeliveryOutputDTO(delivery))
+}
+
+// RedeliverWebhookDelivery godoc
+// @Summary      Redeliver webhook delivery
+// @Description  Send a delivery again right away, with a fresh set of retries, whatever its status
+// @Tags         Webhooks
+// @Produce      json
+// @Param        webhookId    path      integer  true  "Webhook id"
+// @Param        deliveryId   path      integer  true  "Delivery id"
+// @Success      202 {object} DeliveryOutputDTO
+// @Failure      500 {object} Problem
+// @Failure      409 {object} Problem
+// @Failure      404 {object} Problem
+// @Failure      400 {object} Problem
+// @Router       /webhooks/{webhookId}/deliveries/{deliveryId}/redeliver [post]
+func (h *WebhookHandler) RedeliverWebhookDelivery(ctx *gin.Context) {
+	webhookID, ok := h.pathID(ctx, "webhookId")
+	if !ok {
+		return
+	}
+
+	id, ok := h.pathID(ctx, "deliveryId")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(ctx, webhookID, id)
+	if err != nil {
+		h.respondWebhookError(ctx, "error redelivering webhook delivery", err)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "webhook delivery scheduled again", slog.Int("delivery_id", id))
+	ctx.JSON(http.StatusAccepted, newDeliveryOutputDTO(delivery))
+}
+
+func (h *WebhookHandler) pathID(ctx *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param(name))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "error getting "+name, slog.Any("error", err))
+		_ = ctx.Error(invalidField(name))
+		return 0, false
+	}
+
+	return id, true
+}
+
+func (h *WebhookHandler) respondWebhookError(ctx *gin.Context, message string, err error) {
+	h.logger.ErrorContext(ctx, message, slog.Any("error", err))
+	_ = ctx.Error(errors.Join(err, ErrUpdateWebhook))
+}
+
+func newWebhookOutputDTO(s *webhook.Subscription) WebhookOutputDTO {
+	return WebhookOutputDTO{
+		WebhookID:  s.ID,
+		URL:        s.URL,
+		EventTypes: s.EventTypes,
+		AccountID:  s.AccountID,
+		Active:     s.Active,
+		CreatedAt:  s.CreatedAt,
+	}
+}
+
+func newDeliveryOutputDTO(d *webhook.Delivery) DeliveryOutputDTO {
+	output := DeliveryOutputDTO{
+		DeliveryID:     d.ID,
+		WebhookID:      d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		AttemptCount:   d.AttemptCount,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+
+	for _, a := range d.Attempts {
+		output.Attempts = append(output.Attempts, DeliveryAttemptOutputDTO{
+			StatusCode:  a.StatusCode,
+			Error:       a.Error,
+			AttemptedAt: a.AttemptedAt,
+		})
+	}
+
+	return output
+}
@@ -2,12 +2,15 @@ package main
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/shopspring/decimal"
 	"github.com/supwr/pismo-transactions/api/handler"
 	_ "github.com/supwr/pismo-transactions/docs"
+	"github.com/supwr/pismo-transactions/pkg/metrics"
 	swaggerFiles "github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
 	"go.uber.org/fx"
+	"net/http"
 )
 
 // @title           Transactions API
@@ -16,18 +19,58 @@ func main() {
 	decimal.MarshalJSONWithoutQuotes = true
 
 	app := createApp(
-		fx.Invoke(func(accountHandler *handler.AccountHandler, transactionHandler *handler.TransactionHandler) {
-			api := gin.Default()
-
+		fx.Invoke(func(
+			api *gin.Engine,
+			registry *prometheus.Registry,
+			_ *http.Server,
+			accountHandler *handler.AccountHandler,
+			transactionHandler *handler.TransactionHandler,
+			operationTypeHandler *handler.OperationTypeHandler,
+			invoiceHandler *handler.InvoiceHandler,
+			feeHandler *handler.FeeHandler,
+			authorizationHandler *handler.AuthorizationHandler,
+			webhookHandler *handler.WebhookHandler,
+			healthHandler *handler.HealthHandler,
+		) {
 			// routes
 			api.GET("/accounts/:accountId", accountHandler.GetAccountById)
 			api.POST("/accounts", accountHandler.CreateAccount)
+			api.PATCH("/accounts/:accountId", accountHandler.UpdateAccount)
+			api.PUT("/accounts/:accountId/credit-limit", accountHandler.ChangeCreditLimit)
+			api.GET("/accounts/:accountId/credit-limit/history", accountHandler.GetCreditLimitHistory)
+			api.GET("/accounts/:accountId/balance", accountHandler.GetAccountBalance)
+			api.GET("/accounts/:accountId/transactions", transactionHandler.ListAccountTransactions)
+			api.GET("/accounts/:accountId/invoices", invoiceHandler.ListAccountInvoices)
+			api.GET("/accounts/:accountId/invoices/current", invoiceHandler.GetCurrentInvoice)
+			api.GET("/invoices/:invoiceId", invoiceHandler.GetInvoiceById)
 			api.POST("/transactions", transactionHandler.CreateTransaction)
+			api.GET("/transactions/:transactionId", transactionHandler.GetTransactionById)
+			api.GET("/transactions/:transactionId/discharges", transactionHandler.GetTransactionDischarges)
+			api.POST("/transactions/:transactionId/reversal", transactionHandler.ReverseTransaction)
+			api.GET("/transactions/:transactionId/installment-plan", transactionHandler.GetTransactionInstallmentPlan)
+			api.POST("/authorizations", authorizationHandler.CreateAuthorization)
+			api.GET("/authorizations/:authorizationId", authorizationHandler.GetAuthorizationById)
+			api.POST("/authorizations/:authorizationId/capture", authorizationHandler.CaptureAuthorization)
+			api.POST("/authorizations/:authorizationId/void", authorizationHandler.VoidAuthorization)
+			api.GET("/operation-types", operationTypeHandler.ListOperationTypes)
+			api.POST("/operation-types", operationTypeHandler.CreateOperationType)
+			api.GET("/operation-types/:operationTypeId", operationTypeHandler.GetOperationTypeById)
+			api.PUT("/operation-types/:operationTypeId", operationTypeHandler.UpdateOperationType)
+			api.DELETE("/operation-types/:operationTypeId", operationTypeHandler.DeleteOperationType)
+			api.GET("/fee-rates", feeHandler.ListFeeRates)
+			api.POST("/fee-rates", feeHandler.CreateFeeRate)
+			api.GET("/webhooks", webhookHandler.ListWebhooks)
+			api.POST("/webhooks", webhookHandler.CreateWebhook)
+			api.GET("/webhooks/:webhookId", webhookHandler.GetWebhookById)
+			api.DELETE("/webhooks/:webhookId", webhookHandler.DeleteWebhook)
+			api.GET("/webhooks/:webhookId/deliveries", webhookHandler.ListWebhookDeliveries)
+			api.GET("/webhooks/:webhookId/deliveries/:deliveryId", webhookHandler.GetWebhookDelivery)
+			api.POST("/webhooks/:webhookId/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhookDelivery)
+			api.GET("/healthz", healthHandler.Liveness)
+			api.GET("/readyz", healthHandler.Readiness)
+			api.GET("/metrics", gin.WrapH(metrics.Handler(registry)))
 			api.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-
-			api.Run()
 		}),
-		fx.Invoke(func(s fx.Shutdowner) { _ = s.Shutdown() }),
 	)
 
 	app.Run()
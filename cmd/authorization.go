@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"github.com/supwr/pismo-transactions/internal/authorization"
+	"go.uber.org/fx"
+	"log/slog"
+)
+
+// expireAuthorizations releases the holds of the authorizations that weren't captured or voided before expiring.
+// It's meant to run often, e.g. hourly, since expired holds keep the limit unavailable until then.
+func expireAuthorizations(_ []string) int {
+	code := exitOK
+
+	app := createApp(
+		fx.Invoke(func(s *authorization.Service, l *slog.Logger) {
+			ctx := context.Background()
+
+			expired, err := s.ExpireStale(ctx)
+			if err != nil {
+				l.ErrorContext(ctx, "error expiring authorizations", slog.Any("error", err))
+				code = exitError
+			}
+
+			l.InfoContext(ctx, "authorizations expired", slog.Int("authorizations", expired))
+		}),
+		fx.Invoke(func(s fx.Shutdowner) { _ = s.Shutdown() }),
+	)
+
+	app.Run()
+
+	return code
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/supwr/pismo-transactions/internal/billing"
+	"go.uber.org/fx"
+	"log/slog"
+)
+
+// closeCycles closes the billing cycles that reached their closing date into invoices. It's meant to run daily,
+// e.g. from cron; missed days are caught up on the next run.
+func closeCycles(args []string) int {
+	flags := flag.NewFlagSet("close-cycles", flag.ContinueOnError)
+	accountID := flags.Int("account", 0, "only close the cycles of this account")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	code := exitOK
+
+	app := createApp(
+		fx.Invoke(func(s *billing.Service, l *slog.Logger) {
+			ctx := context.Background()
+
+			var err error
+			var closed int
+
+			if *accountID != 0 {
+				var invoices []billing.Invoice
+				invoices, err = s.CloseAccountCycles(ctx, *accountID)
+				closed = len(invoices)
+			} else {
+				closed, err = s.CloseCycles(ctx)
+			}
+
+			if err != nil {
+				l.ErrorContext(ctx, "error closing billing cycles", slog.Any("error", err))
+				code = exitError
+			}
+
+			l.InfoContext(ctx, "billing cycles closed", slog.Int("invoices", closed))
+		}),
+		fx.Invoke(func(s fx.Shutdowner) { _ = s.Shutdown() }),
+	)
+
+	app.Run()
+
+	return code
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"github.com/supwr/pismo-transactions/internal/fee"
+	"go.uber.org/fx"
+	"log/slog"
+)
+
+// accrueFees charges the interest and late fees of overdue invoices for the days that ended since the last run.
+// It's meant to run daily, after close-cycles, so invoice statuses are up to date.
+func accrueFees(_ []string) int {
+	code := exitOK
+
+	app := createApp(
+		fx.Invoke(func(s *fee.Service, l *slog.Logger) {
+			ctx := context.Background()
+
+			posted, err := s.Accrue(ctx)
+			if err != nil {
+				l.ErrorContext(ctx, "error accruing fees", slog.Any("error", err))
+				code = exitError
+			}
+
+			l.InfoContext(ctx, "fees accrued", slog.Int("fees", posted))
+		}),
+		fx.Invoke(func(s fx.Shutdowner) { _ = s.Shutdown() }),
+	)
+
+	app.Run()
+
+	return code
+}
@@ -1,7 +1,22 @@
 package main
 
 import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/authorization"
+	"github.com/supwr/pismo-transactions/internal/billing"
+	"github.com/supwr/pismo-transactions/internal/fee"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"github.com/supwr/pismo-transactions/internal/ledger"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"github.com/supwr/pismo-transactions/internal/outbox"
+	"github.com/supwr/pismo-transactions/internal/reconciliation"
+	"github.com/supwr/pismo-transactions/internal/transaction"
+	"github.com/supwr/pismo-transactions/internal/webhook"
+	"github.com/supwr/pismo-transactions/pkg/clock"
 	"github.com/supwr/pismo-transactions/pkg/database"
+	"github.com/supwr/pismo-transactions/pkg/logging"
+	"github.com/supwr/pismo-transactions/pkg/metrics"
 	"go.uber.org/fx"
 	"log/slog"
 	"os"
@@ -10,14 +25,168 @@ import (
 func createApp(o ...fx.Option) *fx.App {
 	options := []fx.Option{
 		database.Module(),
+		metrics.Module(),
 		fx.Provide(
 			newLogger,
+			logging.NewConfig,
+			webhook.NewConfig,
+			newClock,
+
+			//services
+			newAccountService,
+			newReconciliationService,
+			newInstallmentService,
+			newBillingService,
+			newTransactionService,
+			newTransactionMetrics,
+			newOperationTypeService,
+			newLedgerService,
+			newFeeService,
+			newAuthorizationService,
+			newOutboxService,
+			newWebhookService,
+
+			// repositories
+			fx.Annotate(
+				account.NewRepository,
+				fx.As(new(account.RepositoryInterface)),
+			),
+			fx.Annotate(
+				reconciliation.NewRepository,
+				fx.As(new(reconciliation.RepositoryInterface)),
+			),
+			fx.Annotate(
+				installment.NewRepository,
+				fx.As(new(installment.RepositoryInterface)),
+			),
+			fx.Annotate(
+				billing.NewRepository,
+				fx.As(new(billing.RepositoryInterface)),
+			),
+			fx.Annotate(
+				transaction.NewRepository,
+				fx.As(new(transaction.RepositoryInterface)),
+			),
+			fx.Annotate(
+				operationtype.NewRepository,
+				fx.As(new(operationtype.RepositoryInterface)),
+			),
+			fx.Annotate(
+				ledger.NewRepository,
+				fx.As(new(ledger.RepositoryInterface)),
+			),
+			fx.Annotate(
+				fee.NewRepository,
+				fx.As(new(fee.RepositoryInterface)),
+			),
+			fx.Annotate(
+				authorization.NewRepository,
+				fx.As(new(authorization.RepositoryInterface)),
+			),
+			fx.Annotate(
+				outbox.NewRepository,
+				fx.As(new(outbox.RepositoryInterface)),
+			),
+			fx.Annotate(
+				webhook.NewRepository,
+				fx.As(new(webhook.RepositoryInterface)),
+			),
 		),
 	}
 
 	return fx.New(append(options, o...)...)
 }
 
-func newLogger() *slog.Logger {
-	return slog.New(slog.NewTextHandler(os.Stderr, nil))
+func newLogger(cfg logging.Config) (*slog.Logger, error) {
+	h, err := logging.NewHandler(os.Stderr, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return slog.New(h), nil
+}
+
+func newAccountService(r account.RepositoryInterface, o *outbox.Service, c clock.Clock, tm database.TxManager) *account.Service {
+	return account.NewService(r, o, c, tm)
+}
+
+func newReconciliationService(r reconciliation.RepositoryInterface, a *account.Service, tm database.TxManager) *reconciliation.Service {
+	return reconciliation.NewService(r, a, tm)
+}
+
+func newInstallmentService(r installment.RepositoryInterface) *installment.Service {
+	return installment.NewService(r)
+}
+
+func newBillingService(
+	r billing.RepositoryInterface,
+	a *account.Service,
+	i *installment.Service,
+	o *operationtype.Service,
+	t *transaction.Service,
+	c clock.Clock,
+	tm database.TxManager,
+) *billing.Service {
+	return billing.NewService(r, a, i, o, t, c, tm)
+}
+
+func newTransactionService(
+	r transaction.RepositoryInterface,
+	a *account.Service,
+	i *installment.Service,
+	o *operationtype.Service,
+	ls *ledger.Service,
+	e *outbox.Service,
+	m *transaction.Metrics,
+	c clock.Clock,
+	tm database.TxManager,
+) *transaction.Service {
+	return transaction.NewService(r, a, i, o, ls, e, m, c, tm)
+}
+
+func newTransactionMetrics(r prometheus.Registerer) *transaction.Metrics {
+	return transaction.NewMetrics(r)
+}
+
+func newOperationTypeService(r operationtype.RepositoryInterface, c clock.Clock) *operationtype.Service {
+	return operationtype.NewService(r, c)
+}
+
+func newLedgerService(r ledger.RepositoryInterface) *ledger.Service {
+	return ledger.NewService(r)
+}
+
+func newFeeService(
+	r fee.RepositoryInterface,
+	a *account.Service,
+	b *billing.Service,
+	o *operationtype.Service,
+	t *transaction.Service,
+	c clock.Clock,
+	tm database.TxManager,
+) *fee.Service {
+	return fee.NewService(r, a, b, o, t, c, tm)
+}
+
+func newAuthorizationService(
+	r authorization.RepositoryInterface,
+	a *account.Service,
+	o *operationtype.Service,
+	t *transaction.Service,
+	c clock.Clock,
+	tm database.TxManager,
+) *authorization.Service {
+	return authorization.NewService(r, a, o, t, c, tm)
+}
+
+func newOutboxService(r outbox.RepositoryInterface, c clock.Clock) *outbox.Service {
+	return outbox.NewService(r, c)
+}
+
+func newWebhookService(r webhook.RepositoryInterface, c clock.Clock, tm database.TxManager, cfg webhook.Config) *webhook.Service {
+	return webhook.NewService(r, c, tm, cfg)
+}
+
+func newClock() clock.Clock {
+	return clock.NewClock()
 }
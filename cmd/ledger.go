@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/supwr/pismo-transactions/internal/ledger"
+	"go.uber.org/fx"
+	"log/slog"
+)
+
+// verifyLedger checks that the ledger debits as much as it credits, entry by entry. It exits with exitMismatches
+// when it doesn't, so it can run as a check, e.g. from cron after the daily jobs.
+func verifyLedger(args []string) int {
+	flags := flag.NewFlagSet("verify-ledger", flag.ContinueOnError)
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	code := exitOK
+
+	app := createApp(
+		fx.Invoke(func(s *ledger.Service, l *slog.Logger) {
+			ctx := context.Background()
+
+			err := s.Verify(ctx)
+
+			switch {
+			case errors.Is(err, ledger.ErrUnbalancedLedger):
+				l.ErrorContext(ctx, "ledger doesn't balance", slog.Any("error", err))
+				code = exitMismatches
+			case err != nil:
+				l.ErrorContext(ctx, "error verifying ledger", slog.Any("error", err))
+				code = exitError
+			default:
+				l.InfoContext(ctx, "ledger balances")
+			}
+		}),
+		fx.Invoke(func(s fx.Shutdowner) { _ = s.Shutdown() }),
+	)
+
+	app.Run()
+
+	return code
+}
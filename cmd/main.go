@@ -1,16 +1,39 @@
 package main
 
 import (
+	"fmt"
 	"github.com/shopspring/decimal"
 	"github.com/supwr/pismo-transactions/pkg/database"
 	"go.uber.org/fx"
+	"os"
 )
 
 const devEnv = "DEV"
 
+// commands are the subcommands the binary runs instead of the migrations, e.g. `cmd reconcile -format csv`.
+var commands = map[string]func(args []string) int{
+	"reconcile":             reconcile,
+	"close-cycles":          closeCycles,
+	"accrue-fees":           accrueFees,
+	"expire-authorizations": expireAuthorizations,
+	"relay-outbox":          relayOutbox,
+	"deliver-webhooks":      deliverWebhooks,
+	"verify-ledger":         verifyLedger,
+}
+
 func main() {
 	decimal.MarshalJSONWithoutQuotes = true
 
+	if len(os.Args) > 1 {
+		command, ok := commands[os.Args[1]]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+			os.Exit(exitError)
+		}
+
+		os.Exit(command(os.Args[2:]))
+	}
+
 	app := createApp(
 		fx.Invoke(func(cfg database.Config, migration *database.Migration) {
 			if cfg.Environment == devEnv {
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/supwr/pismo-transactions/internal/outbox"
+	"github.com/supwr/pismo-transactions/internal/webhook"
+	"go.uber.org/fx"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	publisherStdout   = "stdout"
+	publisherWebhooks = "webhooks"
+)
+
+// relayOutbox publishes the events waiting in the outbox, either as JSON lines on stdout or appended to -output,
+// or queued for delivery to the webhooks subscribed to them. It keeps polling every -interval until it's
+// interrupted, or stops once the outbox is drained with -once.
+func relayOutbox(args []string) int {
+	flags := flag.NewFlagSet("relay-outbox", flag.ContinueOnError)
+	publisherName := flags.String("publisher", publisherStdout, "where to publish the events: stdout or webhooks")
+	output := flags.String("output", "", "append the events to this file instead of stdout")
+	batchSize := flags.Int("batch", 100, "how many events to claim and publish at a time")
+	interval := flags.Duration("interval", 5*time.Second, "how long to wait when the outbox is empty")
+	once := flags.Bool("once", false, "stop once there is nothing left to publish")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if *publisherName != publisherStdout && *publisherName != publisherWebhooks {
+		fmt.Fprintf(os.Stderr, "unknown publisher %q\n", *publisherName)
+		return exitError
+	}
+
+	var w io.Writer = os.Stdout
+
+	if *output != "" {
+		f, err := os.OpenFile(*output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	code := exitOK
+
+	app := createApp(
+		fx.Invoke(func(s *outbox.Service, ws *webhook.Service, l *slog.Logger) {
+			var publisher outbox.Publisher = outbox.NewWriterPublisher(w)
+
+			if *publisherName == publisherWebhooks {
+				publisher = ws
+			}
+
+			poll(*interval, *once, func(ctx context.Context) bool {
+				published, err := s.Relay(ctx, publisher, *batchSize)
+				if err != nil {
+					l.ErrorContext(ctx, "error relaying outbox events", slog.Any("error", err))
+					code = exitError
+				}
+
+				if published > 0 {
+					l.InfoContext(ctx, "outbox events published", slog.Int("events", published))
+				}
+
+				// a full batch means there may be more waiting
+				return err == nil && published == *batchSize
+			})
+		}),
+		fx.Invoke(func(s fx.Shutdowner) { _ = s.Shutdown() }),
+	)
+
+	app.Run()
+
+	return code
+}
+
+// poll runs work until it's interrupted, waiting interval between runs unless work says there is more to do right
+// away. With once it stops as soon as there is nothing left.
+func poll(interval time.Duration, once bool, work func(ctx context.Context) bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for {
+		if work(ctx) {
+			continue
+		}
+
+		if once {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/supwr/pismo-transactions/internal/reconciliation"
+	"go.uber.org/fx"
+	"io"
+	"log/slog"
+	"os"
+)
+
+const (
+	exitOK = iota
+	exitError
+	exitMismatches
+)
+
+// reconcile compares each account's available limit with its transaction history and reports the ones that
+// drifted. Repairs are only written with both -repair and -confirm; -repair alone is a dry run. It exits with
+// exitMismatches when drift was found and left unrepaired, so it can be used as a check.
+func reconcile(args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	format := flags.String("format", reconciliation.FormatJSON, "report format: json or csv")
+	repair := flags.Bool("repair", false, "repair mismatched accounts (dry run unless -confirm is given)")
+	confirm := flags.Bool("confirm", false, "write the repairs to the database")
+	output := flags.String("output", "", "write the report to this file instead of stdout")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if *format != reconciliation.FormatJSON && *format != reconciliation.FormatCSV {
+		fmt.Fprintln(os.Stderr, reconciliation.ErrUnknownFormat)
+		return exitError
+	}
+
+	apply := *repair && *confirm
+	code := exitOK
+
+	app := createApp(
+		fx.Invoke(func(s *reconciliation.Service, l *slog.Logger) {
+			ctx := context.Background()
+
+			mismatches, err := s.Run(ctx, apply)
+			if err != nil {
+				l.ErrorContext(ctx, "error reconciling accounts", slog.Any("error", err))
+				code = exitError
+			}
+
+			if err = writeReport(*output, *format, mismatches); err != nil {
+				l.ErrorContext(ctx, "error writing reconciliation report", slog.Any("error", err))
+				code = exitError
+			}
+
+			if *repair && !*confirm && len(mismatches) > 0 {
+				l.InfoContext(ctx, "dry run, no account was changed; run again with -confirm to repair", slog.Int("mismatches", len(mismatches)))
+			}
+
+			if code == exitOK && !apply && len(mismatches) > 0 {
+				code = exitMismatches
+			}
+		}),
+		fx.Invoke(func(s fx.Shutdowner) { _ = s.Shutdown() }),
+	)
+
+	app.Run()
+
+	return code
+}
+
+func writeReport(path string, format string, mismatches []reconciliation.Mismatch) error {
+	var w io.Writer = os.Stdout
+
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	return reconciliation.WriteReport(w, format, mismatches)
+}
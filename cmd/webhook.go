@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/supwr/pismo-transactions/internal/webhook"
+	"go.uber.org/fx"
+	"log/slog"
+	"time"
+)
+
+// deliverWebhooks posts the webhook deliveries that are due, retrying failed ones with exponential backoff. It keeps
+// polling every -interval until it's interrupted, or stops once nothing is due with -once.
+func deliverWebhooks(args []string) int {
+	flags := flag.NewFlagSet("deliver-webhooks", flag.ContinueOnError)
+	batchSize := flags.Int("batch", 50, "how many deliveries to send per run")
+	interval := flags.Duration("interval", 5*time.Second, "how long to wait when nothing is due")
+	once := flags.Bool("once", false, "stop once nothing is due")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	code := exitOK
+
+	app := createApp(
+		fx.Invoke(func(s *webhook.Service, l *slog.Logger) {
+			poll(*interval, *once, func(ctx context.Context) bool {
+				delivered, err := s.DeliverDue(ctx, *batchSize)
+				if err != nil {
+					l.ErrorContext(ctx, "error delivering webhooks", slog.Any("error", err))
+					code = exitError
+				}
+
+				if delivered > 0 {
+					l.InfoContext(ctx, "webhooks delivered", slog.Int("deliveries", delivered))
+				}
+
+				return err == nil && delivered == *batchSize
+			})
+		}),
+		fx.Invoke(func(s fx.Shutdowner) { _ = s.Shutdown() }),
+	)
+
+	app.Run()
+
+	return code
+}
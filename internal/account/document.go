@@ -0,0 +1,76 @@
+package account
+
+import (
+	"strings"
+)
+
+const (
+	DocumentTypeCPF  = "CPF"
+	DocumentTypeCNPJ = "CNPJ"
+)
+
+type Document string
+
+// Normalize strips the usual CPF and CNPJ punctuation, so formatted and digits-only documents are the same.
+// Documents with anything other than digits and punctuation are returned as they are and fail validation.
+func (d Document) Normalize() Document {
+	var digits strings.Builder
+
+	for _, r := range strings.TrimSpace(string(d)) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '.' || r == '-' || r == '/' || r == ' ':
+		default:
+			return d
+		}
+	}
+
+	return Document(digits.String())
+}
+
+// Type tells whether the normalized document is a valid CPF or CNPJ, checking its verification digits.
+func (d Document) Type() (string, error) {
+	switch {
+	case len(d) == 11 && validDigits(string(d), []int{10, 9, 8, 7, 6, 5, 4, 3, 2}):
+		return DocumentTypeCPF, nil
+	case len(d) == 14 && validDigits(string(d), []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}):
+		return DocumentTypeCNPJ, nil
+	default:
+		return "", ErrInvalidDocument
+	}
+}
+
+// validDigits checks the two verification digits at the end of document. The first one is computed with weights
+// over the base digits, and the second with the same weights shifted by one over the base plus the first digit.
+func validDigits(document string, weights []int) bool {
+	base := len(weights)
+
+	if strings.Count(document, document[:1]) == len(document) {
+		return false
+	}
+
+	for i := 0; i < len(document); i++ {
+		if document[i] < '0' || document[i] > '9' {
+			return false
+		}
+	}
+
+	first := verificationDigit(document[:base], append([]int{}, weights...))
+	second := verificationDigit(document[:base+1], append([]int{weights[0] + 1}, weights...))
+
+	return int(document[base]-'0') == first && int(document[base+1]-'0') == second
+}
+
+func verificationDigit(digits string, weights []int) int {
+	sum := 0
+	for i := range digits {
+		sum += int(digits[i]-'0') * weights[i]
+	}
+
+	if rest := sum % 11; rest >= 2 {
+		return 11 - rest
+	}
+
+	return 0
+}
@@ -0,0 +1,43 @@
+package account
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDocument_Normalize(t *testing.T) {
+	t.Run("strip punctuation", func(t *testing.T) {
+		assert.Equal(t, Document("52998224725"), Document("529.982.247-25").Normalize())
+		assert.Equal(t, Document("11222333000181"), Document(" 11.222.333/0001-81 ").Normalize())
+		assert.Equal(t, Document("52998224725"), Document("52998224725").Normalize())
+	})
+
+	t.Run("keep documents with other characters as they are", func(t *testing.T) {
+		assert.Equal(t, Document("abc"), Document("abc").Normalize())
+		assert.Equal(t, Document("529x98224725"), Document("529x98224725").Normalize())
+	})
+}
+
+func TestDocument_Type(t *testing.T) {
+	t.Run("valid documents", func(t *testing.T) {
+		documentType, err := Document("52998224725").Type()
+		assert.Nil(t, err)
+		assert.Equal(t, DocumentTypeCPF, documentType)
+
+		documentType, err = Document("11144477735").Type()
+		assert.Nil(t, err)
+		assert.Equal(t, DocumentTypeCPF, documentType)
+
+		documentType, err = Document("11222333000181").Type()
+		assert.Nil(t, err)
+		assert.Equal(t, DocumentTypeCNPJ, documentType)
+	})
+
+	t.Run("invalid documents", func(t *testing.T) {
+		for _, document := range []Document{"", "abc", "123456", "52998224724", "11111111111", "11222333000182", "00000000000000", "529.982.247-25"} {
+			documentType, err := document.Type()
+			assert.Empty(t, documentType)
+			assert.ErrorIs(t, err, ErrInvalidDocument, string(document))
+		}
+	})
+}
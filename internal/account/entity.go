@@ -5,13 +5,74 @@ import (
 	"time"
 )
 
-type Document string
+const (
+	StatusActive  = "ACTIVE"
+	StatusBlocked = "BLOCKED"
+	StatusClosed  = "CLOSED"
+)
+
+// Billing days are kept within 28 so every month has them.
+const (
+	DefaultClosingDay = 3
+	DefaultDueDay     = 10
+	MaxBillingDay     = 28
+)
 
 type Account struct {
 	ID                   int             `json:"id" gorm:"primaryKey"`
 	Document             Document        `json:"document"`
+	DocumentType         string          `json:"document_type"`
+	CreditLimit          decimal.Decimal `json:"credit_limit"`
 	AvailableCreditLimit decimal.Decimal `json:"available_credit_limit"`
+	Status               string          `json:"status"`
+	StatusReason         string          `json:"status_reason"`
+	StatusChangedAt      *time.Time      `json:"status_changed_at"`
+	ClosingDay           int             `json:"closing_day"`
+	DueDay               int             `json:"due_day"`
 	CreatedAt            time.Time       `json:"created_at"`
 	UpdatedAt            *time.Time      `json:"updated_at"`
 	DeletedAt            *time.Time      `json:"deleted_at"`
 }
+
+// CreditLimitChange records who changed an account's credit limit, why, and how both limits moved.
+type CreditLimitChange struct {
+	ID                           int             `json:"id" gorm:"primaryKey"`
+	AccountID                    int             `json:"account_id"`
+	PreviousCreditLimit          decimal.Decimal `json:"previous_credit_limit"`
+	CreditLimit                  decimal.Decimal `json:"credit_limit"`
+	PreviousAvailableCreditLimit decimal.Decimal `json:"previous_available_credit_limit"`
+	AvailableCreditLimit         decimal.Decimal `json:"available_credit_limit"`
+	Reason                       string          `json:"reason"`
+	Actor                        string          `json:"actor"`
+	CreatedAt                    time.Time       `json:"created_at"`
+}
+
+// Patch holds the account properties to change. Nil fields are left untouched.
+type Patch struct {
+	Document   *Document
+	Status     *string
+	Reason     string
+	ClosingDay *int
+	DueDay     *int
+}
+
+func (a *Account) IsBlocked() bool {
+	return a.Status == StatusBlocked
+}
+
+func (a *Account) IsClosed() bool {
+	return a.Status == StatusClosed
+}
+
+// validBillingDays tells whether the account's invoice closing and due days fit in every month and differ.
+func (a *Account) validBillingDays() bool {
+	return a.ClosingDay >= 1 && a.ClosingDay <= MaxBillingDay &&
+		a.DueDay >= 1 && a.DueDay <= MaxBillingDay &&
+		a.ClosingDay != a.DueDay
+}
+
+// transitions lists the statuses an account can move to from each status. Closing is final.
+var transitions = map[string][]string{
+	StatusActive:  {StatusBlocked, StatusClosed},
+	StatusBlocked: {StatusActive, StatusClosed},
+}
@@ -3,5 +3,16 @@ package account
 import "errors"
 
 var (
-	ErrAccountAlreadyExists = errors.New("There's already an account with this document")
+	ErrAccountAlreadyExists    = errors.New("There's already an account with this document")
+	ErrAccountNotFound         = errors.New("Account not found")
+	ErrInvalidDocument         = errors.New("Document must be a valid CPF or CNPJ")
+	ErrAccountClosed           = errors.New("Account is closed")
+	ErrInvalidStatus           = errors.New("Status must be ACTIVE, BLOCKED or CLOSED")
+	ErrInvalidStatusTransition = errors.New("Account can't move to this status")
+	ErrStatusReasonRequired    = errors.New("A reason is required to block or close an account")
+	ErrAccountBalanceNotZero   = errors.New("Only accounts with zero balance can be closed")
+	ErrInvalidCreditLimit      = errors.New("Credit limit can't be negative")
+	ErrCreditLimitBelowUsage   = errors.New("Credit limit can't be lower than what is already in use")
+	ErrChangeReasonRequired    = errors.New("A reason and an actor are required to change the credit limit")
+	ErrInvalidBillingDay       = errors.New("Closing and due days must be different days between 1 and 28")
 )
@@ -0,0 +1,66 @@
+package account
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// AccountCreatedV1 is the payload of AccountCreated events.
+type AccountCreatedV1 struct {
+	ID                   int             `json:"id"`
+	Document             Document        `json:"document"`
+	DocumentType         string          `json:"document_type"`
+	CreditLimit          decimal.Decimal `json:"credit_limit"`
+	AvailableCreditLimit decimal.Decimal `json:"available_credit_limit"`
+	Status               string          `json:"status"`
+	ClosingDay           int             `json:"closing_day"`
+	DueDay               int             `json:"due_day"`
+	CreatedAt            time.Time       `json:"created_at"`
+}
+
+func (AccountCreatedV1) Version() int {
+	return 1
+}
+
+func newAccountCreatedV1(a *Account) AccountCreatedV1 {
+	return AccountCreatedV1{
+		ID:                   a.ID,
+		Document:             a.Document,
+		DocumentType:         a.DocumentType,
+		CreditLimit:          a.CreditLimit,
+		AvailableCreditLimit: a.AvailableCreditLimit,
+		Status:               a.Status,
+		ClosingDay:           a.ClosingDay,
+		DueDay:               a.DueDay,
+		CreatedAt:            a.CreatedAt,
+	}
+}
+
+// CreditLimitChangedV1 is the payload of CreditLimitChanged events.
+type CreditLimitChangedV1 struct {
+	AccountID                    int             `json:"account_id"`
+	PreviousCreditLimit          decimal.Decimal `json:"previous_credit_limit"`
+	CreditLimit                  decimal.Decimal `json:"credit_limit"`
+	PreviousAvailableCreditLimit decimal.Decimal `json:"previous_available_credit_limit"`
+	AvailableCreditLimit         decimal.Decimal `json:"available_credit_limit"`
+	Reason                       string          `json:"reason"`
+	Actor                        string          `json:"actor"`
+	ChangedAt                    time.Time       `json:"changed_at"`
+}
+
+func (CreditLimitChangedV1) Version() int {
+	return 1
+}
+
+func newCreditLimitChangedV1(c *CreditLimitChange) CreditLimitChangedV1 {
+	return CreditLimitChangedV1{
+		AccountID:                    c.AccountID,
+		PreviousCreditLimit:          c.PreviousCreditLimit,
+		CreditLimit:                  c.CreditLimit,
+		PreviousAvailableCreditLimit: c.PreviousAvailableCreditLimit,
+		AvailableCreditLimit:         c.AvailableCreditLimit,
+		Reason:                       c.Reason,
+		Actor:                        c.Actor,
+		ChangedAt:                    c.CreatedAt,
+	}
+}
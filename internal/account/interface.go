@@ -3,11 +3,18 @@ package account
 
 import (
 	"context"
+	"github.com/shopspring/decimal"
 )
 
 type RepositoryInterface interface {
 	Create(ctx context.Context, account *Account) error
 	UpdateAvailableLimit(ctx context.Context, account *Account) error
+	Update(ctx context.Context, account *Account) error
+	UpdateLimits(ctx context.Context, account *Account) error
+	CreateCreditLimitChange(ctx context.Context, change *CreditLimitChange) error
+	FindCreditLimitChanges(ctx context.Context, accountID int) ([]CreditLimitChange, error)
+	Balance(ctx context.Context, id int) (decimal.Decimal, error)
 	FindById(ctx context.Context, id int) (*Account, error)
+	FindByIdForUpdate(ctx context.Context, id int) (*Account, error)
 	FindByDocument(ctx context.Context, document Document) (*Account, error)
 }
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	decimal "github.com/shopspring/decimal"
 )
 
 // MockRepositoryInterface is a mock of RepositoryInterface interface.
@@ -34,6 +35,21 @@ func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder
 	return m.recorder
 }
 
+// Balance mocks base method.
+func (m *MockRepositoryInterface) Balance(ctx context.Context, id int) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Balance", ctx, id)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Balance indicates an expected call of Balance.
+func (mr *MockRepositoryInterfaceMockRecorder) Balance(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Balance", reflect.TypeOf((*MockRepositoryInterface)(nil).Balance), ctx, id)
+}
+
 // Create mocks base method.
 func (m *MockRepositoryInterface) Create(ctx context.Context, account *Account) error {
 	m.ctrl.T.Helper()
@@ -48,6 +64,20 @@ func (mr *MockRepositoryInterfaceMockRecorder) Create(ctx, account interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepositoryInterface)(nil).Create), ctx, account)
 }
 
+// CreateCreditLimitChange mocks base method.
+func (m *MockRepositoryInterface) CreateCreditLimitChange(ctx context.Context, change *CreditLimitChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCreditLimitChange", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCreditLimitChange indicates an expected call of CreateCreditLimitChange.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateCreditLimitChange(ctx, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCreditLimitChange", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateCreditLimitChange), ctx, change)
+}
+
 // FindByDocument mocks base method.
 func (m *MockRepositoryInterface) FindByDocument(ctx context.Context, document Document) (*Account, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +108,50 @@ func (mr *MockRepositoryInterfaceMockRecorder) FindById(ctx, id interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockRepositoryInterface)(nil).FindById), ctx, id)
 }
 
+// FindByIdForUpdate mocks base method.
+func (m *MockRepositoryInterface) FindByIdForUpdate(ctx context.Context, id int) (*Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIdForUpdate", ctx, id)
+	ret0, _ := ret[0].(*Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIdForUpdate indicates an expected call of FindByIdForUpdate.
+func (mr *MockRepositoryInterfaceMockRecorder) FindByIdForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIdForUpdate", reflect.TypeOf((*MockRepositoryInterface)(nil).FindByIdForUpdate), ctx, id)
+}
+
+// FindCreditLimitChanges mocks base method.
+func (m *MockRepositoryInterface) FindCreditLimitChanges(ctx context.Context, accountID int) ([]CreditLimitChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCreditLimitChanges", ctx, accountID)
+	ret0, _ := ret[0].([]CreditLimitChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCreditLimitChanges indicates an expected call of FindCreditLimitChanges.
+func (mr *MockRepositoryInterfaceMockRecorder) FindCreditLimitChanges(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCreditLimitChanges", reflect.TypeOf((*MockRepositoryInterface)(nil).FindCreditLimitChanges), ctx, accountID)
+}
+
+// Update mocks base method.
+func (m *MockRepositoryInterface) Update(ctx context.Context, account *Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRepositoryInterfaceMockRecorder) Update(ctx, account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepositoryInterface)(nil).Update), ctx, account)
+}
+
 // UpdateAvailableLimit mocks base method.
 func (m *MockRepositoryInterface) UpdateAvailableLimit(ctx context.Context, account *Account) error {
 	m.ctrl.T.Helper()
@@ -91,3 +165,17 @@ func (mr *MockRepositoryInterfaceMockRecorder) UpdateAvailableLimit(ctx, account
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAvailableLimit", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateAvailableLimit), ctx, account)
 }
+
+// UpdateLimits mocks base method.
+func (m *MockRepositoryInterface) UpdateLimits(ctx context.Context, account *Account) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLimits", ctx, account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLimits indicates an expected call of UpdateLimits.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateLimits(ctx, account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLimits", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateLimits), ctx, account)
+}
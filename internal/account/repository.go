@@ -3,7 +3,10 @@ package account
 import (
 	"context"
 	"errors"
+	"github.com/shopspring/decimal"
+	"github.com/supwr/pismo-transactions/pkg/database"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log/slog"
 )
 
@@ -19,14 +22,24 @@ func NewRepository(db *gorm.DB, logger *slog.Logger) *Repository {
 	}
 }
 
+// Create stores the account. The unique index on open accounts' documents is what really prevents duplicates,
+// since concurrent requests can both get past the service's FindByDocument check.
 func (r *Repository) Create(ctx context.Context, account *Account) error {
-	return r.db.Create(account).Error
+	if err := database.Conn(ctx, r.db).Create(account).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return ErrAccountAlreadyExists
+		}
+
+		return err
+	}
+
+	return nil
 }
 
 func (r *Repository) FindById(ctx context.Context, id int) (*Account, error) {
 	var account *Account
 
-	if err := r.db.First(&account, "id = ? and deleted_at is null", id).Error; err != nil {
+	if err := database.Conn(ctx, r.db).First(&account, "id = ? and deleted_at is null", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -38,15 +51,95 @@ func (r *Repository) FindById(ctx context.Context, id int) (*Account, error) {
 	return account, nil
 }
 
+// FindByIdForUpdate locks the account row until the surrounding transaction ends, so concurrent
+// limit changes on the same account are applied one after the other. Closed accounts are returned
+// too, so callers can tell them apart from missing ones.
+func (r *Repository) FindByIdForUpdate(ctx context.Context, id int) (*Account, error) {
+	var account *Account
+
+	err := database.Conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&account, "id = ?", id).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		r.logger.ErrorContext(ctx, "error finding account for update", slog.Any("error", err))
+		return nil, err
+	}
+
+	return account, nil
+}
+
 func (r *Repository) UpdateAvailableLimit(ctx context.Context, account *Account) error {
 	var acc *Account
-	return r.db.Model(&acc).Where("id = ?", account.ID).Update("available_credit_limit", account.AvailableCreditLimit).Error
+	return database.Conn(ctx, r.db).Model(&acc).Where("id = ?", account.ID).Update("available_credit_limit", account.AvailableCreditLimit).Error
+}
+
+func (r *Repository) Update(ctx context.Context, account *Account) error {
+	err := database.Conn(ctx, r.db).
+		Model(account).
+		Select("document", "document_type", "status", "status_reason", "status_changed_at", "closing_day", "due_day", "deleted_at").
+		Updates(account).Error
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrAccountAlreadyExists
+	}
+
+	return err
+}
+
+func (r *Repository) UpdateLimits(ctx context.Context, account *Account) error {
+	return database.Conn(ctx, r.db).
+		Model(account).
+		Select("credit_limit", "available_credit_limit").
+		Updates(account).Error
+}
+
+func (r *Repository) CreateCreditLimitChange(ctx context.Context, change *CreditLimitChange) error {
+	return database.Conn(ctx, r.db).Create(change).Error
+}
+
+func (r *Repository) FindCreditLimitChanges(ctx context.Context, accountID int) ([]CreditLimitChange, error) {
+	var changes []CreditLimitChange
+
+	err := database.Conn(ctx, r.db).
+		Where("account_id = ?", accountID).
+		Order("created_at desc, id desc").
+		Find(&changes).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding credit limit changes", slog.Any("error", err))
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// Balance sums what is still open on the account's transactions: negative when it owes, positive when it has credit left.
+func (r *Repository) Balance(ctx context.Context, id int) (decimal.Decimal, error) {
+	var balance decimal.Decimal
+
+	err := database.Conn(ctx, r.db).
+		Table(r.db.NamingStrategy.TableName("Transaction")).
+		Select("coalesce(sum(balance), 0)").
+		Where("account_id = ? and deleted_at is null", id).
+		Scan(&balance).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error getting account balance", slog.Any("error", err))
+		return decimal.Zero, err
+	}
+
+	return balance, nil
 }
 
 func (r *Repository) FindByDocument(ctx context.Context, document Document) (*Account, error) {
 	var account *Account
 
-	if err := r.db.First(&account, "document = ? and deleted_at is null", document).Error; err != nil {
+	if err := database.Conn(ctx, r.db).First(&account, "document = ? and deleted_at is null", document).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
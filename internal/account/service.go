@@ -2,29 +2,86 @@ package account
 
 import (
 	"context"
+	"github.com/shopspring/decimal"
+	"github.com/supwr/pismo-transactions/internal/outbox"
+	"github.com/supwr/pismo-transactions/pkg/clock"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"github.com/supwr/pismo-transactions/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"slices"
+	"strings"
 )
 
+var tracer = otel.Tracer("github.com/supwr/pismo-transactions/internal/account")
+
 type Service struct {
-	repository RepositoryInterface
+	repository    RepositoryInterface
+	outboxService *outbox.Service
+	clock         clock.Clock
+	txManager     database.TxManager
 }
 
-func NewService(r RepositoryInterface) *Service {
-	return &Service{repository: r}
+func NewService(r RepositoryInterface, o *outbox.Service, c clock.Clock, tm database.TxManager) *Service {
+	return &Service{repository: r, outboxService: o, clock: c, txManager: tm}
 }
 
-func (s *Service) FindById(ctx context.Context, id int) (*Account, error) {
+func (s *Service) FindById(ctx context.Context, id int) (_ *Account, err error) {
+	ctx, span := tracer.Start(ctx, "account.Service.FindById")
+	defer func() { tracing.End(span, err) }()
+
 	return s.repository.FindById(ctx, id)
 }
 
-func (s *Service) FindByDocument(ctx context.Context, document Document) (*Account, error) {
-	return s.repository.FindByDocument(ctx, document)
+func (s *Service) FindByIdForUpdate(ctx context.Context, id int) (_ *Account, err error) {
+	ctx, span := tracer.Start(ctx, "account.Service.FindByIdForUpdate")
+	defer func() { tracing.End(span, err) }()
+
+	return s.repository.FindByIdForUpdate(ctx, id)
+}
+
+func (s *Service) FindByDocument(ctx context.Context, document Document) (_ *Account, err error) {
+	ctx, span := tracer.Start(ctx, "account.Service.FindByDocument")
+	defer func() { tracing.End(span, err) }()
+
+	return s.repository.FindByDocument(ctx, document.Normalize())
 }
 
-func (s *Service) UpdateCreditLimit(ctx context.Context, account *Account) error {
+func (s *Service) UpdateCreditLimit(ctx context.Context, account *Account) (err error) {
+	ctx, span := tracer.Start(ctx, "account.Service.UpdateCreditLimit")
+	defer func() { tracing.End(span, err) }()
+
 	return s.repository.UpdateAvailableLimit(ctx, account)
 }
 
-func (s *Service) Create(ctx context.Context, account *Account) error {
+func (s *Service) Create(ctx context.Context, account *Account) (err error) {
+	ctx, span := tracer.Start(ctx, "account.Service.Create")
+	defer func() { tracing.End(span, err) }()
+
+	if account.CreditLimit.IsNegative() {
+		return ErrInvalidCreditLimit
+	}
+
+	account.Document = account.Document.Normalize()
+
+	documentType, err := account.Document.Type()
+	if err != nil {
+		return err
+	}
+
+	account.DocumentType = documentType
+
+	if account.ClosingDay == 0 {
+		account.ClosingDay = DefaultClosingDay
+	}
+
+	if account.DueDay == 0 {
+		account.DueDay = DefaultDueDay
+	}
+
+	if !account.validBillingDays() {
+		return ErrInvalidBillingDay
+	}
+
 	exists, err := s.repository.FindByDocument(ctx, account.Document)
 	if err != nil {
 		return err
@@ -34,9 +91,215 @@ func (s *Service) Create(ctx context.Context, account *Account) error {
 		return ErrAccountAlreadyExists
 	}
 
-	if err = s.repository.Create(ctx, account); err != nil {
-		return err
+	account.Status = StatusActive
+	account.AvailableCreditLimit = account.CreditLimit
+
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.Create(ctx, account); err != nil {
+			return err
+		}
+
+		return s.outboxService.Record(ctx, outbox.EventAccountCreated, outbox.AggregateAccount, account.ID, newAccountCreatedV1(account))
+	})
+}
+
+// Update applies patch to the account. Status changes are stamped with their reason and time, and closing
+// soft-deletes the account, which is only allowed once nothing is owed on it and no credit is left.
+func (s *Service) Update(ctx context.Context, id int, patch Patch) (_ *Account, err error) {
+	ctx, span := tracer.Start(ctx, "account.Service.Update")
+	defer func() { tracing.End(span, err) }()
+
+	var account *Account
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		if account, err = s.repository.FindByIdForUpdate(ctx, id); err != nil {
+			return err
+		}
+
+		if account == nil {
+			return ErrAccountNotFound
+		}
+
+		if account.IsClosed() {
+			return ErrAccountClosed
+		}
+
+		if patch.Document != nil && patch.Document.Normalize() != account.Document {
+			document := patch.Document.Normalize()
+
+			documentType, err := document.Type()
+			if err != nil {
+				return err
+			}
+
+			exists, err := s.repository.FindByDocument(ctx, document)
+			if err != nil {
+				return err
+			}
+
+			if exists != nil {
+				return ErrAccountAlreadyExists
+			}
+
+			account.Document = document
+			account.DocumentType = documentType
+		}
+
+		if patch.ClosingDay != nil || patch.DueDay != nil {
+			if patch.ClosingDay != nil {
+				account.ClosingDay = *patch.ClosingDay
+			}
+
+			if patch.DueDay != nil {
+				account.DueDay = *patch.DueDay
+			}
+
+			if !account.validBillingDays() {
+				return ErrInvalidBillingDay
+			}
+		}
+
+		if patch.Status != nil && *patch.Status != account.Status {
+			if err = s.changeStatus(ctx, account, *patch.Status, patch.Reason); err != nil {
+				return err
+			}
+		}
+
+		return s.repository.Update(ctx, account)
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
+	return account, nil
+}
+
+// ChangeCreditLimit sets the account's credit limit and moves the available limit by the same amount, so what is
+// already in use stays in use. The change is recorded in the account's credit limit history.
+func (s *Service) ChangeCreditLimit(
+	ctx context.Context,
+	id int,
+	limit decimal.Decimal,
+	reason string,
+	actor string,
+) (_ *Account, err error) {
+	ctx, span := tracer.Start(ctx, "account.Service.ChangeCreditLimit")
+	defer func() { tracing.End(span, err) }()
+
+	var account *Account
+
+	if limit.IsNegative() {
+		return nil, ErrInvalidCreditLimit
+	}
+
+	reason, actor = strings.TrimSpace(reason), strings.TrimSpace(actor)
+	if reason == "" || actor == "" {
+		return nil, ErrChangeReasonRequired
+	}
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+
+		if account, err = s.repository.FindByIdForUpdate(ctx, id); err != nil {
+			return err
+		}
+
+		if account == nil {
+			return ErrAccountNotFound
+		}
+
+		if account.IsClosed() {
+			return ErrAccountClosed
+		}
+
+		change := &CreditLimitChange{
+			AccountID:                    account.ID,
+			PreviousCreditLimit:          account.CreditLimit,
+			CreditLimit:                  limit,
+			PreviousAvailableCreditLimit: account.AvailableCreditLimit,
+			AvailableCreditLimit:         account.AvailableCreditLimit.Add(limit.Sub(account.CreditLimit)),
+			Reason:                       reason,
+			Actor:                        actor,
+		}
+
+		if change.AvailableCreditLimit.IsNegative() {
+			return ErrCreditLimitBelowUsage
+		}
+
+		account.CreditLimit = change.CreditLimit
+		account.AvailableCreditLimit = change.AvailableCreditLimit
+
+		if err = s.repository.UpdateLimits(ctx, account); err != nil {
+			return err
+		}
+
+		if err = s.repository.CreateCreditLimitChange(ctx, change); err != nil {
+			return err
+		}
+
+		return s.outboxService.Record(ctx, outbox.EventCreditLimitChanged, outbox.AggregateAccount, account.ID, newCreditLimitChangedV1(change))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// CreditLimitHistory lists the account's credit limit changes, newest first.
+func (s *Service) CreditLimitHistory(ctx context.Context, id int) (_ []CreditLimitChange, err error) {
+	ctx, span := tracer.Start(ctx, "account.Service.CreditLimitHistory")
+	defer func() { tracing.End(span, err) }()
+
+	account, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if account == nil {
+		return nil, ErrAccountNotFound
+	}
+
+	return s.repository.FindCreditLimitChanges(ctx, id)
+}
+
+func (s *Service) changeStatus(ctx context.Context, account *Account, status string, reason string) error {
+	if status != StatusActive && status != StatusBlocked && status != StatusClosed {
+		return ErrInvalidStatus
+	}
+
+	if !slices.Contains(transitions[account.Status], status) {
+		return ErrInvalidStatusTransition
+	}
+
+	reason = strings.TrimSpace(reason)
+
+	if status != StatusActive && reason == "" {
+		return ErrStatusReasonRequired
+	}
+
+	now := s.clock.Now()
+
+	if status == StatusClosed {
+		balance, err := s.repository.Balance(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		if !balance.IsZero() {
+			return ErrAccountBalanceNotZero
+		}
+
+		account.DeletedAt = &now
+	}
+
+	account.Status = status
+	account.StatusReason = reason
+	account.StatusChangedAt = &now
+
 	return nil
 }
@@ -4,7 +4,13 @@ import (
 	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/supwr/pismo-transactions/internal/outbox"
+	"github.com/supwr/pismo-transactions/internal/testutil"
+	"github.com/supwr/pismo-transactions/pkg/clock"
+	clockmock "github.com/supwr/pismo-transactions/pkg/clock/mock"
+	dbmock "github.com/supwr/pismo-transactions/pkg/database/mock"
 	"testing"
 	"time"
 )
@@ -21,9 +27,9 @@ func TestService_FindById(t *testing.T) {
 			CreatedAt: time.Now(),
 		}
 
-		repo.EXPECT().FindById(ctx, account.ID).Return(account, nil).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), account.ID).Return(account, nil).Times(1)
 
-		service := NewService(repo)
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
 		a, err := service.FindById(ctx, account.ID)
 		assert.Equal(t, account, a)
 		assert.Nil(t, err)
@@ -34,9 +40,9 @@ func TestService_FindById(t *testing.T) {
 		repo := NewMockRepositoryInterface(ctrl)
 		ctx := context.Background()
 
-		repo.EXPECT().FindById(ctx, 1).Return(nil, nil).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), 1).Return(nil, nil).Times(1)
 
-		service := NewService(repo)
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
 		a, err := service.FindById(ctx, 1)
 		assert.Nil(t, a)
 		assert.Nil(t, err)
@@ -48,15 +54,50 @@ func TestService_FindById(t *testing.T) {
 		expectedErr := errors.New("database error")
 		ctx := context.Background()
 
-		repo.EXPECT().FindById(ctx, 1).Return(nil, expectedErr).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), 1).Return(nil, expectedErr).Times(1)
 
-		service := NewService(repo)
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
 		a, err := service.FindById(ctx, 1)
 		assert.Nil(t, a)
 		assert.ErrorIs(t, err, expectedErr)
 	})
 }
 
+func TestService_FindByIdForUpdate(t *testing.T) {
+	t.Run("find by id for update successfully", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		account := &Account{
+			ID:        1,
+			Document:  "123456",
+			CreatedAt: time.Now(),
+		}
+
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), account.ID).Return(account, nil).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
+		a, err := service.FindByIdForUpdate(ctx, account.ID)
+		assert.Equal(t, account, a)
+		assert.Nil(t, err)
+	})
+
+	t.Run("error finding account by id for update", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		expectedErr := errors.New("database error")
+		ctx := context.Background()
+
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(nil, expectedErr).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
+		a, err := service.FindByIdForUpdate(ctx, 1)
+		assert.Nil(t, a)
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
 func TestService_FindByDocument(t *testing.T) {
 	t.Run("find by document successfully", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -69,9 +110,9 @@ func TestService_FindByDocument(t *testing.T) {
 			CreatedAt: time.Now(),
 		}
 
-		repo.EXPECT().FindByDocument(ctx, account.Document).Return(account, nil).Times(1)
+		repo.EXPECT().FindByDocument(gomock.Any(), account.Document).Return(account, nil).Times(1)
 
-		service := NewService(repo)
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
 		a, err := service.FindByDocument(ctx, account.Document)
 		assert.Equal(t, account, a)
 		assert.Nil(t, err)
@@ -83,9 +124,9 @@ func TestService_FindByDocument(t *testing.T) {
 		document := Document("123456")
 		ctx := context.Background()
 
-		repo.EXPECT().FindByDocument(ctx, document).Return(nil, nil).Times(1)
+		repo.EXPECT().FindByDocument(gomock.Any(), document).Return(nil, nil).Times(1)
 
-		service := NewService(repo)
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
 		a, err := service.FindByDocument(ctx, document)
 		assert.Nil(t, a)
 		assert.Nil(t, err)
@@ -98,9 +139,9 @@ func TestService_FindByDocument(t *testing.T) {
 		expectedErr := errors.New("database error")
 		ctx := context.Background()
 
-		repo.EXPECT().FindByDocument(ctx, document).Return(nil, expectedErr).Times(1)
+		repo.EXPECT().FindByDocument(gomock.Any(), document).Return(nil, expectedErr).Times(1)
 
-		service := NewService(repo)
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
 		a, err := service.FindByDocument(ctx, document)
 		assert.Nil(t, a)
 		assert.ErrorIs(t, err, expectedErr)
@@ -115,14 +156,28 @@ func TestService_Create(t *testing.T) {
 
 		account := &Account{
 			ID:        1,
-			Document:  "123456",
+			Document:  "52998224725",
 			CreatedAt: time.Now(),
 		}
 
-		findByDocument := repo.EXPECT().FindByDocument(ctx, account.Document).Return(nil, nil).Times(1)
-		repo.EXPECT().Create(ctx, account).Return(nil).Times(1).After(findByDocument)
-
-		service := NewService(repo)
+		outboxRepo := outbox.NewMockRepositoryInterface(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		findByDocument := repo.EXPECT().FindByDocument(gomock.Any(), account.Document).Return(nil, nil).Times(1)
+		create := repo.EXPECT().Create(gomock.Any(), account).Return(nil).Times(1).After(findByDocument)
+		outboxRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, e *outbox.Event) error {
+			assert.Equal(t, outbox.EventAccountCreated, e.Type)
+			assert.Equal(t, outbox.AggregateAccount, e.AggregateType)
+			assert.Equal(t, 1, e.AggregateID)
+			assert.Equal(t, 1, e.Version)
+			assert.Contains(t, string(e.Payload), `"document":"52998224725"`)
+			assert.NotContains(t, string(e.Payload), "deleted_at")
+			return nil
+		}).After(create).Times(1)
+
+		outboxService := outbox.NewService(outboxRepo, clock.NewClock())
+		service := NewService(repo, outboxService, clockmock.NewMockClock(ctrl), txManager)
 		err := service.Create(ctx, account)
 		assert.Nil(t, err)
 	})
@@ -135,17 +190,113 @@ func TestService_Create(t *testing.T) {
 
 		account := &Account{
 			ID:        1,
-			Document:  "123456",
+			Document:  "52998224725",
 			CreatedAt: time.Now(),
 		}
 
-		repo.EXPECT().FindByDocument(ctx, account.Document).Return(nil, expectedErr).Times(1)
+		repo.EXPECT().FindByDocument(gomock.Any(), account.Document).Return(nil, expectedErr).Times(1)
 
-		service := NewService(repo)
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
 		err := service.Create(ctx, account)
 		assert.ErrorIs(t, err, expectedErr)
 	})
 
+	t.Run("available limit starts at the credit limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		account := &Account{Document: "52998224725", CreditLimit: decimal.NewFromInt(1000)}
+
+		repo.EXPECT().FindByDocument(gomock.Any(), account.Document).Return(nil, nil).Times(1)
+		repo.EXPECT().Create(gomock.Any(), account).Return(nil).Times(1)
+
+		txManager := dbmock.NewMockTxManager(ctrl)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), txManager)
+		err := service.Create(ctx, account)
+
+		assert.Nil(t, err)
+		assert.Equal(t, StatusActive, account.Status)
+		assert.True(t, account.AvailableCreditLimit.Equal(decimal.NewFromInt(1000)))
+		assert.Equal(t, DefaultClosingDay, account.ClosingDay)
+		assert.Equal(t, DefaultDueDay, account.DueDay)
+	})
+
+	t.Run("invalid billing days error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
+		err := service.Create(ctx, &Account{Document: "52998224725", ClosingDay: 31, DueDay: 10})
+
+		assert.ErrorIs(t, err, ErrInvalidBillingDay)
+	})
+
+	t.Run("negative credit limit error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
+		err := service.Create(ctx, &Account{Document: "52998224725", CreditLimit: decimal.NewFromInt(-1)})
+
+		assert.ErrorIs(t, err, ErrInvalidCreditLimit)
+	})
+
+	t.Run("formatted document is normalized", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		account := &Account{Document: "11.222.333/0001-81"}
+
+		repo.EXPECT().FindByDocument(gomock.Any(), Document("11222333000181")).Return(nil, nil).Times(1)
+		repo.EXPECT().Create(gomock.Any(), account).Return(nil).Times(1)
+
+		txManager := dbmock.NewMockTxManager(ctrl)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), txManager)
+		err := service.Create(ctx, account)
+
+		assert.Nil(t, err)
+		assert.Equal(t, Document("11222333000181"), account.Document)
+		assert.Equal(t, DocumentTypeCNPJ, account.DocumentType)
+	})
+
+	t.Run("invalid document error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
+		err := service.Create(ctx, &Account{Document: "123.456.789-00"})
+
+		assert.ErrorIs(t, err, ErrInvalidDocument)
+	})
+
+	t.Run("account created concurrently with the same document", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		account := &Account{Document: "52998224725"}
+
+		findByDocument := repo.EXPECT().FindByDocument(gomock.Any(), account.Document).Return(nil, nil).Times(1)
+		repo.EXPECT().Create(gomock.Any(), account).Return(ErrAccountAlreadyExists).After(findByDocument).Times(1)
+
+		txManager := dbmock.NewMockTxManager(ctrl)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), txManager)
+		err := service.Create(ctx, account)
+
+		assert.ErrorIs(t, err, ErrAccountAlreadyExists)
+	})
+
 	t.Run("account already exists error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		repo := NewMockRepositoryInterface(ctrl)
@@ -153,13 +304,13 @@ func TestService_Create(t *testing.T) {
 
 		account := &Account{
 			ID:        1,
-			Document:  "123456",
+			Document:  "52998224725",
 			CreatedAt: time.Now(),
 		}
 
-		repo.EXPECT().FindByDocument(ctx, account.Document).Return(account, nil).Times(1)
+		repo.EXPECT().FindByDocument(gomock.Any(), account.Document).Return(account, nil).Times(1)
 
-		service := NewService(repo)
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl))
 		err := service.Create(ctx, account)
 		assert.ErrorIs(t, err, ErrAccountAlreadyExists)
 	})
@@ -172,15 +323,375 @@ func TestService_Create(t *testing.T) {
 
 		account := &Account{
 			ID:        1,
-			Document:  "123456",
+			Document:  "52998224725",
 			CreatedAt: time.Now(),
 		}
 
-		findByDocument := repo.EXPECT().FindByDocument(ctx, account.Document).Return(nil, nil).Times(1)
-		repo.EXPECT().Create(ctx, account).Return(expectedErr).Times(1).After(findByDocument)
+		findByDocument := repo.EXPECT().FindByDocument(gomock.Any(), account.Document).Return(nil, nil).Times(1)
+		repo.EXPECT().Create(gomock.Any(), account).Return(expectedErr).Times(1).After(findByDocument)
+
+		txManager := dbmock.NewMockTxManager(ctrl)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
 
-		service := NewService(repo)
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockmock.NewMockClock(ctrl), txManager)
 		err := service.Create(ctx, account)
 		assert.ErrorIs(t, err, expectedErr)
 	})
 }
+
+func TestService_Update(t *testing.T) {
+	statusBlocked := StatusBlocked
+	statusActive := StatusActive
+	statusClosed := StatusClosed
+
+	t.Run("block account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		now := time.Now()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&Account{ID: 1, Document: "123456", Status: StatusActive}, nil).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().Update(gomock.Any(), &Account{
+			ID:              1,
+			Document:        "123456",
+			Status:          StatusBlocked,
+			StatusReason:    "fraud suspicion",
+			StatusChangedAt: &now,
+		}).Return(nil).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		a, err := service.Update(ctx, 1, Patch{Status: &statusBlocked, Reason: "fraud suspicion"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, StatusBlocked, a.Status)
+	})
+
+	t.Run("unblock account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		now := time.Now()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&Account{ID: 1, Status: StatusBlocked, StatusReason: "fraud suspicion"}, nil).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().Update(gomock.Any(), &Account{ID: 1, Status: StatusActive, StatusChangedAt: &now}).Return(nil).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		_, err := service.Update(ctx, 1, Patch{Status: &statusActive})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("close account with zero balance", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		now := time.Now()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&Account{ID: 1, Status: StatusActive}, nil).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().Balance(gomock.Any(), 1).Return(decimal.Zero, nil).Times(1)
+		repo.EXPECT().Update(gomock.Any(), &Account{
+			ID:              1,
+			Status:          StatusClosed,
+			StatusReason:    "customer request",
+			StatusChangedAt: &now,
+			DeletedAt:       &now,
+		}).Return(nil).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		a, err := service.Update(ctx, 1, Patch{Status: &statusClosed, Reason: "customer request"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, &now, a.DeletedAt)
+	})
+
+	t.Run("account with balance can't be closed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&Account{ID: 1, Status: StatusActive}, nil).Times(1)
+		clockMock.EXPECT().Now().Return(time.Now()).Times(1)
+		repo.EXPECT().Balance(gomock.Any(), 1).Return(decimal.NewFromInt(-10), nil).Times(1)
+		repo.EXPECT().Update(gomock.Any(), gomock.Any()).Times(0)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		a, err := service.Update(ctx, 1, Patch{Status: &statusClosed, Reason: "customer request"})
+
+		assert.Nil(t, a)
+		assert.ErrorIs(t, err, ErrAccountBalanceNotZero)
+	})
+
+	t.Run("closed account can't be changed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&Account{ID: 1, Status: StatusClosed}, nil).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		a, err := service.Update(ctx, 1, Patch{Status: &statusActive})
+
+		assert.Nil(t, a)
+		assert.ErrorIs(t, err, ErrAccountClosed)
+	})
+
+	t.Run("status errors", func(t *testing.T) {
+		invalid := "DELETED"
+
+		cases := map[string]struct {
+			patch       Patch
+			expectedErr error
+		}{
+			"unknown status": {Patch{Status: &invalid, Reason: "reason"}, ErrInvalidStatus},
+			"missing reason": {Patch{Status: &statusBlocked}, ErrStatusReasonRequired},
+			"blank reason":   {Patch{Status: &statusBlocked, Reason: "  "}, ErrStatusReasonRequired},
+		}
+
+		for name, c := range cases {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				repo := NewMockRepositoryInterface(ctrl)
+				clockMock := clockmock.NewMockClock(ctrl)
+				txManager := dbmock.NewMockTxManager(ctrl)
+				ctx := context.Background()
+
+				txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+				repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&Account{ID: 1, Status: StatusActive}, nil).Times(1)
+
+				service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+				_, err := service.Update(ctx, 1, c.patch)
+
+				assert.ErrorIs(t, err, c.expectedErr)
+			})
+		}
+	})
+
+	t.Run("change document to one already in use", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		document := Document("11222333000181")
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&Account{ID: 1, Document: "123456", Status: StatusActive}, nil).Times(1)
+		repo.EXPECT().FindByDocument(gomock.Any(), document).Return(&Account{ID: 2, Document: document}, nil).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		_, err := service.Update(ctx, 1, Patch{Document: &document})
+
+		assert.ErrorIs(t, err, ErrAccountAlreadyExists)
+	})
+
+	t.Run("change billing days", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		closingDay := 20
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&Account{ID: 1, Status: StatusActive, ClosingDay: 3, DueDay: 10}, nil).Times(1)
+		repo.EXPECT().Update(gomock.Any(), &Account{ID: 1, Status: StatusActive, ClosingDay: 20, DueDay: 10}).Return(nil).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		_, err := service.Update(ctx, 1, Patch{ClosingDay: &closingDay})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("closing and due days can't be the same", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		dueDay := 3
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&Account{ID: 1, Status: StatusActive, ClosingDay: 3, DueDay: 10}, nil).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		_, err := service.Update(ctx, 1, Patch{DueDay: &dueDay})
+
+		assert.ErrorIs(t, err, ErrInvalidBillingDay)
+	})
+
+	t.Run("account not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(nil, nil).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		_, err := service.Update(ctx, 1, Patch{Status: &statusBlocked, Reason: "reason"})
+
+		assert.ErrorIs(t, err, ErrAccountNotFound)
+	})
+}
+
+func TestService_ChangeCreditLimit(t *testing.T) {
+	t.Run("raise credit limit keeps what is in use", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		outboxRepo := outbox.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		findAccount := repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&Account{
+			ID:                   1,
+			Status:               StatusActive,
+			CreditLimit:          decimal.NewFromInt(1000),
+			AvailableCreditLimit: decimal.NewFromInt(400),
+		}, nil).Times(1)
+		updateLimits := repo.EXPECT().UpdateLimits(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, a *Account) error {
+			assert.True(t, a.CreditLimit.Equal(decimal.NewFromInt(1500)))
+			assert.True(t, a.AvailableCreditLimit.Equal(decimal.NewFromInt(900)))
+			return nil
+		}).After(findAccount).Times(1)
+		createChange := repo.EXPECT().CreateCreditLimitChange(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, c *CreditLimitChange) error {
+			assert.Equal(t, 1, c.AccountID)
+			assert.True(t, c.PreviousCreditLimit.Equal(decimal.NewFromInt(1000)))
+			assert.True(t, c.CreditLimit.Equal(decimal.NewFromInt(1500)))
+			assert.True(t, c.PreviousAvailableCreditLimit.Equal(decimal.NewFromInt(400)))
+			assert.True(t, c.AvailableCreditLimit.Equal(decimal.NewFromInt(900)))
+			assert.Equal(t, "income review", c.Reason)
+			assert.Equal(t, "analyst@pismo", c.Actor)
+			return nil
+		}).After(updateLimits).Times(1)
+		outboxRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, e *outbox.Event) error {
+			assert.Equal(t, outbox.EventCreditLimitChanged, e.Type)
+			assert.Equal(t, 1, e.AggregateID)
+			assert.Contains(t, string(e.Payload), `"actor":"analyst@pismo"`)
+			return nil
+		}).After(createChange).Times(1)
+
+		service := NewService(repo, outbox.NewService(outboxRepo, clock.NewClock()), clockMock, txManager)
+		a, err := service.ChangeCreditLimit(ctx, 1, decimal.NewFromInt(1500), "income review", "analyst@pismo")
+
+		assert.Nil(t, err)
+		assert.True(t, a.CreditLimit.Equal(decimal.NewFromInt(1500)))
+	})
+
+	t.Run("credit limit can't be lowered below what is in use", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&Account{
+			ID:                   1,
+			Status:               StatusActive,
+			CreditLimit:          decimal.NewFromInt(1000),
+			AvailableCreditLimit: decimal.NewFromInt(400),
+		}, nil).Times(1)
+		repo.EXPECT().UpdateLimits(gomock.Any(), gomock.Any()).Times(0)
+		repo.EXPECT().CreateCreditLimitChange(gomock.Any(), gomock.Any()).Times(0)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		a, err := service.ChangeCreditLimit(ctx, 1, decimal.NewFromInt(500), "risk review", "analyst@pismo")
+
+		assert.Nil(t, a)
+		assert.ErrorIs(t, err, ErrCreditLimitBelowUsage)
+	})
+
+	t.Run("invalid changes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+
+		_, err := service.ChangeCreditLimit(ctx, 1, decimal.NewFromInt(-1), "reason", "actor")
+		assert.ErrorIs(t, err, ErrInvalidCreditLimit)
+
+		_, err = service.ChangeCreditLimit(ctx, 1, decimal.NewFromInt(100), " ", "actor")
+		assert.ErrorIs(t, err, ErrChangeReasonRequired)
+
+		_, err = service.ChangeCreditLimit(ctx, 1, decimal.NewFromInt(100), "reason", "")
+		assert.ErrorIs(t, err, ErrChangeReasonRequired)
+	})
+
+	t.Run("closed account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&Account{ID: 1, Status: StatusClosed}, nil).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		_, err := service.ChangeCreditLimit(ctx, 1, decimal.NewFromInt(100), "reason", "actor")
+
+		assert.ErrorIs(t, err, ErrAccountClosed)
+	})
+}
+
+func TestService_CreditLimitHistory(t *testing.T) {
+	t.Run("list history successfully", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		changes := []CreditLimitChange{{ID: 2, AccountID: 1}, {ID: 1, AccountID: 1}}
+
+		repo.EXPECT().FindById(gomock.Any(), 1).Return(&Account{ID: 1}, nil).Times(1)
+		repo.EXPECT().FindCreditLimitChanges(gomock.Any(), 1).Return(changes, nil).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		c, err := service.CreditLimitHistory(ctx, 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, changes, c)
+	})
+
+	t.Run("account not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindById(gomock.Any(), 1).Return(nil, nil).Times(1)
+
+		service := NewService(repo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		c, err := service.CreditLimitHistory(ctx, 1)
+
+		assert.Nil(t, c)
+		assert.ErrorIs(t, err, ErrAccountNotFound)
+	})
+}
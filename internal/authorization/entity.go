@@ -0,0 +1,42 @@
+package authorization
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	StatusAuthorized = "AUTHORIZED"
+	StatusCaptured   = "CAPTURED"
+	StatusVoided     = "VOIDED"
+	StatusExpired    = "EXPIRED"
+)
+
+// HoldDuration is how long an authorization keeps the limit reserved before it expires if it isn't captured.
+const HoldDuration = 7 * 24 * time.Hour
+
+// Authorization is a hold on the account's available limit for a purchase that will be settled later. Reserved is
+// how much limit the hold took, so releasing it gives back exactly that, even if the operation type changed since.
+type Authorization struct {
+	ID              int             `json:"id" gorm:"primaryKey"`
+	AccountID       int             `json:"account_id"`
+	OperationTypeID int             `json:"operation_type_id"`
+	Amount          decimal.Decimal `json:"amount"`
+	Reserved        decimal.Decimal `json:"reserved"`
+	CapturedAmount  decimal.Decimal `json:"captured_amount"`
+	Installments    int             `json:"installments"`
+	InterestRate    decimal.Decimal `json:"interest_rate"`
+	Status          string          `json:"status"`
+	TransactionID   *int            `json:"transaction_id"`
+	ExpiresAt       time.Time       `json:"expires_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       *time.Time      `json:"updated_at"`
+}
+
+func (a *Authorization) IsPending() bool {
+	return a.Status == StatusAuthorized
+}
+
+func (a *Authorization) IsExpired(now time.Time) bool {
+	return !now.Before(a.ExpiresAt)
+}
@@ -0,0 +1,12 @@
+package authorization
+
+import "errors"
+
+var (
+	ErrAuthorizationNotFound   = errors.New("Authorization not found")
+	ErrAuthorizationNotPending = errors.New("Authorization was already captured, voided or expired")
+	ErrAuthorizationExpired    = errors.New("Authorization expired")
+	ErrInvalidAmount           = errors.New("Amount must be positive")
+	ErrCaptureExceedsAmount    = errors.New("Capture amount exceeds the authorized amount")
+	ErrOperationTypeNotAllowed = errors.New("Only purchases and withdraws can be authorized")
+)
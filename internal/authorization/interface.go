@@ -0,0 +1,15 @@
+//go:generate mockgen -destination=mock.go -source=interface.go -package=authorization
+package authorization
+
+import (
+	"context"
+	"time"
+)
+
+type RepositoryInterface interface {
+	Create(ctx context.Context, authorization *Authorization) error
+	FindById(ctx context.Context, id int) (*Authorization, error)
+	FindByIdForUpdate(ctx context.Context, id int) (*Authorization, error)
+	Update(ctx context.Context, authorization *Authorization) error
+	FindExpiredIDs(ctx context.Context, now time.Time) ([]int, error)
+}
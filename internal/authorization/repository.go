@@ -0,0 +1,88 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+	"time"
+)
+
+type Repository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewRepository(db *gorm.DB, logger *slog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *Repository) Create(ctx context.Context, authorization *Authorization) error {
+	return database.Conn(ctx, r.db).Create(authorization).Error
+}
+
+func (r *Repository) FindById(ctx context.Context, id int) (*Authorization, error) {
+	var authorization *Authorization
+
+	if err := database.Conn(ctx, r.db).First(&authorization, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		r.logger.ErrorContext(ctx, "error finding authorization", slog.Any("error", err))
+		return nil, err
+	}
+
+	return authorization, nil
+}
+
+// FindByIdForUpdate locks the authorization until the surrounding transaction ends, so it can't be captured,
+// voided or expired twice.
+func (r *Repository) FindByIdForUpdate(ctx context.Context, id int) (*Authorization, error) {
+	var authorization *Authorization
+
+	err := database.Conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&authorization, "id = ?", id).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		r.logger.ErrorContext(ctx, "error finding authorization for update", slog.Any("error", err))
+		return nil, err
+	}
+
+	return authorization, nil
+}
+
+func (r *Repository) Update(ctx context.Context, authorization *Authorization) error {
+	return database.Conn(ctx, r.db).
+		Model(authorization).
+		Select("reserved", "captured_amount", "status", "transaction_id").
+		Updates(authorization).Error
+}
+
+// FindExpiredIDs lists the pending authorizations whose hold ran out by now.
+func (r *Repository) FindExpiredIDs(ctx context.Context, now time.Time) ([]int, error) {
+	var ids []int
+
+	err := database.Conn(ctx, r.db).
+		Model(&Authorization{}).
+		Where("status = ? and expires_at <= ?", StatusAuthorized, now).
+		Order("expires_at, id").
+		Pluck("id", &ids).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding expired authorizations", slog.Any("error", err))
+		return nil, err
+	}
+
+	return ids, nil
+}
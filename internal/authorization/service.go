@@ -0,0 +1,300 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"github.com/supwr/pismo-transactions/internal/transaction"
+	"github.com/supwr/pismo-transactions/pkg/clock"
+	"github.com/supwr/pismo-transactions/pkg/database"
+)
+
+type Service struct {
+	repository           RepositoryInterface
+	accountService       *account.Service
+	operationTypeService *operationtype.Service
+	transactionService   *transaction.Service
+	clock                clock.Clock
+	txManager            database.TxManager
+}
+
+func NewService(
+	r RepositoryInterface,
+	a *account.Service,
+	o *operationtype.Service,
+	t *transaction.Service,
+	c clock.Clock,
+	tm database.TxManager,
+) *Service {
+	return &Service{
+		repository:           r,
+		accountService:       a,
+		operationTypeService: o,
+		transactionService:   t,
+		clock:                c,
+		txManager:            tm,
+	}
+}
+
+func (s *Service) FindById(ctx context.Context, id int) (*Authorization, error) {
+	return s.repository.FindById(ctx, id)
+}
+
+// Authorize holds the authorization's amount on the account's available limit until it's captured, voided or
+// expires. It runs the same checks a purchase does, so a capture within the hold can't be refused for lack of funds.
+func (s *Service) Authorize(ctx context.Context, a *Authorization) error {
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		acc, err := s.accountService.FindByIdForUpdate(ctx, a.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if acc == nil {
+			return transaction.ErrAccountNotFound
+		}
+
+		if acc.IsClosed() {
+			return transaction.ErrAccountClosed
+		}
+
+		if acc.IsBlocked() {
+			return transaction.ErrAccountBlocked
+		}
+
+		operationType, err := s.operationTypeService.FindById(ctx, a.OperationTypeID)
+		if err != nil {
+			return err
+		}
+
+		if operationType == nil || !operationType.Active {
+			return transaction.ErrOperationTypeNotFound
+		}
+
+		if operationType.Internal || !operationType.IsDebit() {
+			return ErrOperationTypeNotAllowed
+		}
+
+		if a.OperationTypeID == transaction.OperationTypeInstallmentBuy {
+			a.Installments = max(a.Installments, 1)
+
+			if err = installment.Validate(a.Installments, a.InterestRate); err != nil {
+				return err
+			}
+		} else if a.Installments > 1 || !a.InterestRate.IsZero() {
+			return transaction.ErrInstallmentsNotAllowed
+		}
+
+		if !a.Amount.IsPositive() {
+			return ErrInvalidAmount
+		}
+
+		a.Reserved = decimal.Zero
+
+		if operationType.ConsumesCreditLimit {
+			if acc.AvailableCreditLimit.LessThan(a.Amount) {
+				return transaction.ErrInsuficientFunds
+			}
+
+			a.Reserved = a.Amount
+			acc.AvailableCreditLimit = acc.AvailableCreditLimit.Sub(a.Amount)
+
+			if err = s.accountService.UpdateCreditLimit(ctx, acc); err != nil {
+				return err
+			}
+		}
+
+		a.Status = StatusAuthorized
+		a.CapturedAmount = decimal.Zero
+		a.ExpiresAt = s.clock.Now().Add(HoldDuration)
+
+		return s.repository.Create(ctx, a)
+	})
+}
+
+// Capture settles the authorization as a transaction of amount, or of the whole authorized amount when amount is
+// nil. The hold is released in full, so whatever isn't captured goes back to the available limit.
+func (s *Service) Capture(ctx context.Context, id int, amount *decimal.Decimal) (*transaction.Transaction, error) {
+	var t *transaction.Transaction
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		acc, a, err := s.lock(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !a.IsPending() {
+			return ErrAuthorizationNotPending
+		}
+
+		if a.IsExpired(s.clock.Now()) {
+			return ErrAuthorizationExpired
+		}
+
+		value := a.Amount
+
+		if amount != nil {
+			value = *amount
+		}
+
+		if !value.IsPositive() {
+			return ErrInvalidAmount
+		}
+
+		if value.GreaterThan(a.Amount) {
+			return ErrCaptureExceedsAmount
+		}
+
+		if err = s.release(ctx, acc, a); err != nil {
+			return err
+		}
+
+		t = &transaction.Transaction{
+			AccountID:       a.AccountID,
+			OperationTypeID: a.OperationTypeID,
+			Amount:          value,
+			Installments:    a.Installments,
+			InterestRate:    a.InterestRate,
+		}
+
+		if err = s.transactionService.Create(ctx, t); err != nil {
+			return err
+		}
+
+		a.Status = StatusCaptured
+		a.CapturedAmount = value
+		a.TransactionID = &t.ID
+
+		return s.repository.Update(ctx, a)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Void cancels the authorization and gives its hold back to the available limit.
+func (s *Service) Void(ctx context.Context, id int) (*Authorization, error) {
+	var a *Authorization
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		acc, locked, err := s.lock(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		a = locked
+
+		if !a.IsPending() {
+			return ErrAuthorizationNotPending
+		}
+
+		if err = s.release(ctx, acc, a); err != nil {
+			return err
+		}
+
+		a.Status = StatusVoided
+
+		return s.repository.Update(ctx, a)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// ExpireStale releases the holds of the authorizations that weren't captured or voided in time. Each one is expired
+// in its own transaction, so a failure doesn't keep the others holding the limit.
+func (s *Service) ExpireStale(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+
+	ids, err := s.repository.FindExpiredIDs(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		expired int
+		errs    []error
+	)
+
+	for _, id := range ids {
+		err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			acc, a, err := s.lock(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			// captured or voided since it was listed
+			if !a.IsPending() || !a.IsExpired(now) {
+				return nil
+			}
+
+			if err = s.release(ctx, acc, a); err != nil {
+				return err
+			}
+
+			a.Status = StatusExpired
+
+			if err = s.repository.Update(ctx, a); err != nil {
+				return err
+			}
+
+			expired++
+
+			return nil
+		})
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("authorization %d: %w", id, err))
+		}
+	}
+
+	return expired, errors.Join(errs...)
+}
+
+// lock locks the authorization's account and then the authorization itself, in the same order transactions lock
+// them, to avoid deadlocks.
+func (s *Service) lock(ctx context.Context, id int) (*account.Account, *Authorization, error) {
+	a, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if a == nil {
+		return nil, nil, ErrAuthorizationNotFound
+	}
+
+	acc, err := s.accountService.FindByIdForUpdate(ctx, a.AccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if acc == nil {
+		return nil, nil, transaction.ErrAccountNotFound
+	}
+
+	if a, err = s.repository.FindByIdForUpdate(ctx, id); err != nil {
+		return nil, nil, err
+	}
+
+	return acc, a, nil
+}
+
+func (s *Service) release(ctx context.Context, acc *account.Account, a *Authorization) error {
+	if !a.Reserved.IsPositive() {
+		return nil
+	}
+
+	acc.AvailableCreditLimit = acc.AvailableCreditLimit.Add(a.Reserved)
+	a.Reserved = decimal.Zero
+
+	return s.accountService.UpdateCreditLimit(ctx, acc)
+}
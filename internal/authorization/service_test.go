@@ -0,0 +1,468 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"github.com/supwr/pismo-transactions/internal/ledger"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"github.com/supwr/pismo-transactions/internal/testutil"
+	"github.com/supwr/pismo-transactions/internal/transaction"
+	clockmock "github.com/supwr/pismo-transactions/pkg/clock/mock"
+	dbmock "github.com/supwr/pismo-transactions/pkg/database/mock"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+
+func newAccount() *account.Account {
+	return &account.Account{
+		ID:                   1,
+		Status:               account.StatusActive,
+		CreditLimit:          decimal.NewFromInt(1000),
+		AvailableCreditLimit: decimal.NewFromInt(1000),
+	}
+}
+
+func pending() *Authorization {
+	return &Authorization{
+		ID:              3,
+		AccountID:       1,
+		OperationTypeID: transaction.OperationTypeCashBuy,
+		Amount:          decimal.NewFromInt(300),
+		Reserved:        decimal.NewFromInt(300),
+		Installments:    1,
+		Status:          StatusAuthorized,
+		ExpiresAt:       now.Add(time.Hour),
+	}
+}
+
+func TestService_Authorize(t *testing.T) {
+	t.Run("hold the amount on the available limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		var limits []string
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(newAccount(), nil).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *account.Account) error {
+			limits = append(limits, a.AvailableCreditLimit.String())
+			return nil
+		}).Times(1)
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+		a := &Authorization{AccountID: 1, OperationTypeID: transaction.OperationTypeCashBuy, Amount: decimal.NewFromInt(300)}
+		err := service.Authorize(ctx, a)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"700"}, limits)
+		assert.Equal(t, StatusAuthorized, a.Status)
+		assert.Equal(t, "300", a.Reserved.String())
+		assert.Equal(t, now.Add(HoldDuration), a.ExpiresAt)
+	})
+
+	t.Run("reject authorization above the available limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(newAccount(), nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+		a := &Authorization{AccountID: 1, OperationTypeID: transaction.OperationTypeCashBuy, Amount: decimal.NewFromInt(1001)}
+		err := service.Authorize(ctx, a)
+
+		assert.ErrorIs(t, err, transaction.ErrInsuficientFunds)
+	})
+
+	t.Run("reject authorization on a blocked account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		acc := newAccount()
+		acc.Status = account.StatusBlocked
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+		a := &Authorization{AccountID: 1, OperationTypeID: transaction.OperationTypeCashBuy, Amount: decimal.NewFromInt(10)}
+		err := service.Authorize(ctx, a)
+
+		assert.ErrorIs(t, err, transaction.ErrAccountBlocked)
+	})
+
+	t.Run("reject authorization of payments and internal operation types", func(t *testing.T) {
+		for _, operationTypeID := range []int{transaction.OperationTypePayment, 6} {
+			ctrl := gomock.NewController(t)
+			repo := NewMockRepositoryInterface(ctrl)
+			accountRepo := account.NewMockRepositoryInterface(ctrl)
+			clockMock := clockmock.NewMockClock(ctrl)
+			txManager := dbmock.NewMockTxManager(ctrl)
+			ctx := context.Background()
+
+			txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+			accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(newAccount(), nil).Times(1)
+
+			service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+			a := &Authorization{AccountID: 1, OperationTypeID: operationTypeID, Amount: decimal.NewFromInt(10)}
+			err := service.Authorize(ctx, a)
+
+			assert.ErrorIs(t, err, ErrOperationTypeNotAllowed)
+		}
+	})
+
+	t.Run("reject non positive amount", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(newAccount(), nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+		a := &Authorization{AccountID: 1, OperationTypeID: transaction.OperationTypeCashBuy, Amount: decimal.Zero}
+		err := service.Authorize(ctx, a)
+
+		assert.ErrorIs(t, err, ErrInvalidAmount)
+	})
+}
+
+func TestService_Capture(t *testing.T) {
+	t.Run("capture part of the authorization and release the rest", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := transaction.NewMockRepositoryInterface(ctrl)
+		ledgerRepo := ledger.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		acc := newAccount()
+		acc.AvailableCreditLimit = decimal.NewFromInt(700)
+		var limits []string
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(2)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(2)
+		repo.EXPECT().FindById(gomock.Any(), 3).Return(pending(), nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(2)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 3).Return(pending(), nil).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *account.Account) error {
+			limits = append(limits, a.AvailableCreditLimit.String())
+			return nil
+		}).Times(2)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, t *transaction.Transaction) error {
+			t.ID = 9
+			return nil
+		}).Times(1)
+		ledgerRepo.EXPECT().CreateEntry(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		repo.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *Authorization) error {
+			assert.Equal(t, StatusCaptured, a.Status)
+			assert.Equal(t, "250", a.CapturedAmount.String())
+			assert.True(t, a.Reserved.IsZero())
+			assert.Equal(t, 9, *a.TransactionID)
+			return nil
+		}).Times(1)
+
+		operationTypeService := testutil.NewOperationTypeService(ctrl, operationTypes...)
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := transaction.NewService(transactionRepo, accountService, installment.NewService(installment.NewMockRepositoryInterface(ctrl)), operationTypeService, ledger.NewService(ledgerRepo), testutil.NewOutboxService(ctrl), transaction.NewMetrics(prometheus.NewRegistry()), clockMock, txManager)
+		service := NewService(repo, accountService, operationTypeService, transactionService, clockMock, txManager)
+
+		amount := decimal.NewFromInt(250)
+		tr, err := service.Capture(ctx, 3, &amount)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "-250", tr.Amount.String())
+		assert.Equal(t, []string{"1000", "750"}, limits)
+	})
+
+	t.Run("capture the whole amount when none is given", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := transaction.NewMockRepositoryInterface(ctrl)
+		ledgerRepo := ledger.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		acc := newAccount()
+		acc.AvailableCreditLimit = decimal.NewFromInt(700)
+		var limits []string
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(2)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(2)
+		repo.EXPECT().FindById(gomock.Any(), 3).Return(pending(), nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(2)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 3).Return(pending(), nil).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *account.Account) error {
+			limits = append(limits, a.AvailableCreditLimit.String())
+			return nil
+		}).Times(2)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		ledgerRepo.EXPECT().CreateEntry(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		operationTypeService := testutil.NewOperationTypeService(ctrl, operationTypes...)
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := transaction.NewService(transactionRepo, accountService, installment.NewService(installment.NewMockRepositoryInterface(ctrl)), operationTypeService, ledger.NewService(ledgerRepo), testutil.NewOutboxService(ctrl), transaction.NewMetrics(prometheus.NewRegistry()), clockMock, txManager)
+		service := NewService(repo, accountService, operationTypeService, transactionService, clockMock, txManager)
+
+		tr, err := service.Capture(ctx, 3, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "-300", tr.Amount.String())
+		assert.Equal(t, []string{"1000", "700"}, limits)
+	})
+
+	t.Run("reject capture above the authorized amount", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), 3).Return(pending(), nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(newAccount(), nil).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 3).Return(pending(), nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+		amount := decimal.NewFromInt(301)
+		_, err := service.Capture(ctx, 3, &amount)
+
+		assert.ErrorIs(t, err, ErrCaptureExceedsAmount)
+	})
+
+	t.Run("reject capture of an expired authorization", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		expired := pending()
+		expired.ExpiresAt = now
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), 3).Return(expired, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(newAccount(), nil).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 3).Return(expired, nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+		_, err := service.Capture(ctx, 3, nil)
+
+		assert.ErrorIs(t, err, ErrAuthorizationExpired)
+	})
+
+	t.Run("reject capture of a voided authorization", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		voided := pending()
+		voided.Status = StatusVoided
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), 3).Return(voided, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(newAccount(), nil).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 3).Return(voided, nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+		_, err := service.Capture(ctx, 3, nil)
+
+		assert.ErrorIs(t, err, ErrAuthorizationNotPending)
+	})
+
+	t.Run("authorization not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), 3).Return(nil, nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+		_, err := service.Capture(ctx, 3, nil)
+
+		assert.ErrorIs(t, err, ErrAuthorizationNotFound)
+	})
+}
+
+func TestService_Void(t *testing.T) {
+	t.Run("void authorization and release its hold", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		acc := newAccount()
+		acc.AvailableCreditLimit = decimal.NewFromInt(700)
+		var limits []string
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), 3).Return(pending(), nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 3).Return(pending(), nil).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *account.Account) error {
+			limits = append(limits, a.AvailableCreditLimit.String())
+			return nil
+		}).Times(1)
+		repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+		a, err := service.Void(ctx, 3)
+
+		assert.Nil(t, err)
+		assert.Equal(t, StatusVoided, a.Status)
+		assert.Equal(t, []string{"1000"}, limits)
+	})
+
+	t.Run("reject void of a captured authorization", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		captured := pending()
+		captured.Status = StatusCaptured
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), 3).Return(captured, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(newAccount(), nil).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 3).Return(captured, nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+		_, err := service.Void(ctx, 3)
+
+		assert.ErrorIs(t, err, ErrAuthorizationNotPending)
+	})
+}
+
+func TestService_ExpireStale(t *testing.T) {
+	t.Run("expire stale authorizations and skip the ones settled meanwhile", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		acc := newAccount()
+		acc.AvailableCreditLimit = decimal.NewFromInt(700)
+		stale := pending()
+		stale.ExpiresAt = now.Add(-time.Minute)
+		captured := pending()
+		captured.ID = 4
+		captured.Status = StatusCaptured
+		var limits []string
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(2)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().FindExpiredIDs(gomock.Any(), now).Return([]int{3, 4}, nil).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), 3).Return(stale, nil).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), 4).Return(captured, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(2)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 3).Return(stale, nil).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 4).Return(captured, nil).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *account.Account) error {
+			limits = append(limits, a.AvailableCreditLimit.String())
+			return nil
+		}).Times(1)
+		repo.EXPECT().Update(gomock.Any(), stale).Return(nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+		expired, err := service.ExpireStale(ctx)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, expired)
+		assert.Equal(t, StatusExpired, stale.Status)
+		assert.Equal(t, []string{"1000"}, limits)
+	})
+
+	t.Run("keep expiring the others when one fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		stale := pending()
+		stale.ID = 4
+		stale.ExpiresAt = now
+		var limits []string
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(2)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().FindExpiredIDs(gomock.Any(), now).Return([]int{3, 4}, nil).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), 3).Return(nil, errors.New("connection reset")).Times(1)
+		repo.EXPECT().FindById(gomock.Any(), 4).Return(stale, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(newAccount(), nil).Times(1)
+		repo.EXPECT().FindByIdForUpdate(gomock.Any(), 4).Return(stale, nil).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *account.Account) error {
+			limits = append(limits, a.AvailableCreditLimit.String())
+			return nil
+		}).Times(1)
+		repo.EXPECT().Update(gomock.Any(), stale).Return(nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), testutil.NewOperationTypeService(ctrl, operationTypes...), nil, clockMock, txManager)
+
+		expired, err := service.ExpireStale(ctx)
+
+		assert.ErrorContains(t, err, "authorization 3: connection reset")
+		assert.Equal(t, 1, expired)
+	})
+}
+
+// operationTypes are the built-in purchases and payment, along with an internal fee.
+var operationTypes = []operationtype.OperationType{
+	{ID: 1, Description: "COMPRA A VISTA", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true},
+	{ID: 2, Description: "COMPRA PARCELADA", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true},
+	{ID: 4, Description: "PAGAMENTO", Direction: operationtype.DirectionCredit, ConsumesCreditLimit: true, Active: true},
+	{ID: 6, Description: "JUROS ROTATIVO", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true, Internal: true},
+}
@@ -0,0 +1,25 @@
+package billing
+
+import "time"
+
+// NextClosingDate returns the first closing date after t. Cycles close at the start of the closing day.
+func NextClosingDate(t time.Time, closingDay int) time.Time {
+	closing := time.Date(t.Year(), t.Month(), closingDay, 0, 0, 0, 0, t.Location())
+
+	if !closing.After(t) {
+		closing = closing.AddDate(0, 1, 0)
+	}
+
+	return closing
+}
+
+// dueDate returns the first due day after the closing date, in the same month or the next one.
+func dueDate(closing time.Time, dueDay int) time.Time {
+	due := time.Date(closing.Year(), closing.Month(), dueDay, 0, 0, 0, 0, closing.Location())
+
+	if !due.After(closing) {
+		due = due.AddDate(0, 1, 0)
+	}
+
+	return due
+}
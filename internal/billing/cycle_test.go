@@ -0,0 +1,33 @@
+package billing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNextClosingDate(t *testing.T) {
+	cases := []struct {
+		name     string
+		after    time.Time
+		expected time.Time
+	}{
+		{"later this month", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"on the closing day", time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)},
+		{"next month", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)},
+		{"next year", time.Date(2024, 12, 5, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, NextClosingDate(c.after, 3))
+		})
+	}
+}
+
+func TestDueDate(t *testing.T) {
+	closing := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 1, 28, 0, 0, 0, 0, time.UTC), dueDate(closing, 28))
+	assert.Equal(t, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), dueDate(closing, 10))
+}
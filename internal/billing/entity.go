@@ -0,0 +1,76 @@
+package billing
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	// StatusOpen is the invoice of the cycle still running. It's built on demand and never stored.
+	StatusOpen    = "OPEN"
+	StatusClosed  = "CLOSED"
+	StatusPaid    = "PAID"
+	StatusOverdue = "OVERDUE"
+)
+
+// MinimumPaymentRate is the share of the invoice total that must be paid by the due date.
+var MinimumPaymentRate = decimal.NewFromFloat(0.15)
+
+// Invoice (fatura) is the statement of an account's billing cycle, from PeriodStart up to, but not including,
+// PeriodEnd, its closing date. Item amounts are positive for charges and negative for credits, and Total
+// carries over the previous invoice's total, since payments towards it are credits of this cycle.
+type Invoice struct {
+	ID              int             `json:"id" gorm:"primaryKey"`
+	AccountID       int             `json:"account_id"`
+	PeriodStart     time.Time       `json:"period_start"`
+	PeriodEnd       time.Time       `json:"period_end"`
+	DueDate         time.Time       `json:"due_date"`
+	PreviousBalance decimal.Decimal `json:"previous_balance"`
+	Charges         decimal.Decimal `json:"charges"`
+	Credits         decimal.Decimal `json:"credits"`
+	Total           decimal.Decimal `json:"total"`
+	MinimumPayment  decimal.Decimal `json:"minimum_payment"`
+	PaidAmount      decimal.Decimal `json:"paid_amount"`
+	Status          string          `json:"status"`
+	Items           []InvoiceItem   `json:"items"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       *time.Time      `json:"updated_at"`
+}
+
+// InvoiceItem is a transaction posted during the cycle or an installment that fell due in it.
+type InvoiceItem struct {
+	ID            int             `json:"id" gorm:"primaryKey"`
+	InvoiceID     int             `json:"invoice_id"`
+	TransactionID *int            `json:"transaction_id"`
+	InstallmentID *int            `json:"installment_id"`
+	Description   string          `json:"description"`
+	Amount        decimal.Decimal `json:"amount"`
+	// Interest is the part of an installment's amount that isn't principal. It isn't stored with the item.
+	Interest      decimal.Decimal `json:"-" gorm:"->"`
+	OperationDate time.Time       `json:"operation_date"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// IsSettled tells whether what was paid since closing covers the invoice total.
+func (i *Invoice) IsSettled() bool {
+	return i.PaidAmount.GreaterThanOrEqual(i.Total)
+}
+
+// addItems sums the items into the invoice charges, credits, total and minimum payment.
+func (i *Invoice) addItems(items []InvoiceItem) {
+	for _, item := range items {
+		if item.Amount.IsPositive() {
+			i.Charges = i.Charges.Add(item.Amount)
+		} else {
+			i.Credits = i.Credits.Add(item.Amount.Neg())
+		}
+	}
+
+	i.Items = append(i.Items, items...)
+	i.Total = i.PreviousBalance.Add(i.Charges).Sub(i.Credits)
+	i.MinimumPayment = decimal.Zero
+
+	if i.Total.IsPositive() {
+		i.MinimumPayment = i.Total.Mul(MinimumPaymentRate).Round(2)
+	}
+}
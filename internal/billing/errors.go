@@ -0,0 +1,10 @@
+package billing
+
+import "errors"
+
+var (
+	ErrAccountNotFound = errors.New("Account not found")
+	ErrInvoiceNotFound = errors.New("Invoice not found")
+
+	ErrInterestOperationTypeNotFound = errors.New("Installment interest operation type not found")
+)
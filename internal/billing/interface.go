@@ -0,0 +1,21 @@
+//go:generate mockgen -destination=mock.go -source=interface.go -package=billing
+package billing
+
+import (
+	"context"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type RepositoryInterface interface {
+	FindOpenAccountIDs(ctx context.Context) ([]int, error)
+	FindLatestInvoice(ctx context.Context, accountID int) (*Invoice, error)
+	FindInvoicesByAccount(ctx context.Context, accountID int) ([]Invoice, error)
+	FindInvoiceById(ctx context.Context, id int) (*Invoice, error)
+	CreateInvoice(ctx context.Context, invoice *Invoice) error
+	UpdateInvoiceStatus(ctx context.Context, invoice *Invoice) error
+	FindTransactionItems(ctx context.Context, accountID int, from time.Time, to time.Time) ([]InvoiceItem, error)
+	FindDueInstallmentItems(ctx context.Context, accountID int, until time.Time) ([]InvoiceItem, error)
+	FindBilledInstallmentIDs(ctx context.Context, accountID int, until time.Time) ([]int, error)
+	SumCredits(ctx context.Context, accountID int, from time.Time, to time.Time) (decimal.Decimal, error)
+}
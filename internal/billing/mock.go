@@ -0,0 +1,185 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interface.go
+
+// Package billing is a generated GoMock package.
+package billing
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	decimal "github.com/shopspring/decimal"
+)
+
+// MockRepositoryInterface is a mock of RepositoryInterface interface.
+type MockRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryInterfaceMockRecorder
+}
+
+// MockRepositoryInterfaceMockRecorder is the mock recorder for MockRepositoryInterface.
+type MockRepositoryInterfaceMockRecorder struct {
+	mock *MockRepositoryInterface
+}
+
+// NewMockRepositoryInterface creates a new mock instance.
+func NewMockRepositoryInterface(ctrl *gomock.Controller) *MockRepositoryInterface {
+	mock := &MockRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CreateInvoice mocks base method.
+func (m *MockRepositoryInterface) CreateInvoice(ctx context.Context, invoice *Invoice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvoice", ctx, invoice)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateInvoice indicates an expected call of CreateInvoice.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateInvoice(ctx, invoice interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvoice", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateInvoice), ctx, invoice)
+}
+
+// FindBilledInstallmentIDs mocks base method.
+func (m *MockRepositoryInterface) FindBilledInstallmentIDs(ctx context.Context, accountID int, until time.Time) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBilledInstallmentIDs", ctx, accountID, until)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBilledInstallmentIDs indicates an expected call of FindBilledInstallmentIDs.
+func (mr *MockRepositoryInterfaceMockRecorder) FindBilledInstallmentIDs(ctx, accountID, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBilledInstallmentIDs", reflect.TypeOf((*MockRepositoryInterface)(nil).FindBilledInstallmentIDs), ctx, accountID, until)
+}
+
+// FindDueInstallmentItems mocks base method.
+func (m *MockRepositoryInterface) FindDueInstallmentItems(ctx context.Context, accountID int, until time.Time) ([]InvoiceItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDueInstallmentItems", ctx, accountID, until)
+	ret0, _ := ret[0].([]InvoiceItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDueInstallmentItems indicates an expected call of FindDueInstallmentItems.
+func (mr *MockRepositoryInterfaceMockRecorder) FindDueInstallmentItems(ctx, accountID, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDueInstallmentItems", reflect.TypeOf((*MockRepositoryInterface)(nil).FindDueInstallmentItems), ctx, accountID, until)
+}
+
+// FindInvoiceById mocks base method.
+func (m *MockRepositoryInterface) FindInvoiceById(ctx context.Context, id int) (*Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindInvoiceById", ctx, id)
+	ret0, _ := ret[0].(*Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindInvoiceById indicates an expected call of FindInvoiceById.
+func (mr *MockRepositoryInterfaceMockRecorder) FindInvoiceById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindInvoiceById", reflect.TypeOf((*MockRepositoryInterface)(nil).FindInvoiceById), ctx, id)
+}
+
+// FindInvoicesByAccount mocks base method.
+func (m *MockRepositoryInterface) FindInvoicesByAccount(ctx context.Context, accountID int) ([]Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindInvoicesByAccount", ctx, accountID)
+	ret0, _ := ret[0].([]Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindInvoicesByAccount indicates an expected call of FindInvoicesByAccount.
+func (mr *MockRepositoryInterfaceMockRecorder) FindInvoicesByAccount(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindInvoicesByAccount", reflect.TypeOf((*MockRepositoryInterface)(nil).FindInvoicesByAccount), ctx, accountID)
+}
+
+// FindLatestInvoice mocks base method.
+func (m *MockRepositoryInterface) FindLatestInvoice(ctx context.Context, accountID int) (*Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLatestInvoice", ctx, accountID)
+	ret0, _ := ret[0].(*Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLatestInvoice indicates an expected call of FindLatestInvoice.
+func (mr *MockRepositoryInterfaceMockRecorder) FindLatestInvoice(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLatestInvoice", reflect.TypeOf((*MockRepositoryInterface)(nil).FindLatestInvoice), ctx, accountID)
+}
+
+// FindOpenAccountIDs mocks base method.
+func (m *MockRepositoryInterface) FindOpenAccountIDs(ctx context.Context) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOpenAccountIDs", ctx)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOpenAccountIDs indicates an expected call of FindOpenAccountIDs.
+func (mr *MockRepositoryInterfaceMockRecorder) FindOpenAccountIDs(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOpenAccountIDs", reflect.TypeOf((*MockRepositoryInterface)(nil).FindOpenAccountIDs), ctx)
+}
+
+// FindTransactionItems mocks base method.
+func (m *MockRepositoryInterface) FindTransactionItems(ctx context.Context, accountID int, from, to time.Time) ([]InvoiceItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTransactionItems", ctx, accountID, from, to)
+	ret0, _ := ret[0].([]InvoiceItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTransactionItems indicates an expected call of FindTransactionItems.
+func (mr *MockRepositoryInterfaceMockRecorder) FindTransactionItems(ctx, accountID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTransactionItems", reflect.TypeOf((*MockRepositoryInterface)(nil).FindTransactionItems), ctx, accountID, from, to)
+}
+
+// SumCredits mocks base method.
+func (m *MockRepositoryInterface) SumCredits(ctx context.Context, accountID int, from, to time.Time) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumCredits", ctx, accountID, from, to)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumCredits indicates an expected call of SumCredits.
+func (mr *MockRepositoryInterfaceMockRecorder) SumCredits(ctx, accountID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumCredits", reflect.TypeOf((*MockRepositoryInterface)(nil).SumCredits), ctx, accountID, from, to)
+}
+
+// UpdateInvoiceStatus mocks base method.
+func (m *MockRepositoryInterface) UpdateInvoiceStatus(ctx context.Context, invoice *Invoice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInvoiceStatus", ctx, invoice)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateInvoiceStatus indicates an expected call of UpdateInvoiceStatus.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateInvoiceStatus(ctx, invoice interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInvoiceStatus", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateInvoiceStatus), ctx, invoice)
+}
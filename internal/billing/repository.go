@@ -0,0 +1,234 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+type Repository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewRepository(db *gorm.DB, logger *slog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *Repository) FindOpenAccountIDs(ctx context.Context) ([]int, error) {
+	var ids []int
+
+	err := database.Conn(ctx, r.db).
+		Table(r.table("Account")).
+		Where("deleted_at is null").
+		Order("id").
+		Pluck("id", &ids).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding open accounts", slog.Any("error", err))
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *Repository) FindLatestInvoice(ctx context.Context, accountID int) (*Invoice, error) {
+	var invoice *Invoice
+
+	err := database.Conn(ctx, r.db).
+		Where("account_id = ?", accountID).
+		Order("period_end desc").
+		First(&invoice).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		r.logger.ErrorContext(ctx, "error finding latest invoice", slog.Any("error", err))
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+func (r *Repository) FindInvoicesByAccount(ctx context.Context, accountID int) ([]Invoice, error) {
+	var invoices []Invoice
+
+	err := database.Conn(ctx, r.db).
+		Where("account_id = ?", accountID).
+		Order("period_end desc").
+		Find(&invoices).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding invoices", slog.Any("error", err))
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+func (r *Repository) FindInvoiceById(ctx context.Context, id int) (*Invoice, error) {
+	var invoice *Invoice
+
+	err := database.Conn(ctx, r.db).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("operation_date, id")
+		}).
+		First(&invoice, "id = ?", id).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		r.logger.ErrorContext(ctx, "error finding invoice", slog.Any("error", err))
+		return nil, err
+	}
+
+	return invoice, nil
+}
+
+// CreateInvoice stores the invoice along with its items.
+func (r *Repository) CreateInvoice(ctx context.Context, invoice *Invoice) error {
+	return database.Conn(ctx, r.db).Create(invoice).Error
+}
+
+func (r *Repository) UpdateInvoiceStatus(ctx context.Context, invoice *Invoice) error {
+	return database.Conn(ctx, r.db).
+		Model(invoice).
+		Select("paid_amount", "status").
+		Updates(invoice).Error
+}
+
+// FindTransactionItems lists the account's transactions posted in [from, to) as invoice items. Installment buys
+// are left out, since they are billed installment by installment, and so is the interest booked for the billed
+// installments, which their amount includes. When a reversal cancels the rest of an installment plan, the principal
+// of the cancelled installments is taken out of its credit, as it was never billed.
+func (r *Repository) FindTransactionItems(ctx context.Context, accountID int, from time.Time, to time.Time) ([]InvoiceItem, error) {
+	var items []InvoiceItem
+
+	query := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT t.id AS transaction_id, o.description, t.operation_date,
+				CASE WHEN t.amount > 0 THEN -GREATEST(t.amount - COALESCE(c.cancelled, 0), 0) ELSE -t.amount END AS amount
+			FROM %[1]s t
+			JOIN %[2]s o ON o.id = t.operation_type_id
+			LEFT JOIN LATERAL (
+				SELECT SUM(i.amount - i.interest) AS cancelled
+				FROM %[3]s i
+				JOIN %[4]s p ON p.id = i.installment_plan_id
+				WHERE p.transaction_id = t.reversed_transaction_id AND i.status = @cancelled
+					AND t.id = (SELECT MAX(rt.id) FROM %[1]s rt WHERE rt.reversed_transaction_id = t.reversed_transaction_id)
+			) c ON true
+			WHERE t.account_id = @account AND t.deleted_at IS NULL
+				AND t.operation_date >= @from AND t.operation_date < @to
+				AND NOT EXISTS (SELECT 1 FROM %[4]s p WHERE p.transaction_id = t.id)
+				AND NOT EXISTS (SELECT 1 FROM %[3]s i WHERE i.interest_transaction_id = t.id)
+		) items
+		WHERE amount <> 0
+		ORDER BY operation_date, transaction_id`,
+		r.table("Transaction"), r.table("OperationType"), r.table("Installment"), r.table("InstallmentPlan"))
+
+	err := database.Conn(ctx, r.db).Raw(query, map[string]interface{}{
+		"account":   accountID,
+		"from":      from,
+		"to":        to,
+		"cancelled": installment.StatusCancelled,
+	}).Scan(&items).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding invoice transactions", slog.Any("error", err))
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// FindDueInstallmentItems lists the account's scheduled installments due before until as invoice items.
+func (r *Repository) FindDueInstallmentItems(ctx context.Context, accountID int, until time.Time) ([]InvoiceItem, error) {
+	var items []InvoiceItem
+
+	query := fmt.Sprintf(`
+		SELECT i.id AS installment_id, p.transaction_id, i.amount, i.interest, t.operation_date,
+			o.description || ' ' || i.number || '/' || p.installment_count AS description
+		FROM %[1]s i
+		JOIN %[2]s p ON p.id = i.installment_plan_id
+		JOIN %[3]s t ON t.id = p.transaction_id
+		JOIN %[4]s o ON o.id = t.operation_type_id
+		WHERE p.account_id = @account AND i.status = @scheduled AND i.due_date < @until
+		ORDER BY i.due_date, i.id`,
+		r.table("Installment"), r.table("InstallmentPlan"), r.table("Transaction"), r.table("OperationType"))
+
+	err := database.Conn(ctx, r.db).Raw(query, map[string]interface{}{
+		"account":   accountID,
+		"until":     until,
+		"scheduled": installment.StatusScheduled,
+	}).Scan(&items).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding due installments", slog.Any("error", err))
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// FindBilledInstallmentIDs lists the installments of the account's invoices closed until then that aren't paid yet.
+func (r *Repository) FindBilledInstallmentIDs(ctx context.Context, accountID int, until time.Time) ([]int, error) {
+	var ids []int
+
+	query := fmt.Sprintf(`
+		SELECT i.id
+		FROM %[1]s i
+		JOIN %[2]s ii ON ii.installment_id = i.id
+		JOIN %[3]s v ON v.id = ii.invoice_id
+		WHERE v.account_id = @account AND v.period_end <= @until AND i.status = @billed
+		ORDER BY i.id`,
+		r.table("Installment"), r.table("InvoiceItem"), r.table("Invoice"))
+
+	err := database.Conn(ctx, r.db).Raw(query, map[string]interface{}{
+		"account": accountID,
+		"until":   until,
+		"billed":  installment.StatusBilled,
+	}).Scan(&ids).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding billed installments", slog.Any("error", err))
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// SumCredits sums the payments and refunds posted to the account in [from, to).
+func (r *Repository) SumCredits(ctx context.Context, accountID int, from time.Time, to time.Time) (decimal.Decimal, error) {
+	var credits decimal.Decimal
+
+	err := database.Conn(ctx, r.db).
+		Table(r.table("Transaction")).
+		Select("coalesce(sum(amount), 0)").
+		Where("account_id = ? and deleted_at is null and amount > 0", accountID).
+		Where("operation_date >= ? and operation_date < ?", from, to).
+		Scan(&credits).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error summing credits", slog.Any("error", err))
+		return decimal.Zero, err
+	}
+
+	return credits, nil
+}
+
+func (r *Repository) table(model string) string {
+	return r.db.NamingStrategy.TableName(model)
+}
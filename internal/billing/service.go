@@ -0,0 +1,333 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"github.com/supwr/pismo-transactions/internal/transaction"
+	"github.com/supwr/pismo-transactions/pkg/clock"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"time"
+)
+
+type Service struct {
+	repository           RepositoryInterface
+	accountService       *account.Service
+	installmentService   *installment.Service
+	operationTypeService *operationtype.Service
+	transactionService   *transaction.Service
+	clock                clock.Clock
+	txManager            database.TxManager
+}
+
+func NewService(
+	r RepositoryInterface,
+	a *account.Service,
+	i *installment.Service,
+	o *operationtype.Service,
+	t *transaction.Service,
+	c clock.Clock,
+	tm database.TxManager,
+) *Service {
+	return &Service{
+		repository:           r,
+		accountService:       a,
+		installmentService:   i,
+		operationTypeService: o,
+		transactionService:   t,
+		clock:                c,
+		txManager:            tm,
+	}
+}
+
+// CloseCycles closes the cycles of every open account that reached their closing date and returns how many
+// invoices were closed. An account that fails doesn't stop the others; their errors are returned together.
+func (s *Service) CloseCycles(ctx context.Context) (int, error) {
+	ids, err := s.repository.FindOpenAccountIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	closed := 0
+
+	for _, id := range ids {
+		invoices, err := s.CloseAccountCycles(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("account %d: %w", id, err))
+			continue
+		}
+
+		closed += len(invoices)
+	}
+
+	return closed, errors.Join(errs...)
+}
+
+// CloseAccountCycles closes the account's cycles that reached their closing date, catching up on the ones missed
+// while the job wasn't running, and refreshes the payment status of the latest invoice. The account stays locked
+// meanwhile, so concurrent runs can't close the same cycle twice.
+func (s *Service) CloseAccountCycles(ctx context.Context, accountID int) ([]Invoice, error) {
+	var invoices []Invoice
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		acc, err := s.accountService.FindByIdForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if acc == nil {
+			return ErrAccountNotFound
+		}
+
+		if acc.IsClosed() {
+			return nil
+		}
+
+		now := s.clock.Now()
+
+		previous, err := s.repository.FindLatestInvoice(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		for {
+			start, previousBalance := acc.CreatedAt.In(now.Location()), decimal.Zero
+			if previous != nil {
+				start, previousBalance = previous.PeriodEnd.In(now.Location()), previous.Total
+			}
+
+			end := NextClosingDate(start, acc.ClosingDay)
+
+			if previous != nil {
+				// payments made after the next closing belong to the next invoice
+				if err = s.refreshStatus(ctx, previous, earliest(now, end)); err != nil {
+					return err
+				}
+			}
+
+			if end.After(now) {
+				return nil
+			}
+
+			invoice, err := s.build(ctx, acc, start, end, previousBalance)
+			if err != nil {
+				return err
+			}
+
+			invoice.Status = StatusClosed
+			if !invoice.Total.IsPositive() {
+				invoice.Status = StatusPaid
+			}
+
+			if err = s.chargeInterest(ctx, invoice); err != nil {
+				return err
+			}
+
+			var installments []int
+			for _, item := range invoice.Items {
+				if item.InstallmentID != nil {
+					installments = append(installments, *item.InstallmentID)
+				}
+			}
+
+			mark := s.installmentService.MarkBilled
+			if invoice.Status == StatusPaid {
+				mark = s.installmentService.MarkPaid
+			}
+
+			if err = mark(ctx, installments); err != nil {
+				return err
+			}
+
+			if err = s.repository.CreateInvoice(ctx, invoice); err != nil {
+				return err
+			}
+
+			invoices = append(invoices, *invoice)
+			previous = invoice
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+// Current returns the invoice of the cycle still running, with what was posted so far. It isn't stored.
+func (s *Service) Current(ctx context.Context, accountID int) (*Invoice, error) {
+	acc, err := s.accountService.FindById(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if acc == nil {
+		return nil, ErrAccountNotFound
+	}
+
+	now := s.clock.Now()
+
+	previous, err := s.repository.FindLatestInvoice(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, previousBalance := acc.CreatedAt.In(now.Location()), decimal.Zero
+	if previous != nil {
+		start, previousBalance = previous.PeriodEnd.In(now.Location()), previous.Total
+	}
+
+	invoice, err := s.build(ctx, acc, start, NextClosingDate(now, acc.ClosingDay), previousBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.Status = StatusOpen
+
+	return invoice, nil
+}
+
+func (s *Service) FindInvoicesByAccount(ctx context.Context, accountID int) ([]Invoice, error) {
+	acc, err := s.accountService.FindById(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if acc == nil {
+		return nil, ErrAccountNotFound
+	}
+
+	return s.repository.FindInvoicesByAccount(ctx, accountID)
+}
+
+func (s *Service) FindInvoiceById(ctx context.Context, id int) (*Invoice, error) {
+	return s.repository.FindInvoiceById(ctx, id)
+}
+
+// PaidAmount sums what was paid towards the invoice from its closing up to until.
+func (s *Service) PaidAmount(ctx context.Context, invoice *Invoice, until time.Time) (decimal.Decimal, error) {
+	return s.repository.SumCredits(ctx, invoice.AccountID, invoice.PeriodEnd, until)
+}
+
+// build puts together the invoice of the cycle from start to end: the transactions posted in it and the
+// installments that fell due before it closes.
+func (s *Service) build(ctx context.Context, acc *account.Account, start time.Time, end time.Time, previousBalance decimal.Decimal) (*Invoice, error) {
+	transactions, err := s.repository.FindTransactionItems(ctx, acc.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	installments, err := s.repository.FindDueInstallmentItems(ctx, acc.ID, end)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice := &Invoice{
+		AccountID:       acc.ID,
+		PeriodStart:     start,
+		PeriodEnd:       end,
+		DueDate:         dueDate(end, acc.DueDay),
+		PreviousBalance: previousBalance,
+		Charges:         decimal.Zero,
+		Credits:         decimal.Zero,
+		PaidAmount:      decimal.Zero,
+	}
+
+	invoice.addItems(append(transactions, installments...))
+
+	return invoice, nil
+}
+
+// refreshStatus updates what was paid towards a closed invoice up to until. It's paid once that covers the total,
+// and overdue when the due date went by before that.
+func (s *Service) refreshStatus(ctx context.Context, invoice *Invoice, until time.Time) error {
+	if invoice.Status == StatusPaid {
+		return nil
+	}
+
+	paid, err := s.PaidAmount(ctx, invoice, until)
+	if err != nil {
+		return err
+	}
+
+	status := StatusClosed
+	invoice.PaidAmount = paid
+
+	switch {
+	case invoice.IsSettled():
+		status = StatusPaid
+	case !until.Before(invoice.DueDate.AddDate(0, 0, 1)):
+		status = StatusOverdue
+	}
+
+	invoice.Status = status
+
+	if err = s.repository.UpdateInvoiceStatus(ctx, invoice); err != nil {
+		return err
+	}
+
+	if status != StatusPaid {
+		return nil
+	}
+
+	// the total carries the previous invoices over, so paying it pays their installments too
+	installments, err := s.repository.FindBilledInstallmentIDs(ctx, invoice.AccountID, invoice.PeriodEnd)
+	if err != nil {
+		return err
+	}
+
+	return s.installmentService.MarkPaid(ctx, installments)
+}
+
+// chargeInterest books the interest of the installments billed on the invoice. Their amount already includes it, so
+// the balance and the available limit must take it too, or paying the invoice would leave the account in credit.
+func (s *Service) chargeInterest(ctx context.Context, invoice *Invoice) error {
+	var operationType *operationtype.OperationType
+
+	for _, item := range invoice.Items {
+		if item.InstallmentID == nil || !item.Interest.IsPositive() {
+			continue
+		}
+
+		if operationType == nil {
+			var err error
+			if operationType, err = s.operationTypeService.FindByCode(ctx, operationtype.CodeInstallmentInterest); err != nil {
+				return err
+			}
+
+			if operationType == nil {
+				return ErrInterestOperationTypeNotFound
+			}
+		}
+
+		charge := &transaction.Transaction{
+			AccountID:       invoice.AccountID,
+			OperationTypeID: operationType.ID,
+			Amount:          item.Interest,
+		}
+
+		if err := s.transactionService.Charge(ctx, charge); err != nil {
+			return err
+		}
+
+		if err := s.installmentService.SetInterestTransaction(ctx, *item.InstallmentID, charge.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func earliest(a time.Time, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+
+	return a
+}
@@ -0,0 +1,470 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"github.com/supwr/pismo-transactions/internal/ledger"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"github.com/supwr/pismo-transactions/internal/outbox"
+	"github.com/supwr/pismo-transactions/internal/testutil"
+	"github.com/supwr/pismo-transactions/internal/transaction"
+	clockmock "github.com/supwr/pismo-transactions/pkg/clock/mock"
+	dbmock "github.com/supwr/pismo-transactions/pkg/database/mock"
+	"testing"
+	"time"
+)
+
+func TestService_CloseAccountCycles(t *testing.T) {
+	createdAt := time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC)
+	acc := &account.Account{ID: 1, Status: account.StatusActive, ClosingDay: 3, DueDay: 10, CreatedAt: createdAt}
+	firstClosing := time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)
+	secondClosing := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	t.Run("close the first cycle", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		installmentID := 7
+		transactionID := 3
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		clockMock.EXPECT().Now().Return(time.Date(2024, 2, 3, 1, 0, 0, 0, time.UTC)).Times(1)
+		repo.EXPECT().FindLatestInvoice(gomock.Any(), 1).Return(nil, nil).Times(1)
+		repo.EXPECT().FindTransactionItems(gomock.Any(), 1, createdAt, firstClosing).Return([]InvoiceItem{
+			{TransactionID: &transactionID, Description: "COMPRA A VISTA", Amount: decimal.NewFromInt(100)},
+			{TransactionID: &transactionID, Description: "PAGAMENTO", Amount: decimal.NewFromInt(-40)},
+		}, nil).Times(1)
+		repo.EXPECT().FindDueInstallmentItems(gomock.Any(), 1, firstClosing).Return([]InvoiceItem{
+			{InstallmentID: &installmentID, Description: "COMPRA PARCELADA 1/3", Amount: decimal.NewFromFloat(33.33)},
+		}, nil).Times(1)
+		installmentRepo.EXPECT().UpdateInstallmentsStatus(gomock.Any(), []int{7}, installment.StatusBilled).Return(nil).Times(1)
+		repo.EXPECT().CreateInvoice(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, i *Invoice) error {
+			assert.Equal(t, createdAt, i.PeriodStart)
+			assert.Equal(t, firstClosing, i.PeriodEnd)
+			assert.Equal(t, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), i.DueDate)
+			assert.Equal(t, StatusClosed, i.Status)
+			assert.Len(t, i.Items, 3)
+			assert.True(t, decimal.NewFromFloat(133.33).Equal(i.Charges))
+			assert.True(t, decimal.NewFromInt(40).Equal(i.Credits))
+			assert.True(t, decimal.NewFromFloat(93.33).Equal(i.Total))
+			assert.True(t, decimal.NewFromFloat(14).Equal(i.MinimumPayment))
+			return nil
+		}).Times(1)
+		repo.EXPECT().SumCredits(gomock.Any(), 1, firstClosing, time.Date(2024, 2, 3, 1, 0, 0, 0, time.UTC)).Return(decimal.Zero, nil).Times(1)
+		repo.EXPECT().UpdateInvoiceStatus(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), installment.NewService(installmentRepo), nil, nil, clockMock, txManager)
+		invoices, err := service.CloseAccountCycles(ctx, 1)
+
+		assert.Nil(t, err)
+		assert.Len(t, invoices, 1)
+	})
+
+	t.Run("cycle not closed yet", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		clockMock.EXPECT().Now().Return(time.Date(2024, 2, 2, 23, 59, 0, 0, time.UTC)).Times(1)
+		repo.EXPECT().FindLatestInvoice(gomock.Any(), 1).Return(nil, nil).Times(1)
+		repo.EXPECT().CreateInvoice(gomock.Any(), gomock.Any()).Times(0)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), installment.NewService(installmentRepo), nil, nil, clockMock, txManager)
+		invoices, err := service.CloseAccountCycles(ctx, 1)
+
+		assert.Nil(t, err)
+		assert.Empty(t, invoices)
+	})
+
+	t.Run("catch up on missed cycles carrying the total over", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		now := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().FindLatestInvoice(gomock.Any(), 1).Return(nil, nil).Times(1)
+
+		repo.EXPECT().FindTransactionItems(gomock.Any(), 1, createdAt, firstClosing).Return([]InvoiceItem{
+			{Description: "COMPRA A VISTA", Amount: decimal.NewFromInt(100)},
+		}, nil).Times(1)
+		repo.EXPECT().FindDueInstallmentItems(gomock.Any(), 1, firstClosing).Return(nil, nil).Times(1)
+		repo.EXPECT().FindTransactionItems(gomock.Any(), 1, firstClosing, secondClosing).Return([]InvoiceItem{
+			{Description: "PAGAMENTO", Amount: decimal.NewFromInt(-30)},
+		}, nil).Times(1)
+		repo.EXPECT().FindDueInstallmentItems(gomock.Any(), 1, secondClosing).Return(nil, nil).Times(1)
+
+		// the first invoice is left unpaid past its due date once the second one closes
+		repo.EXPECT().SumCredits(gomock.Any(), 1, firstClosing, secondClosing).Return(decimal.NewFromInt(30), nil).Times(1)
+		repo.EXPECT().UpdateInvoiceStatus(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, i *Invoice) error {
+			assert.Equal(t, firstClosing, i.PeriodEnd)
+			assert.Equal(t, StatusOverdue, i.Status)
+			assert.True(t, decimal.NewFromInt(30).Equal(i.PaidAmount))
+			return nil
+		}).Times(1)
+		repo.EXPECT().SumCredits(gomock.Any(), 1, secondClosing, now).Return(decimal.Zero, nil).Times(1)
+		repo.EXPECT().UpdateInvoiceStatus(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, i *Invoice) error {
+			assert.Equal(t, secondClosing, i.PeriodEnd)
+			assert.Equal(t, StatusClosed, i.Status)
+			return nil
+		}).Times(1)
+
+		installmentRepo.EXPECT().UpdateInstallmentsStatus(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		repo.EXPECT().CreateInvoice(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), installment.NewService(installmentRepo), nil, nil, clockMock, txManager)
+		invoices, err := service.CloseAccountCycles(ctx, 1)
+
+		assert.Nil(t, err)
+		assert.Len(t, invoices, 2)
+		assert.True(t, decimal.NewFromInt(100).Equal(invoices[1].PreviousBalance))
+		assert.True(t, decimal.NewFromInt(70).Equal(invoices[1].Total))
+	})
+
+	t.Run("latest invoice paid before a new cycle closes pays its installments", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		now := time.Date(2024, 2, 8, 0, 0, 0, 0, time.UTC)
+		previous := &Invoice{ID: 5, AccountID: 1, PeriodEnd: firstClosing, DueDate: time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), Total: decimal.NewFromInt(100), Status: StatusClosed}
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().FindLatestInvoice(gomock.Any(), 1).Return(previous, nil).Times(1)
+		repo.EXPECT().SumCredits(gomock.Any(), 1, firstClosing, now).Return(decimal.NewFromInt(100), nil).Times(1)
+		repo.EXPECT().UpdateInvoiceStatus(gomock.Any(), previous).Return(nil).Times(1)
+		repo.EXPECT().FindBilledInstallmentIDs(gomock.Any(), 1, firstClosing).Return([]int{7, 8}, nil).Times(1)
+		installmentRepo.EXPECT().UpdateInstallmentsStatus(gomock.Any(), []int{7, 8}, installment.StatusPaid).Return(nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), installment.NewService(installmentRepo), nil, nil, clockMock, txManager)
+		invoices, err := service.CloseAccountCycles(ctx, 1)
+
+		assert.Nil(t, err)
+		assert.Empty(t, invoices)
+		assert.Equal(t, StatusPaid, previous.Status)
+	})
+
+	t.Run("closed account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&account.Account{ID: 1, Status: account.StatusClosed}, nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), installment.NewService(installmentRepo), nil, nil, clockMock, txManager)
+		invoices, err := service.CloseAccountCycles(ctx, 1)
+
+		assert.Nil(t, err)
+		assert.Empty(t, invoices)
+	})
+
+	t.Run("error creating invoice", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		expectedErr := errors.New("database error")
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		clockMock.EXPECT().Now().Return(firstClosing).Times(1)
+		repo.EXPECT().FindLatestInvoice(gomock.Any(), 1).Return(nil, nil).Times(1)
+		repo.EXPECT().FindTransactionItems(gomock.Any(), 1, createdAt, firstClosing).Return(nil, nil).Times(1)
+		repo.EXPECT().FindDueInstallmentItems(gomock.Any(), 1, firstClosing).Return(nil, nil).Times(1)
+		repo.EXPECT().CreateInvoice(gomock.Any(), gomock.Any()).Return(expectedErr).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), installment.NewService(installmentRepo), nil, nil, clockMock, txManager)
+		invoices, err := service.CloseAccountCycles(ctx, 1)
+
+		assert.Nil(t, invoices)
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestService_InstallmentInterest(t *testing.T) {
+	t.Run("paying off an installment buy with interest restores the balance and the limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		operationTypeRepo := operationtype.NewMockRepositoryInterface(ctrl)
+		transactionRepo := transaction.NewMockRepositoryInterface(ctrl)
+		ledgerRepo := ledger.NewMockRepositoryInterface(ctrl)
+		outboxRepo := outbox.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		code := operationtype.CodeInstallmentInterest
+		acc := account.Account{
+			ID:                   1,
+			Status:               account.StatusActive,
+			CreditLimit:          decimal.NewFromInt(1000),
+			AvailableCreditLimit: decimal.NewFromInt(1000),
+			ClosingDay:           3,
+			DueDay:               10,
+			CreatedAt:            time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC),
+		}
+
+		var now time.Time
+		var plan installment.InstallmentPlan
+		var transactions []transaction.Transaction
+		var postings []ledger.Posting
+		var invoices []Invoice
+
+		clockMock.EXPECT().Now().DoAndReturn(func() time.Time { return now }).AnyTimes()
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(6)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(5)
+		operationTypeRepo.EXPECT().FindAll(gomock.Any()).Return([]operationtype.OperationType{
+			{ID: transaction.OperationTypeInstallmentBuy, Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true},
+			{ID: transaction.OperationTypePayment, Direction: operationtype.DirectionCredit, ConsumesCreditLimit: true, Active: true},
+			{ID: 9, Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true, Internal: true, BuiltIn: true, Code: &code},
+		}, nil).Times(3)
+
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).DoAndReturn(func(_ context.Context, _ int) (*account.Account, error) {
+			locked := acc
+			return &locked, nil
+		}).Times(6)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *account.Account) error {
+			acc = *a
+			return nil
+		}).Times(5)
+
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, tr *transaction.Transaction) error {
+			tr.ID = len(transactions) + 1
+			transactions = append(transactions, *tr)
+			return nil
+		}).Times(5)
+		transactionRepo.EXPECT().FindOutstandingByAccount(gomock.Any(), 1).DoAndReturn(func(_ context.Context, _ int) ([]transaction.Transaction, error) {
+			var outstanding []transaction.Transaction
+			for _, tr := range transactions {
+				if tr.Balance.IsNegative() {
+					outstanding = append(outstanding, tr)
+				}
+			}
+			return outstanding, nil
+		}).Times(1)
+		transactionRepo.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, tr *transaction.Transaction) error {
+			transactions[tr.ID-1].Balance = tr.Balance
+			return nil
+		}).Times(4)
+		transactionRepo.EXPECT().CreateDischarges(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		ledgerRepo.EXPECT().CreateEntry(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, e *ledger.JournalEntry) error {
+			postings = append(postings, e.Postings...)
+			return nil
+		}).Times(5)
+		outboxRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(5)
+
+		installmentRepo.EXPECT().CreatePlan(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, p *installment.InstallmentPlan) error {
+			for i := range p.Installments {
+				p.Installments[i].ID = i + 1
+			}
+			plan = *p
+			return nil
+		}).Times(1)
+		installmentRepo.EXPECT().UpdateInstallmentsStatus(gomock.Any(), gomock.Any(), installment.StatusBilled).DoAndReturn(func(_ context.Context, ids []int, status string) error {
+			for _, id := range ids {
+				plan.Installments[id-1].Status = status
+			}
+			return nil
+		}).Times(3)
+		installmentRepo.EXPECT().UpdateInterestTransaction(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, id int, transactionID int) error {
+			plan.Installments[id-1].InterestTransactionID = &transactionID
+			return nil
+		}).Times(3)
+
+		repo.EXPECT().FindLatestInvoice(gomock.Any(), 1).Return(nil, nil).Times(1)
+		// the purchase and the interest booked for it are billed through the installments
+		repo.EXPECT().FindTransactionItems(gomock.Any(), 1, gomock.Any(), gomock.Any()).Return(nil, nil).Times(4)
+		repo.EXPECT().FindDueInstallmentItems(gomock.Any(), 1, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, until time.Time) ([]InvoiceItem, error) {
+			var items []InvoiceItem
+			for _, i := range plan.Installments {
+				if i.Status == installment.StatusScheduled && i.DueDate.Before(until) {
+					id := i.ID
+					items = append(items, InvoiceItem{InstallmentID: &id, TransactionID: &plan.TransactionID, Amount: i.Amount, Interest: i.Interest})
+				}
+			}
+			return items, nil
+		}).Times(4)
+		repo.EXPECT().CreateInvoice(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, i *Invoice) error {
+			invoices = append(invoices, *i)
+			return nil
+		}).Times(4)
+		repo.EXPECT().SumCredits(gomock.Any(), 1, gomock.Any(), gomock.Any()).Return(decimal.Zero, nil).Times(3)
+		repo.EXPECT().UpdateInvoiceStatus(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+
+		installmentService := installment.NewService(installmentRepo)
+		operationTypeService := operationtype.NewService(operationTypeRepo, clockMock)
+		outboxService := outbox.NewService(outboxRepo, clockMock)
+		accountService := account.NewService(accountRepo, outboxService, clockMock, txManager)
+		transactionService := transaction.NewService(transactionRepo, accountService, installmentService, operationTypeService, ledger.NewService(ledgerRepo), outboxService, transaction.NewMetrics(prometheus.NewRegistry()), clockMock, txManager)
+		service := NewService(repo, accountService, installmentService, operationTypeService, transactionService, clockMock, txManager)
+
+		now = time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+		err := transactionService.Create(ctx, &transaction.Transaction{
+			AccountID:       1,
+			OperationTypeID: transaction.OperationTypeInstallmentBuy,
+			Amount:          decimal.NewFromInt(300),
+			Installments:    3,
+			InterestRate:    decimal.NewFromFloat(0.02),
+		})
+		assert.Nil(t, err)
+
+		// the installments fall due on Feb 15, Mar 15 and Apr 15, and are billed by the invoices closing the month after
+		now = time.Date(2024, 5, 4, 0, 0, 0, 0, time.UTC)
+		_, err = service.CloseAccountCycles(ctx, 1)
+		assert.Nil(t, err)
+		assert.Len(t, invoices, 4)
+
+		total := invoices[len(invoices)-1].Total
+		assert.True(t, plan.Total.Equal(total))
+		assert.True(t, plan.Total.GreaterThan(decimal.NewFromInt(300)))
+		assert.True(t, acc.AvailableCreditLimit.Equal(decimal.NewFromInt(1000).Sub(total)))
+
+		for _, i := range plan.Installments {
+			assert.Equal(t, installment.StatusBilled, i.Status)
+			assert.NotNil(t, i.InterestTransactionID)
+		}
+
+		now = time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)
+		err = transactionService.Create(ctx, &transaction.Transaction{
+			AccountID:       1,
+			OperationTypeID: transaction.OperationTypePayment,
+			Amount:          total,
+		})
+		assert.Nil(t, err)
+
+		balance := decimal.Zero
+		for _, tr := range transactions {
+			balance = balance.Add(tr.Balance)
+		}
+
+		receivables, credit := decimal.Zero, decimal.Zero
+		for _, p := range postings {
+			switch {
+			case p.Ledger == ledger.LedgerReceivables && p.Direction == ledger.DirectionDebit:
+				receivables = receivables.Add(p.Amount)
+			case p.Ledger == ledger.LedgerReceivables:
+				receivables = receivables.Sub(p.Amount)
+			case p.Ledger == ledger.LedgerAccount:
+				credit = credit.Add(p.Amount)
+			}
+		}
+
+		assert.True(t, balance.IsZero())
+		assert.True(t, receivables.IsZero())
+		assert.True(t, credit.IsZero())
+		assert.True(t, acc.AvailableCreditLimit.Equal(acc.CreditLimit))
+	})
+}
+
+func TestService_CloseCycles(t *testing.T) {
+	t.Run("an account failing doesn't stop the others", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		expectedErr := errors.New("database error")
+
+		repo.EXPECT().FindOpenAccountIDs(gomock.Any()).Return([]int{1, 2}, nil).Times(1)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(2)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(nil, expectedErr).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 2).Return(&account.Account{ID: 2, Status: account.StatusClosed}, nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), installment.NewService(installmentRepo), nil, nil, clockMock, txManager)
+		closed, err := service.CloseCycles(ctx)
+
+		assert.Equal(t, 0, closed)
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestService_Current(t *testing.T) {
+	t.Run("current cycle from the latest invoice", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		now := time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)
+		previousEnd := time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)
+		nextEnd := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
+
+		accountRepo.EXPECT().FindById(gomock.Any(), 1).Return(&account.Account{ID: 1, ClosingDay: 3, DueDay: 10}, nil).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().FindLatestInvoice(gomock.Any(), 1).Return(&Invoice{PeriodEnd: previousEnd, Total: decimal.NewFromInt(50)}, nil).Times(1)
+		repo.EXPECT().FindTransactionItems(gomock.Any(), 1, previousEnd, nextEnd).Return([]InvoiceItem{
+			{Description: "PAGAMENTO", Amount: decimal.NewFromInt(-50)},
+		}, nil).Times(1)
+		repo.EXPECT().FindDueInstallmentItems(gomock.Any(), 1, nextEnd).Return(nil, nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), installment.NewService(installmentRepo), nil, nil, clockMock, txManager)
+		invoice, err := service.Current(ctx, 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, StatusOpen, invoice.Status)
+		assert.Equal(t, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), invoice.DueDate)
+		assert.True(t, invoice.Total.IsZero())
+		assert.True(t, invoice.MinimumPayment.IsZero())
+	})
+
+	t.Run("account not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		accountRepo.EXPECT().FindById(gomock.Any(), 1).Return(nil, nil).Times(1)
+
+		service := NewService(repo, account.NewService(accountRepo, nil, clockMock, txManager), installment.NewService(installmentRepo), nil, nil, clockMock, txManager)
+		invoice, err := service.Current(ctx, 1)
+
+		assert.Nil(t, invoice)
+		assert.ErrorIs(t, err, ErrAccountNotFound)
+	})
+}
@@ -0,0 +1,113 @@
+package fee
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	// KindRevolvingInterest (juros rotativo) is a monthly rate charged daily on what is left unpaid after the due date.
+	KindRevolvingInterest = "REVOLVING_INTEREST"
+	// KindLateFee (multa) is charged once on what is overdue when not even the minimum payment was made.
+	KindLateFee = "LATE_FEE"
+	// KindLateInterest (juros de mora) is a monthly rate charged daily on top of the revolving interest while late.
+	KindLateInterest = "LATE_INTEREST"
+)
+
+// daysInMonth turns monthly rates into daily ones, following the commercial month.
+const daysInMonth = 30
+
+var Kinds = []string{KindRevolvingInterest, KindLateFee, KindLateInterest}
+
+// Rate is the rate of a kind of fee from ValidFrom on, until a newer one takes over. Fees are posted with its
+// operation type.
+type Rate struct {
+	ID              int             `json:"id" gorm:"primaryKey"`
+	Kind            string          `json:"kind"`
+	OperationTypeID int             `json:"operation_type_id"`
+	Rate            decimal.Decimal `json:"rate"`
+	ValidFrom       time.Time       `json:"valid_from"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// Accrual is a fee charged on an overdue invoice for a given day, along with what it was charged on.
+type Accrual struct {
+	ID              int             `json:"id" gorm:"primaryKey"`
+	InvoiceID       int             `json:"invoice_id"`
+	AccountID       int             `json:"account_id"`
+	Kind            string          `json:"kind"`
+	AccrualDate     time.Time       `json:"accrual_date"`
+	Base            decimal.Decimal `json:"base"`
+	Rate            decimal.Decimal `json:"rate"`
+	Amount          decimal.Decimal `json:"amount"`
+	OperationTypeID int             `json:"operation_type_id"`
+	TransactionID   int             `json:"transaction_id"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// rateAt returns the rate of kind in effect on day, or nil when there is none yet.
+func rateAt(rates []Rate, kind string, day time.Time) *Rate {
+	var current *Rate
+
+	for i, r := range rates {
+		if r.Kind != kind || dateOf(r.ValidFrom, day.Location()).After(day) {
+			continue
+		}
+
+		if current == nil || r.ValidFrom.After(current.ValidFrom) {
+			current = &rates[i]
+		}
+	}
+
+	return current
+}
+
+// accruals works out the fees of an overdue invoice for day. unpaid is what is still owed at the end of the day and
+// overdue what was owed once the due date went by; late tells whether the minimum payment was missed, and first
+// whether day is the first one after the due date.
+func accruals(day time.Time, unpaid decimal.Decimal, overdue decimal.Decimal, late bool, first bool, rates []Rate) []Accrual {
+	var result []Accrual
+
+	add := func(kind string, base decimal.Decimal, daily bool) {
+		rate := rateAt(rates, kind, day)
+		if rate == nil {
+			return
+		}
+
+		amount := base.Mul(rate.Rate)
+		if daily {
+			amount = amount.Div(decimal.NewFromInt(daysInMonth))
+		}
+
+		amount = amount.Round(2)
+		if !amount.IsPositive() {
+			return
+		}
+
+		result = append(result, Accrual{
+			Kind:            kind,
+			AccrualDate:     day,
+			Base:            base,
+			Rate:            rate.Rate,
+			Amount:          amount,
+			OperationTypeID: rate.OperationTypeID,
+		})
+	}
+
+	if late && first {
+		add(KindLateFee, overdue, false)
+	}
+
+	add(KindRevolvingInterest, unpaid, true)
+
+	if late {
+		add(KindLateInterest, unpaid, true)
+	}
+
+	return result
+}
+
+// dateOf is the start of t's day in loc. Dates read from DATE columns come back at midnight UTC.
+func dateOf(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
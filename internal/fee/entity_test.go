@@ -0,0 +1,59 @@
+package fee
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var testRates = []Rate{
+	{Kind: KindRevolvingInterest, OperationTypeID: 6, Rate: decimal.NewFromFloat(0.14), ValidFrom: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+	{Kind: KindRevolvingInterest, OperationTypeID: 6, Rate: decimal.NewFromFloat(0.12), ValidFrom: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	{Kind: KindLateFee, OperationTypeID: 7, Rate: decimal.NewFromFloat(0.02), ValidFrom: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+	{Kind: KindLateInterest, OperationTypeID: 8, Rate: decimal.NewFromFloat(0.01), ValidFrom: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+}
+
+func TestAccruals(t *testing.T) {
+	day := time.Date(2024, 2, 11, 0, 0, 0, 0, time.UTC)
+
+	t.Run("first late day charges the late fee once", func(t *testing.T) {
+		result := accruals(day, decimal.NewFromInt(1000), decimal.NewFromInt(1000), true, true, testRates)
+
+		assert.Len(t, result, 3)
+		assert.Equal(t, KindLateFee, result[0].Kind)
+		assert.Equal(t, "20", result[0].Amount.String())
+		assert.Equal(t, KindRevolvingInterest, result[1].Kind)
+		assert.Equal(t, "4.67", result[1].Amount.String())
+		assert.Equal(t, KindLateInterest, result[2].Kind)
+		assert.Equal(t, "0.33", result[2].Amount.String())
+		assert.Equal(t, 8, result[2].OperationTypeID)
+	})
+
+	t.Run("minimum paid only accrues revolving interest", func(t *testing.T) {
+		result := accruals(day, decimal.NewFromInt(850), decimal.NewFromInt(850), false, true, testRates)
+
+		assert.Len(t, result, 1)
+		assert.Equal(t, KindRevolvingInterest, result[0].Kind)
+		assert.Equal(t, "3.97", result[0].Amount.String())
+	})
+
+	t.Run("rate in effect on the day", func(t *testing.T) {
+		result := accruals(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), decimal.NewFromInt(1000), decimal.NewFromInt(1000), false, false, testRates)
+
+		assert.Equal(t, "4", result[0].Amount.String())
+		assert.Equal(t, "0.12", result[0].Rate.String())
+	})
+
+	t.Run("amounts rounding to zero aren't charged", func(t *testing.T) {
+		result := accruals(day, decimal.NewFromFloat(0.05), decimal.NewFromFloat(0.05), false, false, testRates)
+
+		assert.Empty(t, result)
+	})
+
+	t.Run("no rate configured", func(t *testing.T) {
+		result := accruals(day, decimal.NewFromInt(1000), decimal.NewFromInt(1000), true, true, nil)
+
+		assert.Empty(t, result)
+	})
+}
@@ -0,0 +1,11 @@
+package fee
+
+import "errors"
+
+var (
+	ErrInvalidKind             = errors.New("Fee kind must be REVOLVING_INTEREST, LATE_FEE or LATE_INTEREST")
+	ErrInvalidRate             = errors.New("Fee rate can't be negative")
+	ErrOperationTypeRequired   = errors.New("An operation type is required for the first rate of a fee kind")
+	ErrOperationTypeNotFound   = errors.New("Operation type not found")
+	ErrOperationTypeNotAllowed = errors.New("Fees must be charged with an internal debit operation type")
+)
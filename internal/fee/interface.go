@@ -0,0 +1,16 @@
+//go:generate mockgen -destination=mock.go -source=interface.go -package=fee
+package fee
+
+import (
+	"context"
+	"github.com/supwr/pismo-transactions/internal/billing"
+	"time"
+)
+
+type RepositoryInterface interface {
+	FindRates(ctx context.Context) ([]Rate, error)
+	CreateRate(ctx context.Context, rate *Rate) error
+	FindOverdueInvoices(ctx context.Context, since time.Time, before time.Time) ([]billing.Invoice, error)
+	FindLastAccrualDate(ctx context.Context, invoiceID int) (*time.Time, error)
+	CreateAccrual(ctx context.Context, accrual *Accrual) error
+}
@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interface.go
+
+// Package fee is a generated GoMock package.
+package fee
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	billing "github.com/supwr/pismo-transactions/internal/billing"
+)
+
+// MockRepositoryInterface is a mock of RepositoryInterface interface.
+type MockRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryInterfaceMockRecorder
+}
+
+// MockRepositoryInterfaceMockRecorder is the mock recorder for MockRepositoryInterface.
+type MockRepositoryInterfaceMockRecorder struct {
+	mock *MockRepositoryInterface
+}
+
+// NewMockRepositoryInterface creates a new mock instance.
+func NewMockRepositoryInterface(ctrl *gomock.Controller) *MockRepositoryInterface {
+	mock := &MockRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CreateAccrual mocks base method.
+func (m *MockRepositoryInterface) CreateAccrual(ctx context.Context, accrual *Accrual) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccrual", ctx, accrual)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccrual indicates an expected call of CreateAccrual.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateAccrual(ctx, accrual interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccrual", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateAccrual), ctx, accrual)
+}
+
+// CreateRate mocks base method.
+func (m *MockRepositoryInterface) CreateRate(ctx context.Context, rate *Rate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRate", ctx, rate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRate indicates an expected call of CreateRate.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateRate(ctx, rate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRate", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateRate), ctx, rate)
+}
+
+// FindLastAccrualDate mocks base method.
+func (m *MockRepositoryInterface) FindLastAccrualDate(ctx context.Context, invoiceID int) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLastAccrualDate", ctx, invoiceID)
+	ret0, _ := ret[0].(*time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLastAccrualDate indicates an expected call of FindLastAccrualDate.
+func (mr *MockRepositoryInterfaceMockRecorder) FindLastAccrualDate(ctx, invoiceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLastAccrualDate", reflect.TypeOf((*MockRepositoryInterface)(nil).FindLastAccrualDate), ctx, invoiceID)
+}
+
+// FindOverdueInvoices mocks base method.
+func (m *MockRepositoryInterface) FindOverdueInvoices(ctx context.Context, since, before time.Time) ([]billing.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOverdueInvoices", ctx, since, before)
+	ret0, _ := ret[0].([]billing.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOverdueInvoices indicates an expected call of FindOverdueInvoices.
+func (mr *MockRepositoryInterfaceMockRecorder) FindOverdueInvoices(ctx, since, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOverdueInvoices", reflect.TypeOf((*MockRepositoryInterface)(nil).FindOverdueInvoices), ctx, since, before)
+}
+
+// FindRates mocks base method.
+func (m *MockRepositoryInterface) FindRates(ctx context.Context) ([]Rate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRates", ctx)
+	ret0, _ := ret[0].([]Rate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRates indicates an expected call of FindRates.
+func (mr *MockRepositoryInterfaceMockRecorder) FindRates(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRates", reflect.TypeOf((*MockRepositoryInterface)(nil).FindRates), ctx)
+}
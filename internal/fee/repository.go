@@ -0,0 +1,86 @@
+package fee
+
+import (
+	"context"
+	"errors"
+	"github.com/supwr/pismo-transactions/internal/billing"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+type Repository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewRepository(db *gorm.DB, logger *slog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *Repository) FindRates(ctx context.Context) ([]Rate, error) {
+	var rates []Rate
+
+	if err := database.Conn(ctx, r.db).Order("kind, valid_from, id").Find(&rates).Error; err != nil {
+		r.logger.ErrorContext(ctx, "error finding fee rates", slog.Any("error", err))
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *Repository) CreateRate(ctx context.Context, rate *Rate) error {
+	if err := database.Conn(ctx, r.db).Create(rate).Error; err != nil {
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return ErrOperationTypeNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// FindOverdueInvoices lists the invoices due in [since, before) that weren't paid yet. Older ones can't accrue
+// fees anymore, since their next invoice closed since.
+func (r *Repository) FindOverdueInvoices(ctx context.Context, since time.Time, before time.Time) ([]billing.Invoice, error) {
+	var invoices []billing.Invoice
+
+	err := database.Conn(ctx, r.db).
+		Where("status <> ? and total > 0", billing.StatusPaid).
+		Where("due_date >= ? and due_date < ?", since, before).
+		Order("account_id, period_end").
+		Find(&invoices).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding overdue invoices", slog.Any("error", err))
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+func (r *Repository) FindLastAccrualDate(ctx context.Context, invoiceID int) (*time.Time, error) {
+	var last *time.Time
+
+	err := database.Conn(ctx, r.db).
+		Model(&Accrual{}).
+		Select("max(accrual_date)").
+		Where("invoice_id = ?", invoiceID).
+		Scan(&last).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding last fee accrual", slog.Any("error", err))
+		return nil, err
+	}
+
+	return last, nil
+}
+
+func (r *Repository) CreateAccrual(ctx context.Context, accrual *Accrual) error {
+	return database.Conn(ctx, r.db).Create(accrual).Error
+}
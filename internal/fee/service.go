@@ -0,0 +1,219 @@
+package fee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/billing"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"github.com/supwr/pismo-transactions/internal/transaction"
+	"github.com/supwr/pismo-transactions/pkg/clock"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"slices"
+	"time"
+)
+
+// lookback is how far back overdue invoices are looked for. Their fees stop once the next invoice closes, about a
+// month after the due date, so older ones have nothing left to accrue.
+const lookback = 2
+
+type Service struct {
+	repository           RepositoryInterface
+	accountService       *account.Service
+	billingService       *billing.Service
+	operationTypeService *operationtype.Service
+	transactionService   *transaction.Service
+	clock                clock.Clock
+	txManager            database.TxManager
+}
+
+func NewService(
+	r RepositoryInterface,
+	a *account.Service,
+	b *billing.Service,
+	o *operationtype.Service,
+	t *transaction.Service,
+	c clock.Clock,
+	tm database.TxManager,
+) *Service {
+	return &Service{
+		repository:           r,
+		accountService:       a,
+		billingService:       b,
+		operationTypeService: o,
+		transactionService:   t,
+		clock:                c,
+		txManager:            tm,
+	}
+}
+
+func (s *Service) Rates(ctx context.Context) ([]Rate, error) {
+	return s.repository.FindRates(ctx)
+}
+
+// CreateRate schedules a new rate for a kind of fee. Without an operation type it keeps the one of the kind's
+// current rate, which must still be an active internal debit, as fees are charged with it.
+func (s *Service) CreateRate(ctx context.Context, rate *Rate) error {
+	if !slices.Contains(Kinds, rate.Kind) {
+		return ErrInvalidKind
+	}
+
+	if rate.Rate.IsNegative() {
+		return ErrInvalidRate
+	}
+
+	if rate.OperationTypeID == 0 {
+		rates, err := s.repository.FindRates(ctx)
+		if err != nil {
+			return err
+		}
+
+		current := rateAt(rates, rate.Kind, rate.ValidFrom)
+		if current == nil {
+			return ErrOperationTypeRequired
+		}
+
+		rate.OperationTypeID = current.OperationTypeID
+	}
+
+	operationType, err := s.operationTypeService.FindById(ctx, rate.OperationTypeID)
+	if err != nil {
+		return err
+	}
+
+	if operationType == nil || !operationType.Active {
+		return ErrOperationTypeNotFound
+	}
+
+	if !operationType.Internal || !operationType.IsDebit() {
+		return ErrOperationTypeNotAllowed
+	}
+
+	return s.repository.CreateRate(ctx, rate)
+}
+
+// Accrue charges the fees of every overdue invoice for each day that ended since they were last charged, and
+// returns how many fees were posted. It's meant to run daily; missed days are caught up on the next run, and
+// running it twice on the same day charges nothing new. An invoice that fails doesn't stop the others.
+func (s *Service) Accrue(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+	today := dateOf(now, now.Location())
+
+	rates, err := s.repository.FindRates(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	invoices, err := s.repository.FindOverdueInvoices(ctx, today.AddDate(0, -lookback, 0), today)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	posted := 0
+
+	for i := range invoices {
+		n, err := s.accrueInvoice(ctx, &invoices[i], today, rates)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invoice %d: %w", invoices[i].ID, err))
+			continue
+		}
+
+		posted += n
+	}
+
+	return posted, errors.Join(errs...)
+}
+
+// accrueInvoice charges the invoice's fees for the days from the one after its due date, or after its last
+// accrual, up to the day before today or before the next invoice closes, whichever comes first. Fees are dated on
+// the day they accrued, even when missed days are caught up later. Fees and their accruals commit together, with
+// the account locked, so concurrent runs can't charge a day twice.
+func (s *Service) accrueInvoice(ctx context.Context, invoice *billing.Invoice, today time.Time, rates []Rate) (int, error) {
+	posted := 0
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		acc, err := s.accountService.FindByIdForUpdate(ctx, invoice.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if acc == nil || acc.IsClosed() {
+			return nil
+		}
+
+		loc := today.Location()
+		firstDay := dateOf(invoice.DueDate, loc).AddDate(0, 0, 1)
+		day := firstDay
+
+		last, err := s.repository.FindLastAccrualDate(ctx, invoice.ID)
+		if err != nil {
+			return err
+		}
+
+		if last != nil {
+			day = dateOf(*last, loc).AddDate(0, 0, 1)
+		}
+
+		end := billing.NextClosingDate(invoice.PeriodEnd.In(loc), acc.ClosingDay)
+		if today.Before(end) {
+			end = today
+		}
+
+		if !day.Before(end) {
+			return nil
+		}
+
+		paidByDue, err := s.billingService.PaidAmount(ctx, invoice, firstDay)
+		if err != nil {
+			return err
+		}
+
+		late := paidByDue.LessThan(invoice.MinimumPayment)
+		overdue := invoice.Total.Sub(paidByDue)
+
+		for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+			paid, err := s.billingService.PaidAmount(ctx, invoice, day.AddDate(0, 0, 1))
+			if err != nil {
+				return err
+			}
+
+			unpaid := invoice.Total.Sub(paid)
+			if !unpaid.IsPositive() {
+				break
+			}
+
+			for _, accrual := range accruals(day, unpaid, overdue, late, day.Equal(firstDay), rates) {
+				t := &transaction.Transaction{
+					AccountID:       invoice.AccountID,
+					OperationTypeID: accrual.OperationTypeID,
+					Amount:          accrual.Amount,
+					OperationDate:   day,
+				}
+
+				if err = s.transactionService.Charge(ctx, t); err != nil {
+					return err
+				}
+
+				accrual.InvoiceID = invoice.ID
+				accrual.AccountID = invoice.AccountID
+				accrual.TransactionID = t.ID
+
+				if err = s.repository.CreateAccrual(ctx, &accrual); err != nil {
+					return err
+				}
+
+				posted++
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return posted, nil
+}
@@ -0,0 +1,352 @@
+package fee
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/billing"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"github.com/supwr/pismo-transactions/internal/ledger"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"github.com/supwr/pismo-transactions/internal/testutil"
+	"github.com/supwr/pismo-transactions/internal/transaction"
+	clockmock "github.com/supwr/pismo-transactions/pkg/clock/mock"
+	dbmock "github.com/supwr/pismo-transactions/pkg/database/mock"
+	"testing"
+	"time"
+)
+
+func TestService_Accrue(t *testing.T) {
+	now := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+	since := time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	acc := &account.Account{ID: 1, Status: account.StatusActive, ClosingDay: 3, DueDay: 10}
+	invoice := billing.Invoice{
+		ID:             5,
+		AccountID:      1,
+		PeriodEnd:      time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC),
+		DueDate:        time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+		Total:          decimal.NewFromInt(1000),
+		MinimumPayment: decimal.NewFromInt(150),
+		Status:         billing.StatusOverdue,
+	}
+
+	t.Run("accrue fees of an unpaid invoice until the next one closes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		billingRepo := billing.NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := transaction.NewMockRepositoryInterface(ctrl)
+		ledgerRepo := ledger.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		totals := map[int]decimal.Decimal{}
+
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().FindRates(gomock.Any()).Return(testRates, nil).Times(1)
+		repo.EXPECT().FindOverdueInvoices(gomock.Any(), since, today).Return([]billing.Invoice{invoice}, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(44)
+		repo.EXPECT().FindLastAccrualDate(gomock.Any(), 5).Return(nil, nil).Times(1)
+		billingRepo.EXPECT().SumCredits(gomock.Any(), 1, invoice.PeriodEnd, gomock.Any()).Return(decimal.Zero, nil).Times(22)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(44)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(43)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).Times(43)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, charge *transaction.Transaction) error {
+			totals[charge.OperationTypeID] = totals[charge.OperationTypeID].Add(charge.Amount)
+			return nil
+		}).Times(43)
+		ledgerRepo.EXPECT().CreateEntry(gomock.Any(), gomock.Any()).Return(nil).Times(43)
+		repo.EXPECT().CreateAccrual(gomock.Any(), gomock.Any()).Return(nil).Times(43)
+
+		operationTypeService := testutil.NewOperationTypeService(ctrl, operationTypes...)
+		installmentService := installment.NewService(installment.NewMockRepositoryInterface(ctrl))
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		billingService := billing.NewService(billingRepo, accountService, installmentService, nil, nil, clockMock, txManager)
+		transactionService := transaction.NewService(transactionRepo, accountService, installmentService, operationTypeService, ledger.NewService(ledgerRepo), testutil.NewOutboxService(ctrl), transaction.NewMetrics(prometheus.NewRegistry()), clockMock, txManager)
+		service := NewService(repo, accountService, billingService, operationTypeService, transactionService, clockMock, txManager)
+
+		posted, err := service.Accrue(ctx)
+
+		// from Feb 11 to Mar 2: one late fee and 21 days of revolving and late interest, the revolving rate
+		// going from 14% to 12% a month on Mar 1
+		assert.Nil(t, err)
+		assert.Equal(t, 43, posted)
+		assert.Equal(t, "-20", totals[7].String())
+		assert.Equal(t, "-96.73", totals[6].String())
+		assert.Equal(t, "-6.93", totals[8].String())
+	})
+
+	t.Run("stop accruing once the invoice is paid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		billingRepo := billing.NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := transaction.NewMockRepositoryInterface(ctrl)
+		ledgerRepo := ledger.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		totals := map[int]decimal.Decimal{}
+		paidOn := time.Date(2024, 2, 20, 15, 0, 0, 0, time.UTC)
+
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().FindRates(gomock.Any()).Return(testRates, nil).Times(1)
+		repo.EXPECT().FindOverdueInvoices(gomock.Any(), since, today).Return([]billing.Invoice{invoice}, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(20)
+		repo.EXPECT().FindLastAccrualDate(gomock.Any(), 5).Return(nil, nil).Times(1)
+		billingRepo.EXPECT().SumCredits(gomock.Any(), 1, invoice.PeriodEnd, gomock.Any()).DoAndReturn(func(_ context.Context, _ int, _ time.Time, until time.Time) (decimal.Decimal, error) {
+			if until.After(paidOn) {
+				return decimal.NewFromInt(1000), nil
+			}
+
+			return decimal.Zero, nil
+		}).Times(11)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(20)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(19)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).Times(19)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, charge *transaction.Transaction) error {
+			totals[charge.OperationTypeID] = totals[charge.OperationTypeID].Add(charge.Amount)
+			return nil
+		}).Times(19)
+		ledgerRepo.EXPECT().CreateEntry(gomock.Any(), gomock.Any()).Return(nil).Times(19)
+		repo.EXPECT().CreateAccrual(gomock.Any(), gomock.Any()).Return(nil).Times(19)
+
+		operationTypeService := testutil.NewOperationTypeService(ctrl, operationTypes...)
+		installmentService := installment.NewService(installment.NewMockRepositoryInterface(ctrl))
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		billingService := billing.NewService(billingRepo, accountService, installmentService, nil, nil, clockMock, txManager)
+		transactionService := transaction.NewService(transactionRepo, accountService, installmentService, operationTypeService, ledger.NewService(ledgerRepo), testutil.NewOutboxService(ctrl), transaction.NewMetrics(prometheus.NewRegistry()), clockMock, txManager)
+		service := NewService(repo, accountService, billingService, operationTypeService, transactionService, clockMock, txManager)
+
+		posted, err := service.Accrue(ctx)
+
+		// from Feb 11 to Feb 19
+		assert.Nil(t, err)
+		assert.Equal(t, 19, posted)
+		assert.Equal(t, "-20", totals[7].String())
+		assert.Equal(t, "-42.03", totals[6].String())
+		assert.Equal(t, "-2.97", totals[8].String())
+	})
+
+	t.Run("minimum payment made by the due date", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		billingRepo := billing.NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := transaction.NewMockRepositoryInterface(ctrl)
+		ledgerRepo := ledger.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		totals := map[int]decimal.Decimal{}
+
+		clockMock.EXPECT().Now().Return(time.Date(2024, 2, 13, 1, 0, 0, 0, time.UTC)).Times(1)
+		repo.EXPECT().FindRates(gomock.Any()).Return(testRates, nil).Times(1)
+		repo.EXPECT().FindOverdueInvoices(gomock.Any(), gomock.Any(), gomock.Any()).Return([]billing.Invoice{invoice}, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(3)
+		repo.EXPECT().FindLastAccrualDate(gomock.Any(), 5).Return(nil, nil).Times(1)
+		billingRepo.EXPECT().SumCredits(gomock.Any(), 1, invoice.PeriodEnd, gomock.Any()).Return(decimal.NewFromInt(200), nil).Times(3)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(3)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(2)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, charge *transaction.Transaction) error {
+			totals[charge.OperationTypeID] = totals[charge.OperationTypeID].Add(charge.Amount)
+			return nil
+		}).Times(2)
+		ledgerRepo.EXPECT().CreateEntry(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		repo.EXPECT().CreateAccrual(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+		operationTypeService := testutil.NewOperationTypeService(ctrl, operationTypes...)
+		installmentService := installment.NewService(installment.NewMockRepositoryInterface(ctrl))
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		billingService := billing.NewService(billingRepo, accountService, installmentService, nil, nil, clockMock, txManager)
+		transactionService := transaction.NewService(transactionRepo, accountService, installmentService, operationTypeService, ledger.NewService(ledgerRepo), testutil.NewOutboxService(ctrl), transaction.NewMetrics(prometheus.NewRegistry()), clockMock, txManager)
+		service := NewService(repo, accountService, billingService, operationTypeService, transactionService, clockMock, txManager)
+
+		posted, err := service.Accrue(ctx)
+
+		// Feb 11 and 12, only revolving interest on the 800 left
+		assert.Nil(t, err)
+		assert.Equal(t, 2, posted)
+		assert.Equal(t, "-7.46", totals[6].String())
+		assert.True(t, totals[7].IsZero())
+		assert.True(t, totals[8].IsZero())
+	})
+
+	t.Run("pick up after the last accrual", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		billingRepo := billing.NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := transaction.NewMockRepositoryInterface(ctrl)
+		ledgerRepo := ledger.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		totals := map[int]decimal.Decimal{}
+		last := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().FindRates(gomock.Any()).Return(testRates, nil).Times(1)
+		repo.EXPECT().FindOverdueInvoices(gomock.Any(), since, today).Return([]billing.Invoice{invoice}, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(3)
+		repo.EXPECT().FindLastAccrualDate(gomock.Any(), 5).Return(&last, nil).Times(1)
+		billingRepo.EXPECT().SumCredits(gomock.Any(), 1, invoice.PeriodEnd, gomock.Any()).Return(decimal.Zero, nil).Times(2)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(3)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(2)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, charge *transaction.Transaction) error {
+			assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), charge.OperationDate)
+			totals[charge.OperationTypeID] = totals[charge.OperationTypeID].Add(charge.Amount)
+			return nil
+		}).Times(2)
+		ledgerRepo.EXPECT().CreateEntry(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		repo.EXPECT().CreateAccrual(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+		operationTypeService := testutil.NewOperationTypeService(ctrl, operationTypes...)
+		installmentService := installment.NewService(installment.NewMockRepositoryInterface(ctrl))
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		billingService := billing.NewService(billingRepo, accountService, installmentService, nil, nil, clockMock, txManager)
+		transactionService := transaction.NewService(transactionRepo, accountService, installmentService, operationTypeService, ledger.NewService(ledgerRepo), testutil.NewOutboxService(ctrl), transaction.NewMetrics(prometheus.NewRegistry()), clockMock, txManager)
+		service := NewService(repo, accountService, billingService, operationTypeService, transactionService, clockMock, txManager)
+
+		posted, err := service.Accrue(ctx)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, posted)
+		assert.Equal(t, "-4", totals[6].String())
+		assert.Equal(t, "-0.33", totals[8].String())
+	})
+
+	t.Run("nothing left to accrue", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		billingRepo := billing.NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := transaction.NewMockRepositoryInterface(ctrl)
+		ledgerRepo := ledger.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		last := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().FindRates(gomock.Any()).Return(testRates, nil).Times(1)
+		repo.EXPECT().FindOverdueInvoices(gomock.Any(), since, today).Return([]billing.Invoice{invoice}, nil).Times(1)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		repo.EXPECT().FindLastAccrualDate(gomock.Any(), 5).Return(&last, nil).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+		operationTypeService := testutil.NewOperationTypeService(ctrl, operationTypes...)
+		installmentService := installment.NewService(installment.NewMockRepositoryInterface(ctrl))
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		billingService := billing.NewService(billingRepo, accountService, installmentService, nil, nil, clockMock, txManager)
+		transactionService := transaction.NewService(transactionRepo, accountService, installmentService, operationTypeService, ledger.NewService(ledgerRepo), testutil.NewOutboxService(ctrl), transaction.NewMetrics(prometheus.NewRegistry()), clockMock, txManager)
+		service := NewService(repo, accountService, billingService, operationTypeService, transactionService, clockMock, txManager)
+
+		posted, err := service.Accrue(ctx)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, posted)
+	})
+
+	t.Run("an invoice failing doesn't stop the others", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		billingRepo := billing.NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := transaction.NewMockRepositoryInterface(ctrl)
+		ledgerRepo := ledger.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		expectedErr := errors.New("database error")
+		other := invoice
+		other.ID, other.AccountID = 6, 2
+
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().FindRates(gomock.Any()).Return(testRates, nil).Times(1)
+		repo.EXPECT().FindOverdueInvoices(gomock.Any(), since, today).Return([]billing.Invoice{invoice, other}, nil).Times(1)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(2)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(nil, expectedErr).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 2).Return(&account.Account{ID: 2, Status: account.StatusClosed}, nil).Times(1)
+
+		operationTypeService := testutil.NewOperationTypeService(ctrl, operationTypes...)
+		installmentService := installment.NewService(installment.NewMockRepositoryInterface(ctrl))
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		billingService := billing.NewService(billingRepo, accountService, installmentService, nil, nil, clockMock, txManager)
+		transactionService := transaction.NewService(transactionRepo, accountService, installmentService, operationTypeService, ledger.NewService(ledgerRepo), testutil.NewOutboxService(ctrl), transaction.NewMetrics(prometheus.NewRegistry()), clockMock, txManager)
+		service := NewService(repo, accountService, billingService, operationTypeService, transactionService, clockMock, txManager)
+
+		posted, err := service.Accrue(ctx)
+
+		assert.Equal(t, 0, posted)
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestService_CreateRate(t *testing.T) {
+	t.Run("keep the operation type of the current rate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+		rate := &Rate{Kind: KindLateFee, Rate: decimal.NewFromFloat(0.02), ValidFrom: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+
+		repo.EXPECT().FindRates(gomock.Any()).Return(testRates, nil).Times(1)
+		repo.EXPECT().CreateRate(gomock.Any(), rate).Return(nil).Times(1)
+
+		service := NewService(repo, nil, nil, testutil.NewOperationTypeService(ctrl, operationTypes...), nil, nil, nil)
+		err := service.CreateRate(ctx, rate)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 7, rate.OperationTypeID)
+	})
+
+	t.Run("invalid rates", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+		validFrom := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+		repo.EXPECT().FindRates(gomock.Any()).Return(nil, nil).Times(1)
+		repo.EXPECT().CreateRate(gomock.Any(), gomock.Any()).Times(0)
+
+		service := NewService(repo, nil, nil, testutil.NewOperationTypeService(ctrl, operationTypes...), nil, nil, nil)
+
+		assert.ErrorIs(t, service.CreateRate(ctx, &Rate{Kind: "TARIFA", Rate: decimal.NewFromInt(1)}), ErrInvalidKind)
+		assert.ErrorIs(t, service.CreateRate(ctx, &Rate{Kind: KindLateFee, Rate: decimal.NewFromInt(-1)}), ErrInvalidRate)
+		assert.ErrorIs(t, service.CreateRate(ctx, &Rate{Kind: KindLateFee, Rate: decimal.NewFromInt(1), ValidFrom: validFrom}), ErrOperationTypeRequired)
+	})
+
+	t.Run("operation type must be an active internal debit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().CreateRate(gomock.Any(), gomock.Any()).Times(0)
+
+		service := NewService(repo, nil, nil, testutil.NewOperationTypeService(ctrl, operationTypes...), nil, nil, nil)
+
+		// a purchase, a reversal, a deactivated fee and one that doesn't exist
+		assert.ErrorIs(t, service.CreateRate(ctx, &Rate{Kind: KindLateFee, Rate: decimal.NewFromInt(1), OperationTypeID: 1}), ErrOperationTypeNotAllowed)
+		assert.ErrorIs(t, service.CreateRate(ctx, &Rate{Kind: KindLateFee, Rate: decimal.NewFromInt(1), OperationTypeID: 5}), ErrOperationTypeNotAllowed)
+		assert.ErrorIs(t, service.CreateRate(ctx, &Rate{Kind: KindLateFee, Rate: decimal.NewFromInt(1), OperationTypeID: 9}), ErrOperationTypeNotFound)
+		assert.ErrorIs(t, service.CreateRate(ctx, &Rate{Kind: KindLateFee, Rate: decimal.NewFromInt(1), OperationTypeID: 15}), ErrOperationTypeNotFound)
+	})
+}
+
+// operationTypes are the built-in purchase and reversal along with the fees' internal debits.
+var operationTypes = []operationtype.OperationType{
+	{ID: 1, Description: "COMPRA A VISTA", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true},
+	{ID: 5, Description: "ESTORNO", Direction: operationtype.DirectionCredit, ConsumesCreditLimit: true, Active: true, Internal: true},
+	{ID: 6, Description: "JUROS ROTATIVO", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true, Internal: true},
+	{ID: 7, Description: "MULTA POR ATRASO", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true, Internal: true},
+	{ID: 8, Description: "JUROS DE MORA", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true, Internal: true},
+	{ID: 9, Description: "TARIFA ANUAL", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: false, Internal: true},
+}
@@ -0,0 +1,18 @@
+package idempotency
+
+import (
+	"time"
+)
+
+type IdempotencyKey struct {
+	Key            string    `json:"key" gorm:"primaryKey"`
+	RequestHash    string    `json:"request_hash"`
+	ResponseStatus int       `json:"response_status"`
+	ResponseBody   []byte    `json:"response_body"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
@@ -0,0 +1,8 @@
+package idempotency
+
+import "errors"
+
+var (
+	ErrRequestMismatch = errors.New("Idempotency key already used with a different request")
+	ErrKeyTooLong      = errors.New("Idempotency key must have at most 255 characters")
+)
@@ -0,0 +1,11 @@
+//go:generate mockgen -destination=mock.go -source=interface.go -package=idempotency
+package idempotency
+
+import (
+	"context"
+)
+
+type RepositoryInterface interface {
+	Create(ctx context.Context, key *IdempotencyKey) error
+	FindByKey(ctx context.Context, key string) (*IdempotencyKey, error)
+}
@@ -0,0 +1,40 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+type Repository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewRepository(db *gorm.DB, logger *slog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *Repository) Create(ctx context.Context, key *IdempotencyKey) error {
+	return database.Conn(ctx, r.db).Create(key).Error
+}
+
+func (r *Repository) FindByKey(ctx context.Context, key string) (*IdempotencyKey, error) {
+	var idempotencyKey *IdempotencyKey
+
+	if err := database.Conn(ctx, r.db).First(&idempotencyKey, "key = ?", key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		r.logger.ErrorContext(ctx, "error finding idempotency key", slog.Any("error", err))
+		return nil, err
+	}
+
+	return idempotencyKey, nil
+}
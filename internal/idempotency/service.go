@@ -0,0 +1,85 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"gorm.io/gorm"
+)
+
+const maxKeyLength = 255
+
+type Service struct {
+	repository RepositoryInterface
+	txManager  database.TxManager
+}
+
+func NewService(r RepositoryInterface, tm database.TxManager) *Service {
+	return &Service{repository: r, txManager: tm}
+}
+
+// Hash fingerprints a request payload so replays can be told apart from a different request reusing the same key.
+func Hash(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// Execute replays the response stored under key or, when the key is new, runs fn and stores its response in the
+// same unit of work as the changes fn makes. Failed executions aren't stored, so they can be retried with the same key.
+func (s *Service) Execute(ctx context.Context, key string, requestHash string, fn func(ctx context.Context) (*Response, error)) (*Response, error) {
+	if len(key) > maxKeyLength {
+		return nil, ErrKeyTooLong
+	}
+
+	stored, err := s.replay(ctx, key, requestHash)
+	if err != nil || stored != nil {
+		return stored, err
+	}
+
+	var response *Response
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if response, err = fn(ctx); err != nil {
+			return err
+		}
+
+		return s.repository.Create(ctx, &IdempotencyKey{
+			Key:            key,
+			RequestHash:    requestHash,
+			ResponseStatus: response.StatusCode,
+			ResponseBody:   response.Body,
+		})
+	})
+
+	// a concurrent request with the same key committed first, so everything done here was rolled back
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		if stored, replayErr := s.replay(ctx, key, requestHash); replayErr != nil || stored != nil {
+			return stored, replayErr
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *Service) replay(ctx context.Context, key string, requestHash string) (*Response, error) {
+	stored, err := s.repository.FindByKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored == nil {
+		return nil, nil
+	}
+
+	if stored.RequestHash != requestHash {
+		return nil, ErrRequestMismatch
+	}
+
+	return &Response{StatusCode: stored.ResponseStatus, Body: stored.ResponseBody}, nil
+}
@@ -0,0 +1,157 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/supwr/pismo-transactions/internal/testutil"
+	dbmock "github.com/supwr/pismo-transactions/pkg/database/mock"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+)
+
+func TestService_Execute(t *testing.T) {
+	t.Run("execute and store response for a new key", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		response := &Response{StatusCode: 201, Body: []byte(`{"transaction_id":1}`)}
+
+		findByKey := repo.EXPECT().FindByKey(ctx, "key").Return(nil, nil).Times(1)
+		txManager.EXPECT().WithinTransaction(ctx, gomock.Any()).DoAndReturn(testutil.RunInTransaction).After(findByKey).Times(1)
+		repo.EXPECT().Create(ctx, &IdempotencyKey{
+			Key:            "key",
+			RequestHash:    "hash",
+			ResponseStatus: response.StatusCode,
+			ResponseBody:   response.Body,
+		}).Return(nil).Times(1)
+
+		service := NewService(repo, txManager)
+		r, err := service.Execute(ctx, "key", "hash", func(ctx context.Context) (*Response, error) {
+			return response, nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, response, r)
+	})
+
+	t.Run("replay stored response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindByKey(ctx, "key").Return(&IdempotencyKey{
+			Key:            "key",
+			RequestHash:    "hash",
+			ResponseStatus: 201,
+			ResponseBody:   []byte(`{"transaction_id":1}`),
+		}, nil).Times(1)
+
+		service := NewService(repo, txManager)
+		r, err := service.Execute(ctx, "key", "hash", func(ctx context.Context) (*Response, error) {
+			t.Fatal("request should not be executed again")
+			return nil, nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, &Response{StatusCode: 201, Body: []byte(`{"transaction_id":1}`)}, r)
+	})
+
+	t.Run("key reused with a different request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindByKey(ctx, "key").Return(&IdempotencyKey{
+			Key:            "key",
+			RequestHash:    "other hash",
+			ResponseStatus: 201,
+			ResponseBody:   []byte(`{"transaction_id":1}`),
+		}, nil).Times(1)
+
+		service := NewService(repo, txManager)
+		r, err := service.Execute(ctx, "key", "hash", func(ctx context.Context) (*Response, error) {
+			t.Fatal("request should not be executed")
+			return nil, nil
+		})
+
+		assert.Nil(t, r)
+		assert.ErrorIs(t, err, ErrRequestMismatch)
+	})
+
+	t.Run("failed execution is not stored", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		expectedErr := errors.New("insuficient funds")
+		ctx := context.Background()
+
+		repo.EXPECT().FindByKey(ctx, "key").Return(nil, nil).Times(1)
+		txManager.EXPECT().WithinTransaction(ctx, gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+		service := NewService(repo, txManager)
+		r, err := service.Execute(ctx, "key", "hash", func(ctx context.Context) (*Response, error) {
+			return nil, expectedErr
+		})
+
+		assert.Nil(t, r)
+		assert.ErrorIs(t, err, expectedErr)
+	})
+
+	t.Run("replay response of a concurrent request that committed first", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		stored := &IdempotencyKey{
+			Key:            "key",
+			RequestHash:    "hash",
+			ResponseStatus: 201,
+			ResponseBody:   []byte(`{"transaction_id":1}`),
+		}
+
+		first := repo.EXPECT().FindByKey(ctx, "key").Return(nil, nil).Times(1)
+		txManager.EXPECT().WithinTransaction(ctx, gomock.Any()).DoAndReturn(testutil.RunInTransaction).After(first).Times(1)
+		create := repo.EXPECT().Create(ctx, gomock.Any()).Return(gorm.ErrDuplicatedKey).Times(1)
+		repo.EXPECT().FindByKey(ctx, "key").Return(stored, nil).After(create).Times(1)
+
+		service := NewService(repo, txManager)
+		r, err := service.Execute(ctx, "key", "hash", func(ctx context.Context) (*Response, error) {
+			return &Response{StatusCode: 201, Body: []byte(`{"transaction_id":2}`)}, nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, &Response{StatusCode: 201, Body: []byte(`{"transaction_id":1}`)}, r)
+	})
+
+	t.Run("key too long", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		service := NewService(repo, txManager)
+		r, err := service.Execute(ctx, strings.Repeat("k", 256), "hash", func(ctx context.Context) (*Response, error) {
+			t.Fatal("request should not be executed")
+			return nil, nil
+		})
+
+		assert.Nil(t, r)
+		assert.ErrorIs(t, err, ErrKeyTooLong)
+	})
+}
+
+func TestHash(t *testing.T) {
+	t.Run("same payload same hash", func(t *testing.T) {
+		assert.Equal(t, Hash([]byte(`{"amount":10}`)), Hash([]byte(`{"amount":10}`)))
+		assert.NotEqual(t, Hash([]byte(`{"amount":10}`)), Hash([]byte(`{"amount":11}`)))
+	})
+}
@@ -0,0 +1,42 @@
+package installment
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	StatusScheduled = "SCHEDULED"
+	StatusBilled    = "BILLED"
+	StatusPaid      = "PAID"
+	StatusCancelled = "CANCELLED"
+)
+
+const MaxInstallments = 24
+
+type InstallmentPlan struct {
+	ID               int             `json:"id" gorm:"primaryKey"`
+	TransactionID    int             `json:"transaction_id"`
+	AccountID        int             `json:"account_id"`
+	Principal        decimal.Decimal `json:"principal"`
+	InterestRate     decimal.Decimal `json:"interest_rate"`
+	InstallmentCount int             `json:"installment_count"`
+	Total            decimal.Decimal `json:"total"`
+	Installments     []Installment   `json:"installments"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        *time.Time      `json:"updated_at"`
+}
+
+type Installment struct {
+	ID                int             `json:"id" gorm:"primaryKey"`
+	InstallmentPlanID int             `json:"installment_plan_id"`
+	Number            int             `json:"number"`
+	Amount            decimal.Decimal `json:"amount"`
+	// Interest is the part of Amount that isn't principal. It's booked as a transaction when the installment is billed.
+	Interest              decimal.Decimal `json:"interest"`
+	InterestTransactionID *int            `json:"interest_transaction_id"`
+	DueDate               time.Time       `json:"due_date"`
+	Status                string          `json:"status"`
+	CreatedAt             time.Time       `json:"created_at"`
+	UpdatedAt             *time.Time      `json:"updated_at"`
+}
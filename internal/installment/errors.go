@@ -0,0 +1,8 @@
+package installment
+
+import "errors"
+
+var (
+	ErrInvalidInstallmentCount = errors.New("Installment count must be between 1 and 24")
+	ErrInvalidInterestRate     = errors.New("Interest rate must not be negative")
+)
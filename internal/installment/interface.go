@@ -0,0 +1,14 @@
+//go:generate mockgen -destination=mock.go -source=interface.go -package=installment
+package installment
+
+import (
+	"context"
+)
+
+type RepositoryInterface interface {
+	CreatePlan(ctx context.Context, plan *InstallmentPlan) error
+	FindPlanByTransaction(ctx context.Context, transactionID int) (*InstallmentPlan, error)
+	CancelScheduledInstallments(ctx context.Context, transactionID int) error
+	UpdateInstallmentsStatus(ctx context.Context, ids []int, status string) error
+	UpdateInterestTransaction(ctx context.Context, id int, transactionID int) error
+}
@@ -0,0 +1,106 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interface.go
+
+// Package installment is a generated GoMock package.
+package installment
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRepositoryInterface is a mock of RepositoryInterface interface.
+type MockRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryInterfaceMockRecorder
+}
+
+// MockRepositoryInterfaceMockRecorder is the mock recorder for MockRepositoryInterface.
+type MockRepositoryInterfaceMockRecorder struct {
+	mock *MockRepositoryInterface
+}
+
+// NewMockRepositoryInterface creates a new mock instance.
+func NewMockRepositoryInterface(ctrl *gomock.Controller) *MockRepositoryInterface {
+	mock := &MockRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CancelScheduledInstallments mocks base method.
+func (m *MockRepositoryInterface) CancelScheduledInstallments(ctx context.Context, transactionID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduledInstallments", ctx, transactionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelScheduledInstallments indicates an expected call of CancelScheduledInstallments.
+func (mr *MockRepositoryInterfaceMockRecorder) CancelScheduledInstallments(ctx, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledInstallments", reflect.TypeOf((*MockRepositoryInterface)(nil).CancelScheduledInstallments), ctx, transactionID)
+}
+
+// CreatePlan mocks base method.
+func (m *MockRepositoryInterface) CreatePlan(ctx context.Context, plan *InstallmentPlan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePlan", ctx, plan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePlan indicates an expected call of CreatePlan.
+func (mr *MockRepositoryInterfaceMockRecorder) CreatePlan(ctx, plan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePlan", reflect.TypeOf((*MockRepositoryInterface)(nil).CreatePlan), ctx, plan)
+}
+
+// FindPlanByTransaction mocks base method.
+func (m *MockRepositoryInterface) FindPlanByTransaction(ctx context.Context, transactionID int) (*InstallmentPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPlanByTransaction", ctx, transactionID)
+	ret0, _ := ret[0].(*InstallmentPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPlanByTransaction indicates an expected call of FindPlanByTransaction.
+func (mr *MockRepositoryInterfaceMockRecorder) FindPlanByTransaction(ctx, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPlanByTransaction", reflect.TypeOf((*MockRepositoryInterface)(nil).FindPlanByTransaction), ctx, transactionID)
+}
+
+// UpdateInstallmentsStatus mocks base method.
+func (m *MockRepositoryInterface) UpdateInstallmentsStatus(ctx context.Context, ids []int, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInstallmentsStatus", ctx, ids, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateInstallmentsStatus indicates an expected call of UpdateInstallmentsStatus.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateInstallmentsStatus(ctx, ids, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInstallmentsStatus", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateInstallmentsStatus), ctx, ids, status)
+}
+
+// UpdateInterestTransaction mocks base method.
+func (m *MockRepositoryInterface) UpdateInterestTransaction(ctx context.Context, id, transactionID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInterestTransaction", ctx, id, transactionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateInterestTransaction indicates an expected call of UpdateInterestTransaction.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateInterestTransaction(ctx, id, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInterestTransaction", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateInterestTransaction), ctx, id, transactionID)
+}
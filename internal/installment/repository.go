@@ -0,0 +1,70 @@
+package installment
+
+import (
+	"context"
+	"errors"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+type Repository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewRepository(db *gorm.DB, logger *slog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreatePlan stores the plan along with its installments.
+func (r *Repository) CreatePlan(ctx context.Context, plan *InstallmentPlan) error {
+	return database.Conn(ctx, r.db).Create(plan).Error
+}
+
+func (r *Repository) FindPlanByTransaction(ctx context.Context, transactionID int) (*InstallmentPlan, error) {
+	var plan *InstallmentPlan
+
+	err := database.Conn(ctx, r.db).
+		Preload("Installments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("number")
+		}).
+		First(&plan, "transaction_id = ?", transactionID).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		r.logger.ErrorContext(ctx, "error finding installment plan", slog.Any("error", err))
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// CancelScheduledInstallments cancels the installments of the transaction's plan that weren't billed yet.
+func (r *Repository) CancelScheduledInstallments(ctx context.Context, transactionID int) error {
+	plans := database.Conn(ctx, r.db).Model(&InstallmentPlan{}).Select("id").Where("transaction_id = ?", transactionID)
+
+	return database.Conn(ctx, r.db).
+		Model(&Installment{}).
+		Where("installment_plan_id in (?) and status = ?", plans, StatusScheduled).
+		Update("status", StatusCancelled).Error
+}
+
+func (r *Repository) UpdateInstallmentsStatus(ctx context.Context, ids []int, status string) error {
+	return database.Conn(ctx, r.db).
+		Model(&Installment{}).
+		Where("id in ?", ids).
+		Update("status", status).Error
+}
+
+func (r *Repository) UpdateInterestTransaction(ctx context.Context, id int, transactionID int) error {
+	return database.Conn(ctx, r.db).
+		Model(&Installment{ID: id}).
+		Update("interest_transaction_id", transactionID).Error
+}
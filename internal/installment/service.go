@@ -0,0 +1,147 @@
+package installment
+
+import (
+	"context"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type Service struct {
+	repository RepositoryInterface
+}
+
+func NewService(r RepositoryInterface) *Service {
+	return &Service{repository: r}
+}
+
+func (s *Service) FindPlanByTransaction(ctx context.Context, transactionID int) (*InstallmentPlan, error) {
+	return s.repository.FindPlanByTransaction(ctx, transactionID)
+}
+
+// CancelPlan cancels the installments of a fully reversed installment buy that weren't billed yet.
+func (s *Service) CancelPlan(ctx context.Context, transactionID int) error {
+	return s.repository.CancelScheduledInstallments(ctx, transactionID)
+}
+
+// MarkBilled flags the installments as billed once they are on an invoice.
+func (s *Service) MarkBilled(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return s.repository.UpdateInstallmentsStatus(ctx, ids, StatusBilled)
+}
+
+// MarkPaid flags billed installments as paid once their invoice is.
+func (s *Service) MarkPaid(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return s.repository.UpdateInstallmentsStatus(ctx, ids, StatusPaid)
+}
+
+// SetInterestTransaction links the installment to the transaction its interest was booked with.
+func (s *Service) SetInterestTransaction(ctx context.Context, id int, transactionID int) error {
+	return s.repository.UpdateInterestTransaction(ctx, id, transactionID)
+}
+
+// CreatePlan splits the principal of an installment buy made at purchaseDate into count monthly installments,
+// the first one due a month after the purchase. The interest rate is monthly, e.g. 0.0199 for 1.99% a month.
+func (s *Service) CreatePlan(ctx context.Context, transactionID int, accountID int, principal decimal.Decimal, count int, interestRate decimal.Decimal, purchaseDate time.Time) (*InstallmentPlan, error) {
+	if err := Validate(count, interestRate); err != nil {
+		return nil, err
+	}
+
+	plan := &InstallmentPlan{
+		TransactionID:    transactionID,
+		AccountID:        accountID,
+		Principal:        principal,
+		InterestRate:     interestRate,
+		InstallmentCount: count,
+		Total:            decimal.Zero,
+	}
+
+	values, interests := amounts(principal, count, interestRate)
+
+	for i, amount := range values {
+		plan.Total = plan.Total.Add(amount)
+		plan.Installments = append(plan.Installments, Installment{
+			Number:   i + 1,
+			Amount:   amount,
+			Interest: interests[i],
+			DueDate:  addMonths(purchaseDate, i+1),
+			Status:   StatusScheduled,
+		})
+	}
+
+	if err := s.repository.CreatePlan(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func Validate(count int, interestRate decimal.Decimal) error {
+	if count < 1 || count > MaxInstallments {
+		return ErrInvalidInstallmentCount
+	}
+
+	if interestRate.IsNegative() {
+		return ErrInvalidInterestRate
+	}
+
+	return nil
+}
+
+// amounts returns the value of each installment and the interest in it. Without interest the principal is split
+// evenly and the rounding difference goes to the last installment; with interest every installment is the fixed
+// payment of the price table, PMT = P * r / (1 - (1 + r)^-n), and its interest is the rate over the principal still
+// owed. The last installment's interest takes the rounding difference, so the principal of all of them adds up to P.
+func amounts(principal decimal.Decimal, count int, interestRate decimal.Decimal) ([]decimal.Decimal, []decimal.Decimal) {
+	values := make([]decimal.Decimal, count)
+	interests := make([]decimal.Decimal, count)
+	n := decimal.NewFromInt(int64(count))
+
+	if interestRate.IsZero() {
+		value := principal.Div(n).RoundDown(2)
+		for i := range values {
+			values[i] = value
+			interests[i] = decimal.Zero
+		}
+
+		values[count-1] = principal.Sub(value.Mul(n.Sub(decimal.NewFromInt(1))))
+		return values, interests
+	}
+
+	growth := decimal.NewFromInt(1).Add(interestRate).Pow(n)
+	payment := principal.Mul(interestRate).Mul(growth).Div(growth.Sub(decimal.NewFromInt(1))).Round(2)
+	owed := principal
+
+	for i := range values {
+		values[i] = payment
+		interests[i] = owed.Mul(interestRate).Round(2)
+
+		if i == count-1 {
+			interests[i] = payment.Sub(owed)
+		}
+
+		owed = owed.Sub(payment.Sub(interests[i]))
+	}
+
+	return values, interests
+}
+
+// addMonths moves date forward by months, clamping to the last day of the target month instead of
+// overflowing into the next one (Jan 31 + 1 month is Feb 28/29, not Mar 2/3).
+func addMonths(date time.Time, months int) time.Time {
+	year, month, day := date.Date()
+	firstOfTarget := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, date.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), day, 0, 0, 0, 0, date.Location())
+}
@@ -0,0 +1,171 @@
+package installment
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestService_CreatePlan(t *testing.T) {
+	t.Run("create plan without interest", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+		purchaseDate := time.Date(2024, 1, 31, 15, 30, 0, 0, time.UTC)
+
+		repo.EXPECT().CreatePlan(ctx, gomock.Any()).Return(nil).Times(1)
+
+		service := NewService(repo)
+		plan, err := service.CreatePlan(ctx, 10, 1, decimal.NewFromInt(100), 3, decimal.Zero, purchaseDate)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 10, plan.TransactionID)
+		assert.Equal(t, 1, plan.AccountID)
+		assert.Equal(t, 3, plan.InstallmentCount)
+		assert.True(t, plan.Total.Equal(decimal.NewFromInt(100)))
+		assert.Len(t, plan.Installments, 3)
+
+		expected := []struct {
+			amount  string
+			dueDate time.Time
+		}{
+			{"33.33", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+			{"33.33", time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+			{"33.34", time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)},
+		}
+
+		for i, installment := range plan.Installments {
+			assert.Equal(t, i+1, installment.Number)
+			assert.Equal(t, expected[i].amount, installment.Amount.StringFixed(2))
+			assert.True(t, installment.Interest.IsZero())
+			assert.Equal(t, expected[i].dueDate, installment.DueDate)
+			assert.Equal(t, StatusScheduled, installment.Status)
+		}
+	})
+
+	t.Run("create plan with monthly interest", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().CreatePlan(ctx, gomock.Any()).Return(nil).Times(1)
+
+		service := NewService(repo)
+		plan, err := service.CreatePlan(ctx, 10, 1, decimal.NewFromInt(1000), 12, decimal.NewFromFloat(0.0199), time.Now())
+
+		assert.Nil(t, err)
+		assert.Len(t, plan.Installments, 12)
+		assert.Equal(t, "1134.00", plan.Total.StringFixed(2))
+
+		interest := decimal.Zero
+		for _, installment := range plan.Installments {
+			assert.Equal(t, "94.50", installment.Amount.StringFixed(2))
+			interest = interest.Add(installment.Interest)
+		}
+
+		// the interest falls as the principal is paid off, and the principal of the installments adds up to the purchase
+		assert.Equal(t, "19.90", plan.Installments[0].Interest.StringFixed(2))
+		assert.True(t, plan.Installments[11].Interest.LessThan(plan.Installments[10].Interest))
+		assert.Equal(t, "134.00", interest.StringFixed(2))
+	})
+
+	t.Run("invalid installment count", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		service := NewService(repo)
+
+		for _, count := range []int{0, MaxInstallments + 1} {
+			plan, err := service.CreatePlan(ctx, 10, 1, decimal.NewFromInt(100), count, decimal.Zero, time.Now())
+			assert.Nil(t, plan)
+			assert.ErrorIs(t, err, ErrInvalidInstallmentCount)
+		}
+	})
+
+	t.Run("invalid interest rate", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		service := NewService(repo)
+		plan, err := service.CreatePlan(ctx, 10, 1, decimal.NewFromInt(100), 3, decimal.NewFromFloat(-0.01), time.Now())
+
+		assert.Nil(t, plan)
+		assert.ErrorIs(t, err, ErrInvalidInterestRate)
+	})
+
+	t.Run("error creating plan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		expectedErr := errors.New("database error")
+		ctx := context.Background()
+
+		repo.EXPECT().CreatePlan(ctx, gomock.Any()).Return(expectedErr).Times(1)
+
+		service := NewService(repo)
+		plan, err := service.CreatePlan(ctx, 10, 1, decimal.NewFromInt(100), 3, decimal.Zero, time.Now())
+
+		assert.Nil(t, plan)
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestService_FindPlanByTransaction(t *testing.T) {
+	t.Run("find plan successfully", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		plan := &InstallmentPlan{ID: 1, TransactionID: 10, InstallmentCount: 2}
+
+		repo.EXPECT().FindPlanByTransaction(ctx, 10).Return(plan, nil).Times(1)
+
+		service := NewService(repo)
+		p, err := service.FindPlanByTransaction(ctx, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, plan, p)
+	})
+}
+
+func TestService_CancelPlan(t *testing.T) {
+	t.Run("cancel plan successfully", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().CancelScheduledInstallments(ctx, 10).Return(nil).Times(1)
+
+		service := NewService(repo)
+		assert.Nil(t, service.CancelPlan(ctx, 10))
+	})
+}
+
+func TestService_MarkBilled(t *testing.T) {
+	t.Run("mark installments billed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().UpdateInstallmentsStatus(ctx, []int{1, 2}, StatusBilled).Return(nil).Times(1)
+
+		service := NewService(repo)
+		assert.Nil(t, service.MarkBilled(ctx, []int{1, 2}))
+	})
+
+	t.Run("nothing to mark", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().UpdateInstallmentsStatus(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		service := NewService(repo)
+		assert.Nil(t, service.MarkBilled(ctx, nil))
+	})
+}
@@ -0,0 +1,57 @@
+package ledger
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	// LedgerReceivables holds what account holders owe for their purchases and withdraws.
+	LedgerReceivables = "RECEIVABLES"
+	// LedgerAccount holds the credit account holders have left, like the part of a payment that settled nothing.
+	LedgerAccount = "ACCOUNT"
+	// LedgerCash holds the money paid out to merchants and received from account holders.
+	LedgerCash = "CASH"
+)
+
+const (
+	DirectionDebit  = "DEBIT"
+	DirectionCredit = "CREDIT"
+)
+
+// JournalEntry groups the postings booked for a transaction. Its debits and credits always add up to the same amount.
+type JournalEntry struct {
+	ID            int       `json:"id" gorm:"primaryKey"`
+	TransactionID int       `json:"transaction_id"`
+	Postings      []Posting `json:"postings"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type Posting struct {
+	ID             int             `json:"id" gorm:"primaryKey"`
+	JournalEntryID int             `json:"journal_entry_id"`
+	AccountID      int             `json:"account_id"`
+	Ledger         string          `json:"ledger"`
+	Direction      string          `json:"direction"`
+	Amount         decimal.Decimal `json:"amount"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// LedgerTotal is the sum of an account's postings on one side of a ledger.
+type LedgerTotal struct {
+	Ledger    string
+	Direction string
+	Amount    decimal.Decimal
+}
+
+// Balance is an account's position derived from its postings.
+type Balance struct {
+	AccountID   int             `json:"account_id"`
+	Receivables decimal.Decimal `json:"receivables"`
+	Credit      decimal.Decimal `json:"credit"`
+}
+
+// Net is what the account holder has left (positive) or owes (negative).
+func (b *Balance) Net() decimal.Decimal {
+	return b.Credit.Sub(b.Receivables)
+}
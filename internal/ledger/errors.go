@@ -0,0 +1,10 @@
+package ledger
+
+import "errors"
+
+var (
+	ErrEmptyEntry       = errors.New("Journal entry must have postings")
+	ErrInvalidPosting   = errors.New("Posting must have a known ledger and direction and a positive amount")
+	ErrUnbalancedEntry  = errors.New("Journal entry debits and credits don't match")
+	ErrUnbalancedLedger = errors.New("Ledger debits and credits don't match")
+)
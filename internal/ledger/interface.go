@@ -0,0 +1,14 @@
+//go:generate mockgen -destination=mock.go -source=interface.go -package=ledger
+package ledger
+
+import (
+	"context"
+)
+
+type RepositoryInterface interface {
+	CreateEntry(ctx context.Context, entry *JournalEntry) error
+	FindEntriesByTransaction(ctx context.Context, transactionID int) ([]JournalEntry, error)
+	TotalsByAccount(ctx context.Context, accountID int) ([]LedgerTotal, error)
+	Totals(ctx context.Context) ([]LedgerTotal, error)
+	FindUnbalancedEntries(ctx context.Context) ([]int, error)
+}
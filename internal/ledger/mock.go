@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interface.go
+
+// Package ledger is a generated GoMock package.
+package ledger
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRepositoryInterface is a mock of RepositoryInterface interface.
+type MockRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryInterfaceMockRecorder
+}
+
+// MockRepositoryInterfaceMockRecorder is the mock recorder for MockRepositoryInterface.
+type MockRepositoryInterfaceMockRecorder struct {
+	mock *MockRepositoryInterface
+}
+
+// NewMockRepositoryInterface creates a new mock instance.
+func NewMockRepositoryInterface(ctrl *gomock.Controller) *MockRepositoryInterface {
+	mock := &MockRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CreateEntry mocks base method.
+func (m *MockRepositoryInterface) CreateEntry(ctx context.Context, entry *JournalEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEntry", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEntry indicates an expected call of CreateEntry.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateEntry(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateEntry), ctx, entry)
+}
+
+// FindEntriesByTransaction mocks base method.
+func (m *MockRepositoryInterface) FindEntriesByTransaction(ctx context.Context, transactionID int) ([]JournalEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEntriesByTransaction", ctx, transactionID)
+	ret0, _ := ret[0].([]JournalEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEntriesByTransaction indicates an expected call of FindEntriesByTransaction.
+func (mr *MockRepositoryInterfaceMockRecorder) FindEntriesByTransaction(ctx, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEntriesByTransaction", reflect.TypeOf((*MockRepositoryInterface)(nil).FindEntriesByTransaction), ctx, transactionID)
+}
+
+// FindUnbalancedEntries mocks base method.
+func (m *MockRepositoryInterface) FindUnbalancedEntries(ctx context.Context) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUnbalancedEntries", ctx)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUnbalancedEntries indicates an expected call of FindUnbalancedEntries.
+func (mr *MockRepositoryInterfaceMockRecorder) FindUnbalancedEntries(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUnbalancedEntries", reflect.TypeOf((*MockRepositoryInterface)(nil).FindUnbalancedEntries), ctx)
+}
+
+// Totals mocks base method.
+func (m *MockRepositoryInterface) Totals(ctx context.Context) ([]LedgerTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Totals", ctx)
+	ret0, _ := ret[0].([]LedgerTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Totals indicates an expected call of Totals.
+func (mr *MockRepositoryInterfaceMockRecorder) Totals(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Totals", reflect.TypeOf((*MockRepositoryInterface)(nil).Totals), ctx)
+}
+
+// TotalsByAccount mocks base method.
+func (m *MockRepositoryInterface) TotalsByAccount(ctx context.Context, accountID int) ([]LedgerTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TotalsByAccount", ctx, accountID)
+	ret0, _ := ret[0].([]LedgerTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TotalsByAccount indicates an expected call of TotalsByAccount.
+func (mr *MockRepositoryInterfaceMockRecorder) TotalsByAccount(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TotalsByAccount", reflect.TypeOf((*MockRepositoryInterface)(nil).TotalsByAccount), ctx, accountID)
+}
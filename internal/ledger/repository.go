@@ -0,0 +1,98 @@
+package ledger
+
+import (
+	"context"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+type Repository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewRepository(db *gorm.DB, logger *slog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateEntry stores the journal entry along with its postings.
+func (r *Repository) CreateEntry(ctx context.Context, entry *JournalEntry) error {
+	return database.Conn(ctx, r.db).Create(entry).Error
+}
+
+func (r *Repository) FindEntriesByTransaction(ctx context.Context, transactionID int) ([]JournalEntry, error) {
+	var entries []JournalEntry
+
+	err := database.Conn(ctx, r.db).
+		Preload("Postings", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Where("transaction_id = ?", transactionID).
+		Order("id").
+		Find(&entries).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding journal entries", slog.Any("error", err))
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *Repository) TotalsByAccount(ctx context.Context, accountID int) ([]LedgerTotal, error) {
+	var totals []LedgerTotal
+
+	err := database.Conn(ctx, r.db).
+		Model(&Posting{}).
+		Select("ledger, direction, sum(amount) as amount").
+		Where("account_id = ?", accountID).
+		Group("ledger, direction").
+		Scan(&totals).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error summing account postings", slog.Any("error", err))
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+func (r *Repository) Totals(ctx context.Context) ([]LedgerTotal, error) {
+	var totals []LedgerTotal
+
+	err := database.Conn(ctx, r.db).
+		Model(&Posting{}).
+		Select("ledger, direction, sum(amount) as amount").
+		Group("ledger, direction").
+		Scan(&totals).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error summing postings", slog.Any("error", err))
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// FindUnbalancedEntries returns the ids of the journal entries whose debits and credits don't match.
+func (r *Repository) FindUnbalancedEntries(ctx context.Context) ([]int, error) {
+	var ids []int
+
+	err := database.Conn(ctx, r.db).
+		Model(&Posting{}).
+		Group("journal_entry_id").
+		Having("sum(case when direction = ? then amount else -amount end) <> 0", DirectionDebit).
+		Order("journal_entry_id").
+		Pluck("journal_entry_id", &ids).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding unbalanced journal entries", slog.Any("error", err))
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,134 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"github.com/shopspring/decimal"
+)
+
+type Service struct {
+	repository RepositoryInterface
+}
+
+func NewService(r RepositoryInterface) *Service {
+	return &Service{repository: r}
+}
+
+// Record books a journal entry, refusing entries whose debits and credits don't add up to the same amount.
+// It must run in the same unit of work as the transaction it records.
+func (s *Service) Record(ctx context.Context, entry *JournalEntry) error {
+	if len(entry.Postings) == 0 {
+		return ErrEmptyEntry
+	}
+
+	debits, credits := decimal.Zero, decimal.Zero
+
+	for _, p := range entry.Postings {
+		if !p.Amount.IsPositive() || !knownLedger(p.Ledger) {
+			return ErrInvalidPosting
+		}
+
+		switch p.Direction {
+		case DirectionDebit:
+			debits = debits.Add(p.Amount)
+		case DirectionCredit:
+			credits = credits.Add(p.Amount)
+		default:
+			return ErrInvalidPosting
+		}
+	}
+
+	if !debits.Equal(credits) {
+		return ErrUnbalancedEntry
+	}
+
+	return s.repository.CreateEntry(ctx, entry)
+}
+
+func (s *Service) FindEntriesByTransaction(ctx context.Context, transactionID int) ([]JournalEntry, error) {
+	return s.repository.FindEntriesByTransaction(ctx, transactionID)
+}
+
+// Balance derives the account's position from its postings: receivables grow with debits and account credit with credits.
+func (s *Service) Balance(ctx context.Context, accountID int) (*Balance, error) {
+	totals, err := s.repository.TotalsByAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &Balance{AccountID: accountID}
+
+	for _, t := range totals {
+		switch {
+		case t.Ledger == LedgerReceivables && t.Direction == DirectionDebit:
+			balance.Receivables = balance.Receivables.Add(t.Amount)
+		case t.Ledger == LedgerReceivables && t.Direction == DirectionCredit:
+			balance.Receivables = balance.Receivables.Sub(t.Amount)
+		case t.Ledger == LedgerAccount && t.Direction == DirectionCredit:
+			balance.Credit = balance.Credit.Add(t.Amount)
+		case t.Ledger == LedgerAccount && t.Direction == DirectionDebit:
+			balance.Credit = balance.Credit.Sub(t.Amount)
+		}
+	}
+
+	return balance, nil
+}
+
+// Verify checks the double-entry invariant: every journal entry, and so the whole ledger, debits as much as it credits.
+func (s *Service) Verify(ctx context.Context) error {
+	totals, err := s.repository.Totals(ctx)
+	if err != nil {
+		return err
+	}
+
+	debits, credits := decimal.Zero, decimal.Zero
+
+	for _, t := range totals {
+		if t.Direction == DirectionDebit {
+			debits = debits.Add(t.Amount)
+		} else {
+			credits = credits.Add(t.Amount)
+		}
+	}
+
+	unbalanced, err := s.repository.FindUnbalancedEntries(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !debits.Equal(credits) || len(unbalanced) > 0 {
+		return fmt.Errorf("%w: debits %s, credits %s, unbalanced entries %v", ErrUnbalancedLedger, debits, credits, unbalanced)
+	}
+
+	return nil
+}
+
+// EntryForTransaction builds the journal entry of a transaction from its signed amount and what is left of it.
+// Purchases and withdraws become receivables paid out in cash. Payments and reversals bring cash in, settle
+// receivables with whatever they discharged, and leave the rest as the account holder's credit.
+func EntryForTransaction(transactionID int, accountID int, amount decimal.Decimal, balance decimal.Decimal) *JournalEntry {
+	entry := &JournalEntry{TransactionID: transactionID}
+
+	post := func(ledger string, direction string, amount decimal.Decimal) {
+		if amount.IsPositive() {
+			entry.Postings = append(entry.Postings, Posting{AccountID: accountID, Ledger: ledger, Direction: direction, Amount: amount})
+		}
+	}
+
+	if amount.IsNegative() {
+		post(LedgerReceivables, DirectionDebit, amount.Neg())
+		post(LedgerCash, DirectionCredit, amount.Neg())
+
+		return entry
+	}
+
+	post(LedgerCash, DirectionDebit, amount)
+	post(LedgerReceivables, DirectionCredit, amount.Sub(balance))
+	post(LedgerAccount, DirectionCredit, balance)
+
+	return entry
+}
+
+func knownLedger(ledger string) bool {
+	return ledger == LedgerReceivables || ledger == LedgerAccount || ledger == LedgerCash
+}
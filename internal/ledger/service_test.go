@@ -0,0 +1,174 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestService_Record(t *testing.T) {
+	t.Run("record balanced entry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		entry := EntryForTransaction(1, 1, decimal.NewFromInt(-50), decimal.NewFromInt(-50))
+
+		repo.EXPECT().CreateEntry(ctx, entry).Return(nil).Times(1)
+
+		service := NewService(repo)
+		assert.Nil(t, service.Record(ctx, entry))
+	})
+
+	t.Run("unbalanced entry error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().CreateEntry(gomock.Any(), gomock.Any()).Times(0)
+
+		service := NewService(repo)
+		err := service.Record(ctx, &JournalEntry{TransactionID: 1, Postings: []Posting{
+			{Ledger: LedgerCash, Direction: DirectionDebit, Amount: decimal.NewFromInt(10)},
+			{Ledger: LedgerReceivables, Direction: DirectionCredit, Amount: decimal.NewFromInt(9)},
+		}})
+
+		assert.ErrorIs(t, err, ErrUnbalancedEntry)
+	})
+
+	t.Run("invalid entries", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		service := NewService(repo)
+
+		assert.ErrorIs(t, service.Record(ctx, &JournalEntry{TransactionID: 1}), ErrEmptyEntry)
+		assert.ErrorIs(t, service.Record(ctx, &JournalEntry{TransactionID: 1, Postings: []Posting{
+			{Ledger: "BANK", Direction: DirectionDebit, Amount: decimal.NewFromInt(10)},
+		}}), ErrInvalidPosting)
+		assert.ErrorIs(t, service.Record(ctx, &JournalEntry{TransactionID: 1, Postings: []Posting{
+			{Ledger: LedgerCash, Direction: DirectionDebit, Amount: decimal.NewFromInt(-10)},
+		}}), ErrInvalidPosting)
+	})
+
+	t.Run("error creating entry", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		expectedErr := errors.New("database error")
+		ctx := context.Background()
+
+		repo.EXPECT().CreateEntry(ctx, gomock.Any()).Return(expectedErr).Times(1)
+
+		service := NewService(repo)
+		err := service.Record(ctx, EntryForTransaction(1, 1, decimal.NewFromInt(10), decimal.NewFromInt(10)))
+
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestEntryForTransaction(t *testing.T) {
+	t.Run("purchase becomes a receivable paid in cash", func(t *testing.T) {
+		entry := EntryForTransaction(1, 2, decimal.NewFromInt(-50), decimal.NewFromInt(-50))
+
+		assert.Equal(t, 1, entry.TransactionID)
+		assertPostings(t, entry, []Posting{
+			{AccountID: 2, Ledger: LedgerReceivables, Direction: DirectionDebit, Amount: decimal.NewFromInt(50)},
+			{AccountID: 2, Ledger: LedgerCash, Direction: DirectionCredit, Amount: decimal.NewFromInt(50)},
+		})
+	})
+
+	t.Run("payment settles receivables and leaves the rest as credit", func(t *testing.T) {
+		entry := EntryForTransaction(3, 2, decimal.NewFromInt(100), decimal.NewFromInt(30))
+
+		assertPostings(t, entry, []Posting{
+			{AccountID: 2, Ledger: LedgerCash, Direction: DirectionDebit, Amount: decimal.NewFromInt(100)},
+			{AccountID: 2, Ledger: LedgerReceivables, Direction: DirectionCredit, Amount: decimal.NewFromInt(70)},
+			{AccountID: 2, Ledger: LedgerAccount, Direction: DirectionCredit, Amount: decimal.NewFromInt(30)},
+		})
+	})
+
+	t.Run("payment that settles everything posts no credit", func(t *testing.T) {
+		entry := EntryForTransaction(3, 2, decimal.NewFromInt(100), decimal.Zero)
+
+		assertPostings(t, entry, []Posting{
+			{AccountID: 2, Ledger: LedgerCash, Direction: DirectionDebit, Amount: decimal.NewFromInt(100)},
+			{AccountID: 2, Ledger: LedgerReceivables, Direction: DirectionCredit, Amount: decimal.NewFromInt(100)},
+		})
+	})
+}
+
+func TestService_Balance(t *testing.T) {
+	t.Run("derive balance from postings", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().TotalsByAccount(ctx, 1).Return([]LedgerTotal{
+			{Ledger: LedgerReceivables, Direction: DirectionDebit, Amount: decimal.NewFromInt(150)},
+			{Ledger: LedgerReceivables, Direction: DirectionCredit, Amount: decimal.NewFromInt(100)},
+			{Ledger: LedgerAccount, Direction: DirectionCredit, Amount: decimal.NewFromInt(20)},
+			{Ledger: LedgerCash, Direction: DirectionDebit, Amount: decimal.NewFromInt(120)},
+			{Ledger: LedgerCash, Direction: DirectionCredit, Amount: decimal.NewFromInt(150)},
+		}, nil).Times(1)
+
+		service := NewService(repo)
+		balance, err := service.Balance(ctx, 1)
+
+		assert.Nil(t, err)
+		assert.True(t, balance.Receivables.Equal(decimal.NewFromInt(50)))
+		assert.True(t, balance.Credit.Equal(decimal.NewFromInt(20)))
+		assert.True(t, balance.Net().Equal(decimal.NewFromInt(-30)))
+	})
+}
+
+func TestService_Verify(t *testing.T) {
+	t.Run("balanced ledger", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().Totals(ctx).Return([]LedgerTotal{
+			{Ledger: LedgerReceivables, Direction: DirectionDebit, Amount: decimal.NewFromInt(150)},
+			{Ledger: LedgerCash, Direction: DirectionCredit, Amount: decimal.NewFromInt(150)},
+		}, nil).Times(1)
+		repo.EXPECT().FindUnbalancedEntries(ctx).Return(nil, nil).Times(1)
+
+		service := NewService(repo)
+		assert.Nil(t, service.Verify(ctx))
+	})
+
+	t.Run("unbalanced ledger", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().Totals(ctx).Return([]LedgerTotal{
+			{Ledger: LedgerReceivables, Direction: DirectionDebit, Amount: decimal.NewFromInt(150)},
+			{Ledger: LedgerCash, Direction: DirectionCredit, Amount: decimal.NewFromInt(140)},
+		}, nil).Times(1)
+		repo.EXPECT().FindUnbalancedEntries(ctx).Return([]int{7}, nil).Times(1)
+
+		service := NewService(repo)
+		err := service.Verify(ctx)
+
+		assert.ErrorIs(t, err, ErrUnbalancedLedger)
+		assert.Contains(t, err.Error(), "[7]")
+	})
+}
+
+func assertPostings(t *testing.T, entry *JournalEntry, expected []Posting) {
+	t.Helper()
+
+	assert.Len(t, entry.Postings, len(expected))
+
+	for i := range expected {
+		assert.Equal(t, expected[i].AccountID, entry.Postings[i].AccountID)
+		assert.Equal(t, expected[i].Ledger, entry.Postings[i].Ledger)
+		assert.Equal(t, expected[i].Direction, entry.Postings[i].Direction)
+		assert.True(t, expected[i].Amount.Equal(entry.Postings[i].Amount), "%s != %s", expected[i].Amount, entry.Postings[i].Amount)
+	}
+}
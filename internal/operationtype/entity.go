@@ -0,0 +1,51 @@
+package operationtype
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	DirectionDebit  = "DEBIT"
+	DirectionCredit = "CREDIT"
+)
+
+// CodeInstallmentInterest is the built-in type the interest of installment buys is booked with.
+const CodeInstallmentInterest = "INSTALLMENT_INTEREST"
+
+type OperationType struct {
+	ID          int    `json:"id" gorm:"primaryKey"`
+	Description string `json:"description"`
+	// Direction tells whether transactions of this type take money from the account (DEBIT) or give it back (CREDIT).
+	Direction string `json:"direction"`
+	// ConsumesCreditLimit tells whether transactions of this type move the account's available credit limit.
+	ConsumesCreditLimit bool `json:"consumes_credit_limit"`
+	Active              bool `json:"active"`
+	// Internal types are booked by the application itself, like reversals, and can't be posted by clients.
+	Internal bool `json:"internal"`
+	// BuiltIn types are seeded by the migrations, since the application books them, like reversals and fees.
+	BuiltIn bool `json:"built_in"`
+	// Code names the built-in types the application looks up, since their ids depend on the database.
+	Code      *string    `json:"code"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// IsBuiltIn tells whether the type was seeded by the migrations. Its direction and internal flag can't change,
+// since code relies on them.
+func (o *OperationType) IsBuiltIn() bool {
+	return o.BuiltIn
+}
+
+func (o *OperationType) IsDebit() bool {
+	return o.Direction == DirectionDebit
+}
+
+// SignedAmount returns amount with the sign of the operation: negative for debits, positive for credits.
+func (o *OperationType) SignedAmount(amount decimal.Decimal) decimal.Decimal {
+	if o.IsDebit() {
+		return amount.Abs().Neg()
+	}
+
+	return amount.Abs()
+}
@@ -0,0 +1,10 @@
+package operationtype
+
+import "errors"
+
+var (
+	ErrOperationTypeNotFound = errors.New("Operation Type not found")
+	ErrInvalidDirection      = errors.New("Direction must be DEBIT or CREDIT")
+	ErrInvalidDescription    = errors.New("Description is required")
+	ErrBuiltInOperationType  = errors.New("Direction and internal flag of built-in operation types can't be changed")
+)
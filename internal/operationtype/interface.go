@@ -0,0 +1,13 @@
+//go:generate mockgen -destination=mock.go -source=interface.go -package=operationtype
+package operationtype
+
+import (
+	"context"
+)
+
+type RepositoryInterface interface {
+	Create(ctx context.Context, operationType *OperationType) error
+	Update(ctx context.Context, operationType *OperationType) error
+	FindById(ctx context.Context, id int) (*OperationType, error)
+	FindAll(ctx context.Context) ([]OperationType, error)
+}
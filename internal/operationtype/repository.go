@@ -0,0 +1,58 @@
+package operationtype
+
+import (
+	"context"
+	"errors"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+type Repository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewRepository(db *gorm.DB, logger *slog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *Repository) Create(ctx context.Context, operationType *OperationType) error {
+	return database.Conn(ctx, r.db).Create(operationType).Error
+}
+
+func (r *Repository) Update(ctx context.Context, operationType *OperationType) error {
+	return database.Conn(ctx, r.db).
+		Model(operationType).
+		Select("description", "direction", "consumes_credit_limit", "active", "internal", "updated_at").
+		Updates(operationType).Error
+}
+
+func (r *Repository) FindById(ctx context.Context, id int) (*OperationType, error) {
+	var operationType *OperationType
+
+	if err := database.Conn(ctx, r.db).First(&operationType, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		r.logger.ErrorContext(ctx, "error finding operation type", slog.Any("error", err))
+		return nil, err
+	}
+
+	return operationType, nil
+}
+
+func (r *Repository) FindAll(ctx context.Context) ([]OperationType, error) {
+	var operationTypes []OperationType
+
+	if err := database.Conn(ctx, r.db).Order("id").Find(&operationTypes).Error; err != nil {
+		r.logger.ErrorContext(ctx, "error listing operation types", slog.Any("error", err))
+		return nil, err
+	}
+
+	return operationTypes, nil
+}
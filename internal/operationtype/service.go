@@ -0,0 +1,173 @@
+package operationtype
+
+import (
+	"context"
+	"github.com/supwr/pismo-transactions/pkg/clock"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheTTL bounds how long a catalogue change made through another instance takes to be noticed.
+const cacheTTL = time.Minute
+
+type Service struct {
+	repository RepositoryInterface
+	clock      clock.Clock
+
+	mu        sync.RWMutex
+	cache     map[int]OperationType
+	expiresAt time.Time
+	// generation changes on every invalidation, so a load that started before one doesn't cache what it read
+	generation int
+}
+
+func NewService(r RepositoryInterface, c clock.Clock) *Service {
+	return &Service{repository: r, clock: c}
+}
+
+// FindById resolves an operation type from the cached catalogue, loading it from the database when it expires.
+func (s *Service) FindById(ctx context.Context, id int) (*OperationType, error) {
+	catalogue, err := s.catalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	operationType, ok := catalogue[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &operationType, nil
+}
+
+// FindByCode resolves a built-in operation type by its code from the cached catalogue.
+func (s *Service) FindByCode(ctx context.Context, code string) (*OperationType, error) {
+	catalogue, err := s.catalogue(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, operationType := range catalogue {
+		if operationType.Code != nil && *operationType.Code == code {
+			return &operationType, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]OperationType, error) {
+	return s.repository.FindAll(ctx)
+}
+
+func (s *Service) Create(ctx context.Context, operationType *OperationType) error {
+	if err := validate(operationType); err != nil {
+		return err
+	}
+
+	if err := s.repository.Create(ctx, operationType); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+func (s *Service) Update(ctx context.Context, operationType *OperationType) error {
+	if err := validate(operationType); err != nil {
+		return err
+	}
+
+	existing, err := s.repository.FindById(ctx, operationType.ID)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		return ErrOperationTypeNotFound
+	}
+
+	if existing.IsBuiltIn() && (operationType.Direction != existing.Direction || operationType.Internal != existing.Internal) {
+		return ErrBuiltInOperationType
+	}
+
+	if err = s.repository.Update(ctx, operationType); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// Deactivate keeps the operation type for the transactions already booked with it, but no new ones can use it.
+func (s *Service) Deactivate(ctx context.Context, id int) error {
+	operationType, err := s.repository.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if operationType == nil {
+		return ErrOperationTypeNotFound
+	}
+
+	operationType.Active = false
+
+	if err = s.repository.Update(ctx, operationType); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+func (s *Service) catalogue(ctx context.Context) (map[int]OperationType, error) {
+	s.mu.RLock()
+	catalogue, expiresAt, generation := s.cache, s.expiresAt, s.generation
+	s.mu.RUnlock()
+
+	now := s.clock.Now()
+	if catalogue != nil && now.Before(expiresAt) {
+		return catalogue, nil
+	}
+
+	operationTypes, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogue = make(map[int]OperationType, len(operationTypes))
+	for _, operationType := range operationTypes {
+		catalogue[operationType.ID] = operationType
+	}
+
+	s.mu.Lock()
+	if s.generation == generation {
+		s.cache = catalogue
+		s.expiresAt = now.Add(cacheTTL)
+	}
+	s.mu.Unlock()
+
+	return catalogue, nil
+}
+
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache = nil
+	s.generation++
+}
+
+func validate(operationType *OperationType) error {
+	operationType.Description = strings.TrimSpace(operationType.Description)
+	if operationType.Description == "" {
+		return ErrInvalidDescription
+	}
+
+	if operationType.Direction != DirectionDebit && operationType.Direction != DirectionCredit {
+		return ErrInvalidDirection
+	}
+
+	return nil
+}
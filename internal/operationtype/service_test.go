@@ -0,0 +1,325 @@
+package operationtype
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	clockmock "github.com/supwr/pismo-transactions/pkg/clock/mock"
+	"testing"
+	"time"
+)
+
+func TestService_FindById(t *testing.T) {
+	operationTypes := []OperationType{
+		{ID: 1, Description: "COMPRA A VISTA", Direction: DirectionDebit, ConsumesCreditLimit: true, Active: true},
+		{ID: 4, Description: "PAGAMENTO", Direction: DirectionCredit, ConsumesCreditLimit: true, Active: true},
+	}
+
+	t.Run("catalogue is loaded once while cached", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+		now := time.Now()
+
+		repo.EXPECT().FindAll(ctx).Return(operationTypes, nil).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(2)
+
+		service := NewService(repo, clockMock)
+
+		o, err := service.FindById(ctx, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, &operationTypes[0], o)
+
+		o, err = service.FindById(ctx, 4)
+		assert.Nil(t, err)
+		assert.Equal(t, &operationTypes[1], o)
+	})
+
+	t.Run("catalogue is reloaded when the cache expires", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+		now := time.Now()
+
+		first := clockMock.EXPECT().Now().Return(now).Times(1)
+		clockMock.EXPECT().Now().Return(now.Add(cacheTTL)).After(first).Times(1)
+		repo.EXPECT().FindAll(ctx).Return(operationTypes, nil).Times(2)
+
+		service := NewService(repo, clockMock)
+
+		_, err := service.FindById(ctx, 1)
+		assert.Nil(t, err)
+
+		_, err = service.FindById(ctx, 1)
+		assert.Nil(t, err)
+	})
+
+	t.Run("catalogue loaded before an invalidation isn't cached", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+
+		service := NewService(repo, clockMock)
+
+		clockMock.EXPECT().Now().Return(time.Now()).Times(2)
+		stale := repo.EXPECT().FindAll(ctx).DoAndReturn(func(ctx context.Context) ([]OperationType, error) {
+			// a change made while the catalogue is being read
+			service.invalidate()
+			return operationTypes[:1], nil
+		}).Times(1)
+		repo.EXPECT().FindAll(ctx).Return(operationTypes, nil).After(stale).Times(1)
+
+		o, err := service.FindById(ctx, 4)
+		assert.Nil(t, err)
+		assert.Nil(t, o)
+
+		o, err = service.FindById(ctx, 4)
+		assert.Nil(t, err)
+		assert.Equal(t, &operationTypes[1], o)
+	})
+
+	t.Run("operation type not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindAll(ctx).Return(operationTypes, nil).Times(1)
+		clockMock.EXPECT().Now().Return(time.Now()).Times(1)
+
+		service := NewService(repo, clockMock)
+		o, err := service.FindById(ctx, 15)
+
+		assert.Nil(t, err)
+		assert.Nil(t, o)
+	})
+
+	t.Run("error loading catalogue", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		expectedErr := errors.New("database error")
+		ctx := context.Background()
+
+		repo.EXPECT().FindAll(ctx).Return(nil, expectedErr).Times(1)
+		clockMock.EXPECT().Now().Return(time.Now()).Times(1)
+
+		service := NewService(repo, clockMock)
+		o, err := service.FindById(ctx, 1)
+
+		assert.Nil(t, o)
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestService_FindByCode(t *testing.T) {
+	t.Run("find built-in operation type by its code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+		code := CodeInstallmentInterest
+
+		operationTypes := []OperationType{
+			{ID: 1, Description: "COMPRA A VISTA", Direction: DirectionDebit, Active: true, BuiltIn: true},
+			{ID: 9, Description: "JUROS DE PARCELAMENTO", Direction: DirectionDebit, Active: true, Internal: true, BuiltIn: true, Code: &code},
+		}
+
+		repo.EXPECT().FindAll(ctx).Return(operationTypes, nil).Times(1)
+		clockMock.EXPECT().Now().Return(time.Now()).Times(2)
+
+		service := NewService(repo, clockMock)
+
+		o, err := service.FindByCode(ctx, CodeInstallmentInterest)
+		assert.Nil(t, err)
+		assert.Equal(t, &operationTypes[1], o)
+
+		o, err = service.FindByCode(ctx, "UNKNOWN")
+		assert.Nil(t, err)
+		assert.Nil(t, o)
+	})
+}
+
+func TestService_Create(t *testing.T) {
+	t.Run("create operation type and invalidate the cache", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+
+		operationType := &OperationType{Description: " TARIFA ", Direction: DirectionDebit, Active: true}
+
+		clockMock.EXPECT().Now().Return(time.Now()).Times(2)
+		load := repo.EXPECT().FindAll(ctx).Return(nil, nil).Times(1)
+		create := repo.EXPECT().Create(ctx, operationType).DoAndReturn(func(ctx context.Context, o *OperationType) error {
+			o.ID = 6
+			return nil
+		}).After(load).Times(1)
+		repo.EXPECT().FindAll(ctx).DoAndReturn(func(ctx context.Context) ([]OperationType, error) {
+			return []OperationType{*operationType}, nil
+		}).After(create).Times(1)
+
+		service := NewService(repo, clockMock)
+
+		o, err := service.FindById(ctx, 6)
+		assert.Nil(t, err)
+		assert.Nil(t, o)
+
+		assert.Nil(t, service.Create(ctx, operationType))
+		assert.Equal(t, "TARIFA", operationType.Description)
+
+		o, err = service.FindById(ctx, 6)
+		assert.Nil(t, err)
+		assert.Equal(t, operationType, o)
+	})
+
+	t.Run("invalid operation types", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+
+		service := NewService(repo, clockMock)
+
+		assert.ErrorIs(t, service.Create(ctx, &OperationType{Description: " ", Direction: DirectionDebit}), ErrInvalidDescription)
+		assert.ErrorIs(t, service.Create(ctx, &OperationType{Description: "TARIFA", Direction: "OTHER"}), ErrInvalidDirection)
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	t.Run("update operation type successfully", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+
+		operationType := &OperationType{ID: 6, Description: "TARIFA", Direction: DirectionDebit}
+
+		repo.EXPECT().FindById(ctx, 6).Return(&OperationType{ID: 6, Description: "FEE", Direction: DirectionDebit}, nil).Times(1)
+		repo.EXPECT().Update(ctx, operationType).Return(nil).Times(1)
+
+		service := NewService(repo, clockMock)
+		assert.Nil(t, service.Update(ctx, operationType))
+	})
+
+	t.Run("operation type not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindById(ctx, 6).Return(nil, nil).Times(1)
+		repo.EXPECT().Update(gomock.Any(), gomock.Any()).Times(0)
+
+		service := NewService(repo, clockMock)
+		err := service.Update(ctx, &OperationType{ID: 6, Description: "TARIFA", Direction: DirectionDebit})
+
+		assert.ErrorIs(t, err, ErrOperationTypeNotFound)
+	})
+
+	t.Run("rename built-in operation type", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+
+		operationType := &OperationType{ID: 5, Description: "ESTORNO DE COMPRA", Direction: DirectionCredit, Internal: true}
+
+		repo.EXPECT().FindById(ctx, 5).Return(&OperationType{ID: 5, Description: "ESTORNO", Direction: DirectionCredit, Internal: true, BuiltIn: true}, nil).Times(1)
+		repo.EXPECT().Update(ctx, operationType).Return(nil).Times(1)
+
+		service := NewService(repo, clockMock)
+		assert.Nil(t, service.Update(ctx, operationType))
+	})
+
+	t.Run("change the direction of an operation type created through the api", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+
+		operationType := &OperationType{ID: 9, Description: "TARIFA", Direction: DirectionCredit, Internal: true}
+
+		repo.EXPECT().FindById(ctx, 9).Return(&OperationType{ID: 9, Description: "TARIFA", Direction: DirectionDebit}, nil).Times(1)
+		repo.EXPECT().Update(ctx, operationType).Return(nil).Times(1)
+
+		service := NewService(repo, clockMock)
+		assert.Nil(t, service.Update(ctx, operationType))
+	})
+
+	t.Run("built-in operation type direction and internal flag can't change", func(t *testing.T) {
+		changes := []struct {
+			existing *OperationType
+			update   *OperationType
+		}{
+			{
+				existing: &OperationType{ID: 2, Description: "COMPRA PARCELADA", Direction: DirectionDebit, BuiltIn: true},
+				update:   &OperationType{ID: 2, Description: "COMPRA PARCELADA", Direction: DirectionCredit},
+			},
+			{
+				existing: &OperationType{ID: 5, Description: "ESTORNO", Direction: DirectionCredit, Internal: true, BuiltIn: true},
+				update:   &OperationType{ID: 5, Description: "ESTORNO", Direction: DirectionCredit, Internal: false},
+			},
+			{
+				existing: &OperationType{ID: 7, Description: "MULTA POR ATRASO", Direction: DirectionDebit, Internal: true, BuiltIn: true},
+				update:   &OperationType{ID: 7, Description: "MULTA POR ATRASO", Direction: DirectionCredit, Internal: true},
+			},
+		}
+
+		for _, change := range changes {
+			ctrl := gomock.NewController(t)
+			repo := NewMockRepositoryInterface(ctrl)
+			clockMock := clockmock.NewMockClock(ctrl)
+			ctx := context.Background()
+
+			repo.EXPECT().FindById(ctx, change.existing.ID).Return(change.existing, nil).Times(1)
+			repo.EXPECT().Update(gomock.Any(), gomock.Any()).Times(0)
+
+			service := NewService(repo, clockMock)
+			assert.ErrorIs(t, service.Update(ctx, change.update), ErrBuiltInOperationType)
+		}
+	})
+}
+
+func TestService_Deactivate(t *testing.T) {
+	t.Run("deactivate operation type successfully", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindById(ctx, 3).Return(&OperationType{ID: 3, Description: "SAQUE", Direction: DirectionDebit, Active: true}, nil).Times(1)
+		repo.EXPECT().Update(ctx, &OperationType{ID: 3, Description: "SAQUE", Direction: DirectionDebit, Active: false}).Return(nil).Times(1)
+
+		service := NewService(repo, clockMock)
+		assert.Nil(t, service.Deactivate(ctx, 3))
+	})
+
+	t.Run("operation type not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindById(ctx, 3).Return(nil, nil).Times(1)
+
+		service := NewService(repo, clockMock)
+		assert.ErrorIs(t, service.Deactivate(ctx, 3), ErrOperationTypeNotFound)
+	})
+}
+
+func TestOperationType_SignedAmount(t *testing.T) {
+	t.Run("debits are negative and credits positive", func(t *testing.T) {
+		debit := &OperationType{Direction: DirectionDebit}
+		credit := &OperationType{Direction: DirectionCredit}
+
+		assert.True(t, debit.SignedAmount(decimal.NewFromInt(10)).Equal(decimal.NewFromInt(-10)))
+		assert.True(t, debit.SignedAmount(decimal.NewFromInt(-10)).Equal(decimal.NewFromInt(-10)))
+		assert.True(t, credit.SignedAmount(decimal.NewFromInt(-10)).Equal(decimal.NewFromInt(10)))
+	})
+}
@@ -0,0 +1,41 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	EventAccountCreated     = "AccountCreated"
+	EventTransactionCreated = "TransactionCreated"
+	EventCreditLimitChanged = "CreditLimitChanged"
+)
+
+const (
+	AggregateAccount     = "account"
+	AggregateTransaction = "transaction"
+)
+
+// Event is a domain event waiting in the outbox to be published. It's written in the same database transaction as
+// the change it describes, so an event is published if and only if the change was committed.
+type Event struct {
+	ID            int             `json:"id" gorm:"primaryKey"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int             `json:"aggregate_id"`
+	Version       int             `json:"version"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	PublishedAt   *time.Time      `json:"-"`
+	ClaimedUntil  *time.Time      `json:"-"`
+	Attempts      int             `json:"-"`
+	LastError     *string         `json:"-"`
+	CreatedAt     time.Time       `json:"-"`
+}
+
+// Payload is the body of an event as consumers get it. Each event type has its own, apart from the entity it's
+// built from, so it only changes on purpose: fields may be added, but renaming or removing one calls for a new
+// version.
+type Payload interface {
+	Version() int
+}
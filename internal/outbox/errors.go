@@ -0,0 +1,7 @@
+package outbox
+
+import "errors"
+
+var (
+	ErrInvalidBatchSize = errors.New("Batch size must be positive")
+)
@@ -0,0 +1,22 @@
+//go:generate mockgen -destination=mock.go -source=interface.go -package=outbox
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+type RepositoryInterface interface {
+	Create(ctx context.Context, event *Event) error
+	Claim(ctx context.Context, limit int, now time.Time, until time.Time) ([]Event, error)
+	MarkPublished(ctx context.Context, id int, at time.Time) error
+	MarkFailed(ctx context.Context, id int, message string) error
+	Release(ctx context.Context, ids []int) error
+}
+
+// Publisher delivers events to whoever consumes them downstream. Publish must only return nil once the event was
+// accepted, since the relay won't try it again. It runs outside of any database transaction, and an event can be
+// published twice when the relay stops before marking it, so consumers should tell events apart by their id.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
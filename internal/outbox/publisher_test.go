@@ -0,0 +1,29 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWriterPublisher_Publish(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := context.Background()
+
+	publisher := NewWriterPublisher(&buf)
+
+	err := publisher.Publish(ctx, Event{
+		ID:            1,
+		Type:          EventTransactionCreated,
+		AggregateType: AggregateTransaction,
+		AggregateID:   7,
+		Version:       1,
+		Payload:       []byte(`{"amount":-10.5}`),
+		OccurredAt:    time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC),
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id":1,"type":"TransactionCreated","aggregate_type":"transaction","aggregate_id":7,"version":1,"payload":{"amount":-10.5},"occurred_at":"2024-03-20T10:00:00Z"}`+"\n", buf.String())
+}
@@ -0,0 +1,83 @@
+package outbox
+
+import (
+	"context"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+type Repository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewRepository(db *gorm.DB, logger *slog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *Repository) Create(ctx context.Context, event *Event) error {
+	return database.Conn(ctx, r.db).Create(event).Error
+}
+
+// Claim holds up to limit of the oldest unpublished events until until, skipping the ones another relay holds, and
+// returns them oldest first. The claim commits on its own, so no rows stay locked while they're published; events
+// whose relay stopped before marking them are claimed again once their claim runs out.
+func (r *Repository) Claim(ctx context.Context, limit int, now time.Time, until time.Time) ([]Event, error) {
+	var events []Event
+
+	pending := database.Conn(ctx, r.db).
+		Model(&Event{}).
+		Select("id").
+		Where("published_at is null and (claimed_until is null or claimed_until <= ?)", now).
+		Order("id").
+		Limit(limit).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+
+	err := database.Conn(ctx, r.db).
+		Model(&events).
+		Clauses(clause.Returning{}).
+		Where("id in (?)", pending).
+		Update("claimed_until", until).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error claiming pending events", slog.Any("error", err))
+		return nil, err
+	}
+
+	slices.SortFunc(events, func(a, b Event) int { return a.ID - b.ID })
+
+	return events, nil
+}
+
+func (r *Repository) MarkPublished(ctx context.Context, id int, at time.Time) error {
+	return database.Conn(ctx, r.db).
+		Model(&Event{}).
+		Where("id = ?", id).
+		Update("published_at", at).Error
+}
+
+func (r *Repository) MarkFailed(ctx context.Context, id int, message string) error {
+	return database.Conn(ctx, r.db).
+		Model(&Event{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":      gorm.Expr("attempts + 1"),
+			"last_error":    message,
+			"claimed_until": nil,
+		}).Error
+}
+
+// Release gives the events back before their claim runs out, so the next run can publish them.
+func (r *Repository) Release(ctx context.Context, ids []int) error {
+	return database.Conn(ctx, r.db).
+		Model(&Event{}).
+		Where("id in ?", ids).
+		Update("claimed_until", nil).Error
+}
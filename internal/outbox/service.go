@@ -0,0 +1,92 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/supwr/pismo-transactions/pkg/clock"
+	"time"
+)
+
+// claimDuration is how long a relay holds the events it claimed. A batch must be published within it, or another
+// relay may publish the same events again.
+const claimDuration = 5 * time.Minute
+
+type Service struct {
+	repository RepositoryInterface
+	clock      clock.Clock
+}
+
+func NewService(r RepositoryInterface, c clock.Clock) *Service {
+	return &Service{repository: r, clock: c}
+}
+
+// Record writes an event about the aggregate to the outbox, with payload encoded as JSON. It must run in the same
+// unit of work as the change the event describes.
+func (s *Service) Record(ctx context.Context, eventType string, aggregateType string, aggregateID int, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.Create(ctx, &Event{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Version:       payload.Version(),
+		Payload:       body,
+		OccurredAt:    s.clock.Now(),
+	})
+}
+
+// Relay publishes up to batchSize pending events, oldest first, and returns how many were published. The events are
+// claimed first and published outside of any transaction, each one marked published once accepted. It stops at the
+// first event the publisher refuses, so events keep their order, and gives back the rest of the batch; they're
+// retried on the next run.
+func (s *Service) Relay(ctx context.Context, p Publisher, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		return 0, ErrInvalidBatchSize
+	}
+
+	now := s.clock.Now()
+
+	events, err := s.repository.Claim(ctx, batchSize, now, now.Add(claimDuration))
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+
+	for i, e := range events {
+		if publishErr := p.Publish(ctx, e); publishErr != nil {
+			err = errors.Join(
+				fmt.Errorf("event %d: %w", e.ID, publishErr),
+				s.repository.MarkFailed(ctx, e.ID, publishErr.Error()),
+			)
+
+			if rest := events[i+1:]; len(rest) > 0 {
+				err = errors.Join(err, s.repository.Release(ctx, ids(rest)))
+			}
+
+			return published, err
+		}
+
+		if err = s.repository.MarkPublished(ctx, e.ID, s.clock.Now()); err != nil {
+			return published, err
+		}
+
+		published++
+	}
+
+	return published, nil
+}
+
+func ids(events []Event) []int {
+	result := make([]int, 0, len(events))
+	for _, e := range events {
+		result = append(result, e.ID)
+	}
+
+	return result
+}
@@ -0,0 +1,141 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	clockmock "github.com/supwr/pismo-transactions/pkg/clock/mock"
+	"testing"
+	"time"
+)
+
+type testPayload struct {
+	ID int `json:"id"`
+}
+
+func (testPayload) Version() int {
+	return 2
+}
+
+type unencodablePayload struct {
+	C chan int
+}
+
+func (unencodablePayload) Version() int {
+	return 1
+}
+
+func TestService_Record(t *testing.T) {
+	t.Run("record event with its payload as json", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+		now := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().Create(ctx, &Event{
+			Type:          EventAccountCreated,
+			AggregateType: AggregateAccount,
+			AggregateID:   1,
+			Version:       2,
+			Payload:       []byte(`{"id":1}`),
+			OccurredAt:    now,
+		}).Return(nil).Times(1)
+
+		service := NewService(repo, clockMock)
+		err := service.Record(ctx, EventAccountCreated, AggregateAccount, 1, testPayload{ID: 1})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("payload that can't be encoded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ctx := context.Background()
+
+		service := NewService(NewMockRepositoryInterface(ctrl), clockmock.NewMockClock(ctrl))
+		err := service.Record(ctx, EventAccountCreated, AggregateAccount, 1, unencodablePayload{C: make(chan int)})
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestService_Relay(t *testing.T) {
+	now := time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+	until := now.Add(claimDuration)
+	events := []Event{
+		{ID: 1, Type: EventAccountCreated, AggregateType: AggregateAccount, AggregateID: 1, Payload: []byte(`{}`)},
+		{ID: 2, Type: EventTransactionCreated, AggregateType: AggregateTransaction, AggregateID: 7, Payload: []byte(`{}`)},
+		{ID: 3, Type: EventCreditLimitChanged, AggregateType: AggregateAccount, AggregateID: 1, Payload: []byte(`{}`)},
+	}
+
+	t.Run("publish claimed events in order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		publisher := NewMemoryPublisher()
+		ctx := context.Background()
+
+		clockMock.EXPECT().Now().Return(now).Times(4)
+		repo.EXPECT().Claim(ctx, 10, now, until).Return(events, nil).Times(1)
+		repo.EXPECT().MarkPublished(ctx, gomock.Any(), now).Return(nil).Times(3)
+
+		service := NewService(repo, clockMock)
+		published, err := service.Relay(ctx, publisher, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, published)
+		assert.Equal(t, events, publisher.Events())
+	})
+
+	t.Run("stop at the first event the publisher refuses and give back the rest", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		publisher := NewMockPublisher(ctrl)
+		ctx := context.Background()
+		expectedErr := errors.New("broker unavailable")
+
+		clockMock.EXPECT().Now().Return(now).Times(2)
+		claim := repo.EXPECT().Claim(ctx, 10, now, until).Return(events, nil).Times(1)
+		first := publisher.EXPECT().Publish(ctx, events[0]).Return(nil).After(claim).Times(1)
+		repo.EXPECT().MarkPublished(ctx, 1, now).Return(nil).After(first).Times(1)
+		second := publisher.EXPECT().Publish(ctx, events[1]).Return(expectedErr).After(first).Times(1)
+		failed := repo.EXPECT().MarkFailed(ctx, 2, "broker unavailable").Return(nil).After(second).Times(1)
+		repo.EXPECT().Release(ctx, []int{3}).Return(nil).After(failed).Times(1)
+
+		service := NewService(repo, clockMock)
+		published, err := service.Relay(ctx, publisher, 10)
+
+		assert.ErrorIs(t, err, expectedErr)
+		assert.Equal(t, 1, published)
+	})
+
+	t.Run("error claiming pending events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+		expectedErr := errors.New("database error")
+
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		repo.EXPECT().Claim(ctx, 10, now, until).Return(nil, expectedErr).Times(1)
+
+		service := NewService(repo, clockMock)
+		published, err := service.Relay(ctx, NewMemoryPublisher(), 10)
+
+		assert.ErrorIs(t, err, expectedErr)
+		assert.Equal(t, 0, published)
+	})
+
+	t.Run("invalid batch size", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		ctx := context.Background()
+
+		service := NewService(NewMockRepositoryInterface(ctrl), clockmock.NewMockClock(ctrl))
+		_, err := service.Relay(ctx, NewMemoryPublisher(), 0)
+
+		assert.ErrorIs(t, err, ErrInvalidBatchSize)
+	})
+}
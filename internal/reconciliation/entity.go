@@ -0,0 +1,24 @@
+package reconciliation
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Mismatch is an account whose stored available limit differs from the one its transaction history adds up to.
+type Mismatch struct {
+	AccountID                    int             `json:"account_id"`
+	CreditLimit                  decimal.Decimal `json:"credit_limit"`
+	AvailableCreditLimit         decimal.Decimal `json:"available_credit_limit"`
+	ExpectedAvailableCreditLimit decimal.Decimal `json:"expected_available_credit_limit"`
+	Repaired                     bool            `json:"repaired"`
+}
+
+// Difference is how much the stored available limit is above (positive) or below (negative) the expected one.
+func (m *Mismatch) Difference() decimal.Decimal {
+	return m.AvailableCreditLimit.Sub(m.ExpectedAvailableCreditLimit)
+}
@@ -0,0 +1,7 @@
+package reconciliation
+
+import "errors"
+
+var (
+	ErrUnknownFormat = errors.New("Report format must be json or csv")
+)
@@ -0,0 +1,12 @@
+//go:generate mockgen -destination=mock.go -source=interface.go -package=reconciliation
+package reconciliation
+
+import (
+	"context"
+	"github.com/shopspring/decimal"
+)
+
+type RepositoryInterface interface {
+	FindMismatches(ctx context.Context) ([]Mismatch, error)
+	ExpectedAvailableLimit(ctx context.Context, accountID int) (decimal.Decimal, error)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interface.go
+
+// Package reconciliation is a generated GoMock package.
+package reconciliation
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	decimal "github.com/shopspring/decimal"
+)
+
+// MockRepositoryInterface is a mock of RepositoryInterface interface.
+type MockRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryInterfaceMockRecorder
+}
+
+// MockRepositoryInterfaceMockRecorder is the mock recorder for MockRepositoryInterface.
+type MockRepositoryInterfaceMockRecorder struct {
+	mock *MockRepositoryInterface
+}
+
+// NewMockRepositoryInterface creates a new mock instance.
+func NewMockRepositoryInterface(ctrl *gomock.Controller) *MockRepositoryInterface {
+	mock := &MockRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// ExpectedAvailableLimit mocks base method.
+func (m *MockRepositoryInterface) ExpectedAvailableLimit(ctx context.Context, accountID int) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpectedAvailableLimit", ctx, accountID)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpectedAvailableLimit indicates an expected call of ExpectedAvailableLimit.
+func (mr *MockRepositoryInterfaceMockRecorder) ExpectedAvailableLimit(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpectedAvailableLimit", reflect.TypeOf((*MockRepositoryInterface)(nil).ExpectedAvailableLimit), ctx, accountID)
+}
+
+// FindMismatches mocks base method.
+func (m *MockRepositoryInterface) FindMismatches(ctx context.Context) ([]Mismatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMismatches", ctx)
+	ret0, _ := ret[0].([]Mismatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMismatches indicates an expected call of FindMismatches.
+func (mr *MockRepositoryInterfaceMockRecorder) FindMismatches(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMismatches", reflect.TypeOf((*MockRepositoryInterface)(nil).FindMismatches), ctx)
+}
@@ -0,0 +1,49 @@
+package reconciliation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"github.com/shopspring/decimal"
+	"io"
+	"strconv"
+)
+
+type reportRow struct {
+	Mismatch
+	Difference decimal.Decimal `json:"difference"`
+}
+
+// WriteReport writes the mismatches to w as a JSON array or as CSV with a header row.
+func WriteReport(w io.Writer, format string, mismatches []Mismatch) error {
+	switch format {
+	case FormatJSON:
+		rows := make([]reportRow, 0, len(mismatches))
+		for _, m := range mismatches {
+			rows = append(rows, reportRow{Mismatch: m, Difference: m.Difference()})
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(rows)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		_ = writer.Write([]string{"account_id", "credit_limit", "available_credit_limit", "expected_available_credit_limit", "difference", "repaired"})
+
+		for _, m := range mismatches {
+			_ = writer.Write([]string{
+				strconv.Itoa(m.AccountID),
+				m.CreditLimit.StringFixed(2),
+				m.AvailableCreditLimit.StringFixed(2),
+				m.ExpectedAvailableCreditLimit.StringFixed(2),
+				m.Difference().StringFixed(2),
+				strconv.FormatBool(m.Repaired),
+			})
+		}
+
+		writer.Flush()
+		return writer.Error()
+	default:
+		return ErrUnknownFormat
+	}
+}
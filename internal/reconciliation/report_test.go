@@ -0,0 +1,62 @@
+package reconciliation
+
+import (
+	"bytes"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriteReport(t *testing.T) {
+	mismatches := []Mismatch{
+		{
+			AccountID:                    1,
+			CreditLimit:                  decimal.NewFromInt(1000),
+			AvailableCreditLimit:         decimal.NewFromInt(900),
+			ExpectedAvailableCreditLimit: decimal.NewFromFloat(950.5),
+		},
+	}
+
+	t.Run("write csv report", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := WriteReport(&buf, FormatCSV, mismatches)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "account_id,credit_limit,available_credit_limit,expected_available_credit_limit,difference,repaired\n"+
+			"1,1000.00,900.00,950.50,-50.50,false\n", buf.String())
+	})
+
+	t.Run("write json report", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := WriteReport(&buf, FormatJSON, mismatches)
+
+		assert.Nil(t, err)
+		assert.JSONEq(t, `[{
+			"account_id": 1,
+			"credit_limit": "1000",
+			"available_credit_limit": "900",
+			"expected_available_credit_limit": "950.5",
+			"difference": "-50.5",
+			"repaired": false
+		}]`, buf.String())
+	})
+
+	t.Run("write empty json report", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := WriteReport(&buf, FormatJSON, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "[]\n", buf.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		err := WriteReport(&buf, "xml", mismatches)
+
+		assert.ErrorIs(t, err, ErrUnknownFormat)
+	})
+}
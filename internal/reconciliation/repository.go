@@ -0,0 +1,90 @@
+package reconciliation
+
+import (
+	"context"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"gorm.io/gorm"
+	"log/slog"
+)
+
+type Repository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewRepository(db *gorm.DB, logger *slog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// FindMismatches compares every open account's available limit with its credit limit plus the amounts of the
+// transactions that moved it, minus what pending authorizations hold.
+func (r *Repository) FindMismatches(ctx context.Context) ([]Mismatch, error) {
+	var mismatches []Mismatch
+
+	query := fmt.Sprintf(`
+		SELECT a.id AS account_id, a.credit_limit, a.available_credit_limit,
+			a.credit_limit + COALESCE(u.used, 0) - COALESCE(h.held, 0) AS expected_available_credit_limit
+		FROM %s a
+		LEFT JOIN (%s) u ON u.account_id = a.id
+		LEFT JOIN (%s) h ON h.account_id = a.id
+		WHERE a.deleted_at IS NULL
+			AND a.available_credit_limit <> a.credit_limit + COALESCE(u.used, 0) - COALESCE(h.held, 0)
+		ORDER BY a.id`, r.table("Account"), r.usedLimit("1 = 1"), r.heldLimit("1 = 1"))
+
+	if err := database.Conn(ctx, r.db).Raw(query).Scan(&mismatches).Error; err != nil {
+		r.logger.ErrorContext(ctx, "error finding available limit mismatches", slog.Any("error", err))
+		return nil, err
+	}
+
+	return mismatches, nil
+}
+
+func (r *Repository) ExpectedAvailableLimit(ctx context.Context, accountID int) (decimal.Decimal, error) {
+	var expected decimal.Decimal
+
+	query := fmt.Sprintf(`
+		SELECT a.credit_limit + COALESCE(u.used, 0) - COALESCE(h.held, 0)
+		FROM %s a
+		LEFT JOIN (%s) u ON u.account_id = a.id
+		LEFT JOIN (%s) h ON h.account_id = a.id
+		WHERE a.id = @account`, r.table("Account"), r.usedLimit("t.account_id = @account"), r.heldLimit("h.account_id = @account"))
+
+	err := database.Conn(ctx, r.db).Raw(query, map[string]interface{}{"account": accountID}).Scan(&expected).Error
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error computing expected available limit", slog.Any("error", err))
+		return decimal.Zero, err
+	}
+
+	return expected, nil
+}
+
+// usedLimit sums, per account, the transactions whose operation type consumes the credit limit. Reversals
+// only give back the limit when the transaction they reverse took it.
+func (r *Repository) usedLimit(condition string) string {
+	return fmt.Sprintf(`
+		SELECT t.account_id, SUM(t.amount) AS used
+		FROM %[1]s t
+		JOIN %[2]s o ON o.id = t.operation_type_id
+		LEFT JOIN %[1]s rt ON rt.id = t.reversed_transaction_id
+		LEFT JOIN %[2]s ro ON ro.id = rt.operation_type_id
+		WHERE t.deleted_at IS NULL AND COALESCE(ro.consumes_credit_limit, o.consumes_credit_limit) AND %[3]s
+		GROUP BY t.account_id`, r.table("Transaction"), r.table("OperationType"), condition)
+}
+
+// heldLimit sums, per account, the limit reserved by authorizations that weren't captured, voided or expired yet.
+func (r *Repository) heldLimit(condition string) string {
+	return fmt.Sprintf(`
+		SELECT h.account_id, SUM(h.reserved) AS held
+		FROM %s h
+		WHERE h.status = 'AUTHORIZED' AND %s
+		GROUP BY h.account_id`, r.table("Authorization"), condition)
+}
+
+func (r *Repository) table(model string) string {
+	return r.db.NamingStrategy.TableName(model)
+}
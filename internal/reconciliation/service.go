@@ -0,0 +1,63 @@
+package reconciliation
+
+import (
+	"context"
+	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/pkg/database"
+)
+
+type Service struct {
+	repository     RepositoryInterface
+	accountService *account.Service
+	txManager      database.TxManager
+}
+
+func NewService(r RepositoryInterface, a *account.Service, tm database.TxManager) *Service {
+	return &Service{repository: r, accountService: a, txManager: tm}
+}
+
+// Run reports the accounts whose available limit drifted from their transaction history. With repair, each one
+// is set to the expected limit, recomputed while the account is locked so transactions booked since the report
+// are taken into account.
+func (s *Service) Run(ctx context.Context, repair bool) ([]Mismatch, error) {
+	mismatches, err := s.repository.FindMismatches(ctx)
+	if err != nil || !repair {
+		return mismatches, err
+	}
+
+	for i := range mismatches {
+		if err = s.repair(ctx, &mismatches[i]); err != nil {
+			return mismatches, err
+		}
+	}
+
+	return mismatches, nil
+}
+
+func (s *Service) repair(ctx context.Context, m *Mismatch) error {
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		acc, err := s.accountService.FindByIdForUpdate(ctx, m.AccountID)
+		if err != nil || acc == nil {
+			return err
+		}
+
+		expected, err := s.repository.ExpectedAvailableLimit(ctx, m.AccountID)
+		if err != nil {
+			return err
+		}
+
+		m.AvailableCreditLimit = acc.AvailableCreditLimit
+		m.ExpectedAvailableCreditLimit = expected
+
+		if !acc.AvailableCreditLimit.Equal(expected) {
+			acc.AvailableCreditLimit = expected
+
+			if err = s.accountService.UpdateCreditLimit(ctx, acc); err != nil {
+				return err
+			}
+		}
+
+		m.Repaired = true
+		return nil
+	})
+}
@@ -0,0 +1,147 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/testutil"
+	clockmock "github.com/supwr/pismo-transactions/pkg/clock/mock"
+	dbmock "github.com/supwr/pismo-transactions/pkg/database/mock"
+	"testing"
+)
+
+func TestService_Run(t *testing.T) {
+	t.Run("report mismatches without repairing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		mismatches := []Mismatch{
+			{
+				AccountID:                    1,
+				CreditLimit:                  decimal.NewFromInt(1000),
+				AvailableCreditLimit:         decimal.NewFromInt(900),
+				ExpectedAvailableCreditLimit: decimal.NewFromInt(950),
+			},
+		}
+
+		repo.EXPECT().FindMismatches(gomock.Any()).Return(mismatches, nil).Times(1)
+
+		service := NewService(repo, newAccountService(ctrl, accountRepo, txManager), txManager)
+		result, err := service.Run(ctx, false)
+
+		assert.Nil(t, err)
+		assert.Equal(t, mismatches, result)
+		assert.False(t, result[0].Repaired)
+	})
+
+	t.Run("repair mismatches", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		mismatches := []Mismatch{
+			{
+				AccountID:                    1,
+				CreditLimit:                  decimal.NewFromInt(1000),
+				AvailableCreditLimit:         decimal.NewFromInt(900),
+				ExpectedAvailableCreditLimit: decimal.NewFromInt(950),
+			},
+		}
+
+		gomock.InOrder(
+			repo.EXPECT().FindMismatches(gomock.Any()).Return(mismatches, nil).Times(1),
+			txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1),
+			accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&account.Account{
+				ID:                   1,
+				CreditLimit:          decimal.NewFromInt(1000),
+				AvailableCreditLimit: decimal.NewFromInt(880),
+			}, nil).Times(1),
+			repo.EXPECT().ExpectedAvailableLimit(gomock.Any(), 1).Return(decimal.NewFromInt(930), nil).Times(1),
+			accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *account.Account) error {
+				assert.True(t, decimal.NewFromInt(930).Equal(a.AvailableCreditLimit))
+				return nil
+			}).Times(1),
+		)
+
+		service := NewService(repo, newAccountService(ctrl, accountRepo, txManager), txManager)
+		result, err := service.Run(ctx, true)
+
+		assert.Nil(t, err)
+		assert.True(t, result[0].Repaired)
+		assert.True(t, decimal.NewFromInt(880).Equal(result[0].AvailableCreditLimit))
+		assert.True(t, decimal.NewFromInt(930).Equal(result[0].ExpectedAvailableCreditLimit))
+	})
+
+	t.Run("skip update when the account already matches under lock", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindMismatches(gomock.Any()).Return([]Mismatch{{AccountID: 1}}, nil).Times(1)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&account.Account{
+			ID:                   1,
+			AvailableCreditLimit: decimal.NewFromInt(950),
+		}, nil).Times(1)
+		repo.EXPECT().ExpectedAvailableLimit(gomock.Any(), 1).Return(decimal.NewFromInt(950), nil).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Times(0)
+
+		service := NewService(repo, newAccountService(ctrl, accountRepo, txManager), txManager)
+		result, err := service.Run(ctx, true)
+
+		assert.Nil(t, err)
+		assert.True(t, result[0].Repaired)
+	})
+
+	t.Run("error finding mismatches", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		expectedErr := errors.New("database error")
+
+		repo.EXPECT().FindMismatches(gomock.Any()).Return(nil, expectedErr).Times(1)
+
+		service := NewService(repo, newAccountService(ctrl, accountRepo, txManager), txManager)
+		result, err := service.Run(ctx, true)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, expectedErr)
+	})
+
+	t.Run("error computing expected limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		expectedErr := errors.New("database error")
+
+		repo.EXPECT().FindMismatches(gomock.Any()).Return([]Mismatch{{AccountID: 1}, {AccountID: 2}}, nil).Times(1)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&account.Account{ID: 1}, nil).Times(1)
+		repo.EXPECT().ExpectedAvailableLimit(gomock.Any(), 1).Return(decimal.Zero, expectedErr).Times(1)
+
+		service := NewService(repo, newAccountService(ctrl, accountRepo, txManager), txManager)
+		result, err := service.Run(ctx, true)
+
+		assert.ErrorIs(t, err, expectedErr)
+		assert.False(t, result[0].Repaired)
+		assert.False(t, result[1].Repaired)
+	})
+}
+
+func newAccountService(ctrl *gomock.Controller, r account.RepositoryInterface, tm *dbmock.MockTxManager) *account.Service {
+	return account.NewService(r, nil, clockmock.NewMockClock(ctrl), tm)
+}
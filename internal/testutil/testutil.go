@@ -0,0 +1,38 @@
+// Package testutil holds the helpers the service tests share: stand-ins for the transaction manager and services
+// backed by mocks, for tests that don't look at them.
+package testutil
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"github.com/supwr/pismo-transactions/internal/outbox"
+	"github.com/supwr/pismo-transactions/pkg/clock"
+)
+
+// RunInTransaction runs fn as if it were in a transaction. It's meant for mocked TxManager.WithinTransaction calls.
+func RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// RunAfterCommit runs fn right away, as if the transaction had committed. It's meant for mocked
+// TxManager.AfterCommit calls.
+func RunAfterCommit(_ context.Context, fn func()) {
+	fn()
+}
+
+// NewOutboxService records every event without checking it.
+func NewOutboxService(ctrl *gomock.Controller) *outbox.Service {
+	repo := outbox.NewMockRepositoryInterface(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return outbox.NewService(repo, clock.NewClock())
+}
+
+// NewOperationTypeService serves operationTypes as the whole catalogue.
+func NewOperationTypeService(ctrl *gomock.Controller, operationTypes ...operationtype.OperationType) *operationtype.Service {
+	repo := operationtype.NewMockRepositoryInterface(ctrl)
+	repo.EXPECT().FindAll(gomock.Any()).Return(operationTypes, nil).AnyTimes()
+
+	return operationtype.NewService(repo, clock.NewClock())
+}
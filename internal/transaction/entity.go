@@ -5,27 +5,45 @@ import (
 	"time"
 )
 
+// Operation types seeded in the catalogue that the service handles specially. Others can be added in operation_types.
 const (
 	OperationTypeCashBuy = iota + 1
 	OperationTypeInstallmentBuy
 	OperationTypeWithdraw
 	OperationTypePayment
+	OperationTypeReversal
+)
+
+const (
+	StatusPosted            = "POSTED"
+	StatusPartiallyReversed = "PARTIALLY_REVERSED"
+	StatusReversed          = "REVERSED"
 )
 
 type Transaction struct {
-	ID              int             `json:"id" gorm:"primaryKey"`
-	AccountID       int             `json:"account_id"`
-	OperationTypeID int             `json:"operation_type_id"`
-	Amount          decimal.Decimal `json:"amount"`
-	OperationDate   time.Time       `json:"operation_date"`
-	CreatedAt       time.Time       `json:"created_at"`
-	UpdatedAt       *time.Time      `json:"updated_at"`
-	DeletedAt       *time.Time      `json:"deleted_at"`
+	ID                    int             `json:"id" gorm:"primaryKey"`
+	AccountID             int             `json:"account_id"`
+	OperationTypeID       int             `json:"operation_type_id"`
+	Amount                decimal.Decimal `json:"amount"`
+	Balance               decimal.Decimal `json:"balance"`
+	OperationDate         time.Time       `json:"operation_date"`
+	Status                string          `json:"status"`
+	ReversedAmount        decimal.Decimal `json:"reversed_amount"`
+	ReversedTransactionID *int            `json:"reversed_transaction_id"`
+	CreatedAt             time.Time       `json:"created_at"`
+	UpdatedAt             *time.Time      `json:"updated_at"`
+	DeletedAt             *time.Time      `json:"deleted_at"`
+
+	// Installments and InterestRate describe the plan of an installment buy and aren't stored with the transaction.
+	Installments int             `json:"-" gorm:"-"`
+	InterestRate decimal.Decimal `json:"-" gorm:"-"`
 }
 
-var Operations = map[int]string{
-	OperationTypeCashBuy:        "COMPRA A VISTA",
-	OperationTypeInstallmentBuy: "COMPRA PARCELADA",
-	OperationTypeWithdraw:       "SAQUE",
-	OperationTypePayment:        "PAGAMENTO",
+// Discharge records how much of a payment was used to settle an older negative transaction.
+type Discharge struct {
+	ID                   int             `json:"id" gorm:"primaryKey"`
+	PaymentTransactionID int             `json:"payment_transaction_id"`
+	TransactionID        int             `json:"transaction_id"`
+	Amount               decimal.Decimal `json:"amount"`
+	CreatedAt            time.Time       `json:"created_at"`
 }
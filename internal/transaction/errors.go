@@ -3,7 +3,16 @@ package transaction
 import "errors"
 
 var (
-	ErrOperationTypeNotFound = errors.New("Operation Type not found")
-	ErrAccountNotFound       = errors.New("Account not found")
-	ErrInsuficientFunds      = errors.New("Insuficient funds")
+	ErrOperationTypeNotFound    = errors.New("Operation Type not found")
+	ErrAccountNotFound          = errors.New("Account not found")
+	ErrAccountBlocked           = errors.New("Account is blocked for purchases and withdraws")
+	ErrAccountClosed            = errors.New("Account is closed")
+	ErrInsuficientFunds         = errors.New("Insuficient funds")
+	ErrInvalidCursor            = errors.New("Invalid pagination cursor")
+	ErrInstallmentsNotAllowed   = errors.New("Installments are only allowed for installment buys")
+	ErrOperationTypeNotAllowed  = errors.New("Operation type can't be used to create transactions")
+	ErrTransactionNotFound      = errors.New("Transaction not found")
+	ErrTransactionNotReversible = errors.New("Only purchases and withdraws can be reversed")
+	ErrInvalidReversalAmount    = errors.New("Reversal amount must be positive")
+	ErrReversalExceedsAmount    = errors.New("Reversal amount exceeds what is left to reverse")
 )
@@ -0,0 +1,35 @@
+package transaction
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// TransactionCreatedV1 is the payload of TransactionCreated events. Reversals carry the transaction they refund.
+type TransactionCreatedV1 struct {
+	ID                    int             `json:"id"`
+	AccountID             int             `json:"account_id"`
+	OperationTypeID       int             `json:"operation_type_id"`
+	Amount                decimal.Decimal `json:"amount"`
+	Balance               decimal.Decimal `json:"balance"`
+	OperationDate         time.Time       `json:"operation_date"`
+	Status                string          `json:"status"`
+	ReversedTransactionID *int            `json:"reversed_transaction_id"`
+}
+
+func (TransactionCreatedV1) Version() int {
+	return 1
+}
+
+func newTransactionCreatedV1(t *Transaction) TransactionCreatedV1 {
+	return TransactionCreatedV1{
+		ID:                    t.ID,
+		AccountID:             t.AccountID,
+		OperationTypeID:       t.OperationTypeID,
+		Amount:                t.Amount,
+		Balance:               t.Balance,
+		OperationDate:         t.OperationDate,
+		Status:                t.Status,
+		ReversedTransactionID: t.ReversedTransactionID,
+	}
+}
@@ -0,0 +1,58 @@
+package transaction
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 100
+)
+
+// Filter narrows down the transactions of an account. Amount bounds are compared against the absolute
+// amount, and the date range includes From and excludes To.
+type Filter struct {
+	AccountID       int
+	OperationTypeID int
+	From            *time.Time
+	To              *time.Time
+	MinAmount       *decimal.Decimal
+	MaxAmount       *decimal.Decimal
+	After           *Cursor
+	Limit           int
+}
+
+// Cursor points at the last transaction of a page. Pages are ordered by (operation_date, id) descending,
+// so the next page starts right after it.
+type Cursor struct {
+	OperationDate time.Time
+	ID            int
+}
+
+type Page struct {
+	Transactions []Transaction
+	NextCursor   string
+}
+
+func (c Cursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.OperationDate.UnixNano(), c.ID)))
+}
+
+func DecodeCursor(cursor string) (*Cursor, error) {
+	var nanos int64
+	var id int
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if _, err = fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Cursor{OperationDate: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
@@ -7,4 +7,12 @@ import (
 
 type RepositoryInterface interface {
 	Create(ctx context.Context, transaction *Transaction) error
+	FindById(ctx context.Context, id int) (*Transaction, error)
+	FindByIdForUpdate(ctx context.Context, id int) (*Transaction, error)
+	UpdateReversal(ctx context.Context, transaction *Transaction) error
+	FindByAccount(ctx context.Context, filter Filter) ([]Transaction, error)
+	FindOutstandingByAccount(ctx context.Context, accountID int) ([]Transaction, error)
+	UpdateBalance(ctx context.Context, transaction *Transaction) error
+	CreateDischarges(ctx context.Context, discharges []Discharge) error
+	FindDischargesByTransaction(ctx context.Context, transactionID int) ([]Discharge, error)
 }
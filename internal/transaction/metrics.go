@@ -0,0 +1,86 @@
+package transaction
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"github.com/supwr/pismo-transactions/pkg/metrics"
+	"strconv"
+)
+
+// ReasonError labels the rejections that aren't a business rule, like a database failure.
+const ReasonError = "error"
+
+// rejectionReasons labels the business rules a transaction can be rejected by.
+var rejectionReasons = []struct {
+	err    error
+	reason string
+}{
+	{ErrInsuficientFunds, "insufficient_funds"},
+	{ErrAccountNotFound, "account_not_found"},
+	{ErrAccountBlocked, "account_blocked"},
+	{ErrAccountClosed, "account_closed"},
+	{ErrOperationTypeNotFound, "operation_type_not_found"},
+	{ErrOperationTypeNotAllowed, "operation_type_not_allowed"},
+	{ErrInstallmentsNotAllowed, "installments_not_allowed"},
+	{installment.ErrInvalidInstallmentCount, "invalid_installment_count"},
+	{installment.ErrInvalidInterestRate, "invalid_interest_rate"},
+	{ErrTransactionNotFound, "transaction_not_found"},
+	{ErrTransactionNotReversible, "transaction_not_reversible"},
+	{ErrInvalidReversalAmount, "invalid_reversal_amount"},
+	{ErrReversalExceedsAmount, "reversal_exceeds_amount"},
+}
+
+// Metrics counts the transactions booked, by operation type, along with their amounts, and the ones rejected,
+// by reason.
+type Metrics struct {
+	created  *prometheus.CounterVec
+	amount   *prometheus.CounterVec
+	rejected *prometheus.CounterVec
+}
+
+func NewMetrics(r prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		created: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "transactions_created_total",
+			Help:      "Transactions booked, by operation type.",
+		}, []string{"operation_type_id"}),
+		amount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "transactions_amount_total",
+			Help:      "Absolute amount of the transactions booked, by operation type.",
+		}, []string{"operation_type_id"}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "transactions_rejected_total",
+			Help:      "Transactions and reversals that weren't booked, by reason.",
+		}, []string{"reason"}),
+	}
+
+	r.MustRegister(m.created, m.amount, m.rejected)
+
+	return m
+}
+
+func (m *Metrics) record(t *Transaction, err error) {
+	if err != nil {
+		m.rejected.WithLabelValues(rejectionReason(err)).Inc()
+		return
+	}
+
+	operationType := strconv.Itoa(t.OperationTypeID)
+
+	m.created.WithLabelValues(operationType).Inc()
+	m.amount.WithLabelValues(operationType).Add(t.Amount.Abs().InexactFloat64())
+}
+
+func rejectionReason(err error) string {
+	for _, r := range rejectionReasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+
+	return ReasonError
+}
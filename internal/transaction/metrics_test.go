@@ -0,0 +1,43 @@
+package transaction
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"testing"
+)
+
+func TestMetrics_Record(t *testing.T) {
+	t.Run("count booked transactions and their amounts by operation type", func(t *testing.T) {
+		m := NewMetrics(prometheus.NewRegistry())
+
+		m.record(&Transaction{OperationTypeID: OperationTypeCashBuy, Amount: decimal.NewFromFloat(-50.5)}, nil)
+		m.record(&Transaction{OperationTypeID: OperationTypeCashBuy, Amount: decimal.NewFromFloat(-20)}, nil)
+		m.record(&Transaction{OperationTypeID: OperationTypePayment, Amount: decimal.NewFromFloat(100)}, nil)
+
+		assert.Equal(t, float64(2), testutil.ToFloat64(m.created.WithLabelValues("1")))
+		assert.Equal(t, 70.5, testutil.ToFloat64(m.amount.WithLabelValues("1")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.created.WithLabelValues("4")))
+		assert.Equal(t, float64(100), testutil.ToFloat64(m.amount.WithLabelValues("4")))
+		assert.Equal(t, 0, testutil.CollectAndCount(m.rejected))
+	})
+
+	t.Run("count rejections by reason", func(t *testing.T) {
+		m := NewMetrics(prometheus.NewRegistry())
+
+		m.record(&Transaction{}, ErrInsuficientFunds)
+		m.record(&Transaction{}, ErrInsuficientFunds)
+		m.record(&Transaction{}, installment.ErrInvalidInstallmentCount)
+		m.record(nil, ErrReversalExceedsAmount)
+		m.record(&Transaction{}, errors.New("connection refused"))
+
+		assert.Equal(t, float64(2), testutil.ToFloat64(m.rejected.WithLabelValues("insufficient_funds")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.rejected.WithLabelValues("invalid_installment_count")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.rejected.WithLabelValues("reversal_exceeds_amount")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.rejected.WithLabelValues(ReasonError)))
+		assert.Equal(t, 0, testutil.CollectAndCount(m.created))
+	})
+}
@@ -47,3 +47,120 @@ func (mr *MockRepositoryInterfaceMockRecorder) Create(ctx, transaction interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepositoryInterface)(nil).Create), ctx, transaction)
 }
+
+// CreateDischarges mocks base method.
+func (m *MockRepositoryInterface) CreateDischarges(ctx context.Context, discharges []Discharge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDischarges", ctx, discharges)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDischarges indicates an expected call of CreateDischarges.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateDischarges(ctx, discharges interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDischarges", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateDischarges), ctx, discharges)
+}
+
+// FindByAccount mocks base method.
+func (m *MockRepositoryInterface) FindByAccount(ctx context.Context, filter Filter) ([]Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByAccount", ctx, filter)
+	ret0, _ := ret[0].([]Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByAccount indicates an expected call of FindByAccount.
+func (mr *MockRepositoryInterfaceMockRecorder) FindByAccount(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByAccount", reflect.TypeOf((*MockRepositoryInterface)(nil).FindByAccount), ctx, filter)
+}
+
+// FindById mocks base method.
+func (m *MockRepositoryInterface) FindById(ctx context.Context, id int) (*Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", ctx, id)
+	ret0, _ := ret[0].(*Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockRepositoryInterfaceMockRecorder) FindById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockRepositoryInterface)(nil).FindById), ctx, id)
+}
+
+// FindByIdForUpdate mocks base method.
+func (m *MockRepositoryInterface) FindByIdForUpdate(ctx context.Context, id int) (*Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIdForUpdate", ctx, id)
+	ret0, _ := ret[0].(*Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIdForUpdate indicates an expected call of FindByIdForUpdate.
+func (mr *MockRepositoryInterfaceMockRecorder) FindByIdForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIdForUpdate", reflect.TypeOf((*MockRepositoryInterface)(nil).FindByIdForUpdate), ctx, id)
+}
+
+// FindDischargesByTransaction mocks base method.
+func (m *MockRepositoryInterface) FindDischargesByTransaction(ctx context.Context, transactionID int) ([]Discharge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDischargesByTransaction", ctx, transactionID)
+	ret0, _ := ret[0].([]Discharge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDischargesByTransaction indicates an expected call of FindDischargesByTransaction.
+func (mr *MockRepositoryInterfaceMockRecorder) FindDischargesByTransaction(ctx, transactionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDischargesByTransaction", reflect.TypeOf((*MockRepositoryInterface)(nil).FindDischargesByTransaction), ctx, transactionID)
+}
+
+// FindOutstandingByAccount mocks base method.
+func (m *MockRepositoryInterface) FindOutstandingByAccount(ctx context.Context, accountID int) ([]Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOutstandingByAccount", ctx, accountID)
+	ret0, _ := ret[0].([]Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOutstandingByAccount indicates an expected call of FindOutstandingByAccount.
+func (mr *MockRepositoryInterfaceMockRecorder) FindOutstandingByAccount(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOutstandingByAccount", reflect.TypeOf((*MockRepositoryInterface)(nil).FindOutstandingByAccount), ctx, accountID)
+}
+
+// UpdateBalance mocks base method.
+func (m *MockRepositoryInterface) UpdateBalance(ctx context.Context, transaction *Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBalance", ctx, transaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBalance indicates an expected call of UpdateBalance.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateBalance(ctx, transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateBalance), ctx, transaction)
+}
+
+// UpdateReversal mocks base method.
+func (m *MockRepositoryInterface) UpdateReversal(ctx context.Context, transaction *Transaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReversal", ctx, transaction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReversal indicates an expected call of UpdateReversal.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateReversal(ctx, transaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReversal", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateReversal), ctx, transaction)
+}
@@ -2,7 +2,10 @@ package transaction
 
 import (
 	"context"
+	"errors"
+	"github.com/supwr/pismo-transactions/pkg/database"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log/slog"
 )
 
@@ -19,5 +22,129 @@ func NewRepository(db *gorm.DB, logger *slog.Logger) *Repository {
 }
 
 func (t *Repository) Create(ctx context.Context, transaction *Transaction) error {
-	return t.db.Create(transaction).Error
+	return database.Conn(ctx, t.db).Create(transaction).Error
+}
+
+func (t *Repository) FindById(ctx context.Context, id int) (*Transaction, error) {
+	var transaction *Transaction
+
+	if err := database.Conn(ctx, t.db).First(&transaction, "id = ? and deleted_at is null", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		t.logger.ErrorContext(ctx, "error finding transaction", slog.Any("error", err))
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (t *Repository) FindByIdForUpdate(ctx context.Context, id int) (*Transaction, error) {
+	var transaction *Transaction
+
+	err := database.Conn(ctx, t.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&transaction, "id = ? and deleted_at is null", id).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		t.logger.ErrorContext(ctx, "error finding transaction for update", slog.Any("error", err))
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (t *Repository) UpdateReversal(ctx context.Context, transaction *Transaction) error {
+	var tr *Transaction
+	return database.Conn(ctx, t.db).Model(&tr).Where("id = ?", transaction.ID).Updates(map[string]interface{}{
+		"reversed_amount": transaction.ReversedAmount,
+		"status":          transaction.Status,
+		"balance":         transaction.Balance,
+	}).Error
+}
+
+func (t *Repository) FindByAccount(ctx context.Context, filter Filter) ([]Transaction, error) {
+	var transactions []Transaction
+
+	query := database.Conn(ctx, t.db).Where("account_id = ? and deleted_at is null", filter.AccountID)
+
+	if filter.OperationTypeID != 0 {
+		query = query.Where("operation_type_id = ?", filter.OperationTypeID)
+	}
+
+	if filter.From != nil {
+		query = query.Where("operation_date >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		query = query.Where("operation_date < ?", *filter.To)
+	}
+
+	if filter.MinAmount != nil {
+		query = query.Where("abs(amount) >= ?", *filter.MinAmount)
+	}
+
+	if filter.MaxAmount != nil {
+		query = query.Where("abs(amount) <= ?", *filter.MaxAmount)
+	}
+
+	if filter.After != nil {
+		query = query.Where("(operation_date, id) < (?, ?)", filter.After.OperationDate, filter.After.ID)
+	}
+
+	err := query.Order("operation_date desc, id desc").Limit(filter.Limit).Find(&transactions).Error
+	if err != nil {
+		t.logger.ErrorContext(ctx, "error listing transactions", slog.Any("error", err))
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// FindOutstandingByAccount returns the account's transactions that still have a negative balance, oldest first.
+func (t *Repository) FindOutstandingByAccount(ctx context.Context, accountID int) ([]Transaction, error) {
+	var transactions []Transaction
+
+	err := database.Conn(ctx, t.db).
+		Where("account_id = ? and balance < 0 and deleted_at is null", accountID).
+		Order("operation_date, id").
+		Find(&transactions).Error
+
+	if err != nil {
+		t.logger.ErrorContext(ctx, "error finding outstanding transactions", slog.Any("error", err))
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (t *Repository) UpdateBalance(ctx context.Context, transaction *Transaction) error {
+	var tr *Transaction
+	return database.Conn(ctx, t.db).Model(&tr).Where("id = ?", transaction.ID).Update("balance", transaction.Balance).Error
+}
+
+func (t *Repository) CreateDischarges(ctx context.Context, discharges []Discharge) error {
+	return database.Conn(ctx, t.db).Create(&discharges).Error
+}
+
+// FindDischargesByTransaction returns the discharges made by a payment or settling a negative transaction.
+func (t *Repository) FindDischargesByTransaction(ctx context.Context, transactionID int) ([]Discharge, error) {
+	var discharges []Discharge
+
+	err := database.Conn(ctx, t.db).
+		Where("payment_transaction_id = ? or transaction_id = ?", transactionID, transactionID).
+		Order("id").
+		Find(&discharges).Error
+
+	if err != nil {
+		t.logger.ErrorContext(ctx, "error finding discharges", slog.Any("error", err))
+		return nil, err
+	}
+
+	return discharges, nil
 }
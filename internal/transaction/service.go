@@ -4,53 +4,411 @@ import (
 	"context"
 	"github.com/shopspring/decimal"
 	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"github.com/supwr/pismo-transactions/internal/ledger"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"github.com/supwr/pismo-transactions/internal/outbox"
 	"github.com/supwr/pismo-transactions/pkg/clock"
-	"slices"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"github.com/supwr/pismo-transactions/pkg/tracing"
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("github.com/supwr/pismo-transactions/internal/transaction")
+
 type Service struct {
-	repository     RepositoryInterface
-	accountService *account.Service
-	clock          clock.Clock
+	repository           RepositoryInterface
+	accountService       *account.Service
+	installmentService   *installment.Service
+	operationTypeService *operationtype.Service
+	ledgerService        *ledger.Service
+	outboxService        *outbox.Service
+	metrics              *Metrics
+	clock                clock.Clock
+	txManager            database.TxManager
 }
 
-func NewService(r RepositoryInterface, a *account.Service, c clock.Clock) *Service {
-	return &Service{repository: r, accountService: a, clock: c}
+func NewService(
+	r RepositoryInterface,
+	a *account.Service,
+	i *installment.Service,
+	o *operationtype.Service,
+	l *ledger.Service,
+	e *outbox.Service,
+	m *Metrics,
+	c clock.Clock,
+	tm database.TxManager,
+) *Service {
+	return &Service{
+		repository:           r,
+		accountService:       a,
+		installmentService:   i,
+		operationTypeService: o,
+		ledgerService:        l,
+		outboxService:        e,
+		metrics:              m,
+		clock:                c,
+		txManager:            tm,
+	}
+}
+
+func (s *Service) FindById(ctx context.Context, id int) (_ *Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "transaction.Service.FindById")
+	defer func() { tracing.End(span, err) }()
+
+	return s.repository.FindById(ctx, id)
 }
 
-func (s *Service) Create(ctx context.Context, t *Transaction) error {
-	var negAmountTransactions = []int{OperationTypeCashBuy, OperationTypeInstallmentBuy, OperationTypeWithdraw}
+// List returns a page of the account's transactions, newest first. NextCursor is empty on the last page.
+func (s *Service) List(ctx context.Context, filter Filter) (_ *Page, err error) {
+	ctx, span := tracer.Start(ctx, "transaction.Service.List")
+	defer func() { tracing.End(span, err) }()
 
-	acc, err := s.accountService.FindById(ctx, t.AccountID)
+	acc, err := s.accountService.FindById(ctx, filter.AccountID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if acc == nil {
-		return ErrAccountNotFound
+		return nil, ErrAccountNotFound
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultPageSize
 	}
 
-	if _, exists := Operations[t.OperationTypeID]; !exists {
-		return ErrOperationTypeNotFound
+	if filter.Limit > MaxPageSize {
+		filter.Limit = MaxPageSize
 	}
 
-	if slices.Contains(negAmountTransactions, t.OperationTypeID) {
-		t.Amount = t.Amount.Abs().Neg()
+	limit := filter.Limit
 
-		if acc.AvailableCreditLimit.Add(t.Amount).LessThan(decimal.Zero) {
-			return ErrInsuficientFunds
-		}
-	} else {
-		t.Amount = t.Amount.Abs()
+	// fetch one extra row to find out whether there is a next page
+	filter.Limit++
+
+	transactions, err := s.repository.FindByAccount(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Transactions: transactions}
+
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		last := page.Transactions[limit-1]
+		page.NextCursor = Cursor{OperationDate: last.OperationDate, ID: last.ID}.Encode()
 	}
 
-	t.OperationDate = s.clock.Now()
+	return page, nil
+}
+
+// Create books the transaction, its ledger entry and moves the account's available limit in a single unit of work.
+// The account row stays locked until it commits, so concurrent debits can't overspend the limit.
+func (s *Service) Create(ctx context.Context, t *Transaction) (err error) {
+	ctx, span := tracer.Start(ctx, "transaction.Service.Create")
+	defer func() { tracing.End(span, err) }()
+
+	return s.create(ctx, t, false)
+}
+
+// Charge books a fee the application charges by itself, with one of the internal debit operation types. Unlike
+// Create, it charges blocked accounts too and doesn't check the available limit, which fees can take below zero,
+// and keeps the operation date when one is given.
+func (s *Service) Charge(ctx context.Context, t *Transaction) (err error) {
+	ctx, span := tracer.Start(ctx, "transaction.Service.Charge")
+	defer func() { tracing.End(span, err) }()
+
+	return s.create(ctx, t, true)
+}
+
+func (s *Service) create(ctx context.Context, t *Transaction, internal bool) error {
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		acc, err := s.accountService.FindByIdForUpdate(ctx, t.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if acc == nil {
+			return ErrAccountNotFound
+		}
+
+		if acc.IsClosed() {
+			return ErrAccountClosed
+		}
+
+		operationType, err := s.operationTypeService.FindById(ctx, t.OperationTypeID)
+		if err != nil {
+			return err
+		}
+
+		if operationType == nil || !operationType.Active {
+			return ErrOperationTypeNotFound
+		}
+
+		if operationType.Internal != internal || (internal && !operationType.IsDebit()) {
+			return ErrOperationTypeNotAllowed
+		}
+
+		if t.OperationTypeID == OperationTypeInstallmentBuy {
+			t.Installments = max(t.Installments, 1)
+
+			if err = installment.Validate(t.Installments, t.InterestRate); err != nil {
+				return err
+			}
+		} else if t.Installments > 1 || !t.InterestRate.IsZero() {
+			return ErrInstallmentsNotAllowed
+		}
+
+		var discharges []Discharge
+
+		t.Amount = operationType.SignedAmount(t.Amount)
 
-	acc.AvailableCreditLimit = acc.AvailableCreditLimit.Add(t.Amount)
+		if operationType.IsDebit() {
+			// blocked accounts can still receive payments, so customers can settle what they owe
+			if acc.IsBlocked() && !internal {
+				return ErrAccountBlocked
+			}
 
-	if err = s.accountService.UpdateCreditLimit(ctx, acc); err != nil {
+			t.Balance = t.Amount
+
+			if !internal && operationType.ConsumesCreditLimit && acc.AvailableCreditLimit.Add(t.Amount).LessThan(decimal.Zero) {
+				return ErrInsuficientFunds
+			}
+		} else {
+			if discharges, err = s.discharge(ctx, t); err != nil {
+				return err
+			}
+		}
+
+		// internal charges may be dated on the day they refer to, like fees accrued for days already gone by
+		if !internal || t.OperationDate.IsZero() {
+			t.OperationDate = s.clock.Now()
+		}
+
+		t.Status = StatusPosted
+
+		if operationType.ConsumesCreditLimit {
+			acc.AvailableCreditLimit = acc.AvailableCreditLimit.Add(t.Amount)
+
+			if err = s.accountService.UpdateCreditLimit(ctx, acc); err != nil {
+				return err
+			}
+		}
+
+		if err = s.repository.Create(ctx, t); err != nil {
+			return err
+		}
+
+		if t.OperationTypeID == OperationTypeInstallmentBuy {
+			_, err = s.installmentService.CreatePlan(ctx, t.ID, t.AccountID, t.Amount.Abs(), t.Installments, t.InterestRate, t.OperationDate)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(discharges) > 0 {
+			for i := range discharges {
+				discharges[i].PaymentTransactionID = t.ID
+			}
+
+			if err = s.repository.CreateDischarges(ctx, discharges); err != nil {
+				return err
+			}
+		}
+
+		if err = s.ledgerService.Record(ctx, ledger.EntryForTransaction(t.ID, t.AccountID, t.Amount, t.Balance)); err != nil {
+			return err
+		}
+
+		return s.outboxService.Record(ctx, outbox.EventTransactionCreated, outbox.AggregateTransaction, t.ID, newTransactionCreatedV1(t))
+	})
+
+	if err != nil {
+		s.metrics.record(t, err)
 		return err
 	}
 
-	return s.repository.Create(ctx, t)
+	// the caller's transaction, if any, may still roll it back, so it only counts once everything commits
+	s.txManager.AfterCommit(ctx, func() { s.metrics.record(t, nil) })
+
+	return nil
+}
+
+// Reverse refunds amount of a purchase or withdraw, or whatever is left of it when amount is nil. The refund is
+// booked as a reversal transaction linked to the original one, restores the available limit and settles what is
+// still owed on the original transaction first.
+func (s *Service) Reverse(ctx context.Context, id int, amount *decimal.Decimal) (_ *Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "transaction.Service.Reverse")
+	defer func() { tracing.End(span, err) }()
+
+	var reversal *Transaction
+
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		original, err := s.repository.FindById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if original == nil {
+			return ErrTransactionNotFound
+		}
+
+		// lock the account before the transaction, in the same order Create does, to avoid deadlocks
+		acc, err := s.accountService.FindByIdForUpdate(ctx, original.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if acc == nil {
+			return ErrAccountNotFound
+		}
+
+		if acc.IsClosed() {
+			return ErrAccountClosed
+		}
+
+		if original, err = s.repository.FindByIdForUpdate(ctx, id); err != nil {
+			return err
+		}
+
+		if !original.Amount.IsNegative() {
+			return ErrTransactionNotReversible
+		}
+
+		reversible := original.Amount.Abs().Sub(original.ReversedAmount)
+		value := reversible
+
+		if amount != nil {
+			value = *amount
+		}
+
+		if !value.IsPositive() {
+			return ErrInvalidReversalAmount
+		}
+
+		if value.GreaterThan(reversible) {
+			return ErrReversalExceedsAmount
+		}
+
+		settled := decimal.Min(value, original.Balance.Neg())
+
+		original.Balance = original.Balance.Add(settled)
+		original.ReversedAmount = original.ReversedAmount.Add(value)
+		original.Status = StatusPartiallyReversed
+
+		if original.ReversedAmount.Equal(original.Amount.Abs()) {
+			original.Status = StatusReversed
+		}
+
+		if err = s.repository.UpdateReversal(ctx, original); err != nil {
+			return err
+		}
+
+		operationType, err := s.operationTypeService.FindById(ctx, original.OperationTypeID)
+		if err != nil {
+			return err
+		}
+
+		// only give back the limit the original transaction took
+		if operationType != nil && operationType.ConsumesCreditLimit {
+			acc.AvailableCreditLimit = acc.AvailableCreditLimit.Add(value)
+
+			if err = s.accountService.UpdateCreditLimit(ctx, acc); err != nil {
+				return err
+			}
+		}
+
+		reversal = &Transaction{
+			AccountID:             original.AccountID,
+			OperationTypeID:       OperationTypeReversal,
+			Amount:                value,
+			Balance:               value.Sub(settled),
+			OperationDate:         s.clock.Now(),
+			Status:                StatusPosted,
+			ReversedTransactionID: &original.ID,
+		}
+
+		if err = s.repository.Create(ctx, reversal); err != nil {
+			return err
+		}
+
+		if original.Status == StatusReversed && original.OperationTypeID == OperationTypeInstallmentBuy {
+			if err = s.installmentService.CancelPlan(ctx, original.ID); err != nil {
+				return err
+			}
+		}
+
+		if settled.IsPositive() {
+			err = s.repository.CreateDischarges(ctx, []Discharge{
+				{PaymentTransactionID: reversal.ID, TransactionID: original.ID, Amount: settled},
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		err = s.ledgerService.Record(ctx, ledger.EntryForTransaction(reversal.ID, reversal.AccountID, reversal.Amount, reversal.Balance))
+		if err != nil {
+			return err
+		}
+
+		return s.outboxService.Record(ctx, outbox.EventTransactionCreated, outbox.AggregateTransaction, reversal.ID, newTransactionCreatedV1(reversal))
+	})
+
+	if err != nil {
+		s.metrics.record(reversal, err)
+		return nil, err
+	}
+
+	s.txManager.AfterCommit(ctx, func() { s.metrics.record(reversal, nil) })
+
+	return reversal, nil
+}
+
+func (s *Service) FindInstallmentPlan(ctx context.Context, transactionID int) (_ *installment.InstallmentPlan, err error) {
+	ctx, span := tracer.Start(ctx, "transaction.Service.FindInstallmentPlan")
+	defer func() { tracing.End(span, err) }()
+
+	return s.installmentService.FindPlanByTransaction(ctx, transactionID)
+}
+
+func (s *Service) FindDischarges(ctx context.Context, transactionID int) (_ []Discharge, err error) {
+	ctx, span := tracer.Start(ctx, "transaction.Service.FindDischarges")
+	defer func() { tracing.End(span, err) }()
+
+	return s.repository.FindDischargesByTransaction(ctx, transactionID)
+}
+
+// discharge settles the account's negative transactions, oldest first, with the amount of payment p. Whatever
+// isn't used up is left as p's positive balance.
+func (s *Service) discharge(ctx context.Context, p *Transaction) ([]Discharge, error) {
+	var discharges []Discharge
+
+	outstanding, err := s.repository.FindOutstandingByAccount(ctx, p.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := p.Amount
+
+	for _, o := range outstanding {
+		if !remaining.IsPositive() {
+			break
+		}
+
+		amount := decimal.Min(remaining, o.Balance.Neg())
+		o.Balance = o.Balance.Add(amount)
+		remaining = remaining.Sub(amount)
+
+		if err = s.repository.UpdateBalance(ctx, &o); err != nil {
+			return nil, err
+		}
+
+		discharges = append(discharges, Discharge{TransactionID: o.ID, Amount: amount})
+	}
+
+	p.Balance = remaining
+
+	return discharges, nil
 }
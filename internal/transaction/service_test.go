@@ -4,10 +4,20 @@ import (
 	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/supwr/pismo-transactions/internal/account"
+	"github.com/supwr/pismo-transactions/internal/installment"
+	"github.com/supwr/pismo-transactions/internal/ledger"
+	"github.com/supwr/pismo-transactions/internal/operationtype"
+	"github.com/supwr/pismo-transactions/internal/outbox"
+	"github.com/supwr/pismo-transactions/internal/testutil"
+	"github.com/supwr/pismo-transactions/pkg/clock"
 	clockmock "github.com/supwr/pismo-transactions/pkg/clock/mock"
+	dbmock "github.com/supwr/pismo-transactions/pkg/database/mock"
+	"sync"
 	"testing"
 	"time"
 )
@@ -17,7 +27,9 @@ func TestService_Create(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		accountRepo := account.NewMockRepositoryInterface(ctrl)
 		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
 		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
 		ctx := context.Background()
 
 		acc := &account.Account{
@@ -28,25 +40,29 @@ func TestService_Create(t *testing.T) {
 
 		operationCashBuy := OperationTypeCashBuy
 
-		findAccountById := accountRepo.EXPECT().FindById(ctx, 1).Return(acc, nil).Times(1)
+		findAccountById := accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
 
 		transactionDate := time.Now()
 		transaction := &Transaction{
 			AccountID:       1,
 			OperationTypeID: operationCashBuy,
 			Amount:          decimal.NewFromFloat(float64(123.45)).Neg(),
+			Balance:         decimal.NewFromFloat(float64(123.45)).Neg(),
 			OperationDate:   transactionDate,
+			Status:          StatusPosted,
 		}
 
 		updatedAccount := *acc
 		updatedAccount.AvailableCreditLimit = updatedAccount.AvailableCreditLimit.Add(transaction.Amount)
 
 		clock := clockMock.EXPECT().Now().Return(transactionDate).Times(1).After(findAccountById)
-		updateAccount := accountRepo.EXPECT().UpdateAvailableLimit(ctx, &updatedAccount).Return(nil).After(clock).Times(1)
-		transactionRepo.EXPECT().Create(ctx, transaction).Return(nil).After(clock).Times(1).After(updateAccount)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		updateAccount := accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), &updatedAccount).Return(nil).After(clock).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), transaction).Return(nil).After(clock).Times(1).After(updateAccount)
 
-		accountService := account.NewService(accountRepo)
-		transactionService := NewService(transactionRepo, accountService, clockMock)
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := NewService(transactionRepo, accountService, installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
 
 		err := transactionService.Create(ctx, &Transaction{
 			AccountID:       transaction.AccountID,
@@ -62,7 +78,9 @@ func TestService_Create(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		accountRepo := account.NewMockRepositoryInterface(ctrl)
 		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
 		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
 		ctx := context.Background()
 
 		acc := &account.Account{
@@ -71,25 +89,30 @@ func TestService_Create(t *testing.T) {
 			AvailableCreditLimit: decimal.NewFromInt(1000),
 		}
 
-		findAccountById := accountRepo.EXPECT().FindById(ctx, 1).Return(acc, nil).Times(1)
+		findAccountById := accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
 
 		transactionDate := time.Now()
 		transaction := &Transaction{
 			AccountID:       1,
 			OperationTypeID: OperationTypePayment,
 			Amount:          decimal.NewFromFloat(float64(123.45)),
+			Balance:         decimal.NewFromFloat(float64(123.45)),
 			OperationDate:   transactionDate,
+			Status:          StatusPosted,
 		}
 
+		transactionRepo.EXPECT().FindOutstandingByAccount(gomock.Any(), 1).Return(nil, nil).After(findAccountById).Times(1)
 		clock := clockMock.EXPECT().Now().Return(transactionDate).Times(1).After(findAccountById)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
 
 		updatedAccount := *acc
 		updatedAccount.AvailableCreditLimit = updatedAccount.AvailableCreditLimit.Add(transaction.Amount)
-		updateAccount := accountRepo.EXPECT().UpdateAvailableLimit(ctx, &updatedAccount).Return(nil).After(clock).Times(1)
-		transactionRepo.EXPECT().Create(ctx, transaction).Return(nil).After(clock).Times(1).After(updateAccount)
+		updateAccount := accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), &updatedAccount).Return(nil).After(clock).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), transaction).Return(nil).After(clock).Times(1).After(updateAccount)
 
-		accountService := account.NewService(accountRepo)
-		transactionService := NewService(transactionRepo, accountService, clockMock)
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := NewService(transactionRepo, accountService, installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
 
 		err := transactionService.Create(ctx, &Transaction{
 			AccountID:       transaction.AccountID,
@@ -105,11 +128,15 @@ func TestService_Create(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		accountRepo := account.NewMockRepositoryInterface(ctrl)
 		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
 		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
 		expectedError := errors.New("database error")
 		transactionDate := time.Now()
 		ctx := context.Background()
 
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+
 		transaction := &Transaction{
 			AccountID:       1,
 			OperationTypeID: OperationTypeCashBuy,
@@ -117,10 +144,10 @@ func TestService_Create(t *testing.T) {
 			OperationDate:   transactionDate,
 		}
 
-		accountRepo.EXPECT().FindById(ctx, 1).Return(nil, expectedError).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(nil, expectedError).Times(1)
 
-		accountService := account.NewService(accountRepo)
-		transactionService := NewService(transactionRepo, accountService, clockMock)
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := NewService(transactionRepo, accountService, installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
 
 		err := transactionService.Create(ctx, &Transaction{
 			AccountID:       transaction.AccountID,
@@ -136,10 +163,14 @@ func TestService_Create(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		accountRepo := account.NewMockRepositoryInterface(ctrl)
 		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
 		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
 		transactionDate := time.Now()
 		ctx := context.Background()
 
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+
 		transaction := &Transaction{
 			AccountID:       1,
 			OperationTypeID: OperationTypeCashBuy,
@@ -147,10 +178,10 @@ func TestService_Create(t *testing.T) {
 			OperationDate:   transactionDate,
 		}
 
-		accountRepo.EXPECT().FindById(ctx, 1).Return(nil, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(nil, nil).Times(1)
 
-		accountService := account.NewService(accountRepo)
-		transactionService := NewService(transactionRepo, accountService, clockMock)
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := NewService(transactionRepo, accountService, installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
 
 		err := transactionService.Create(ctx, &Transaction{
 			AccountID:       transaction.AccountID,
@@ -166,16 +197,20 @@ func TestService_Create(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		accountRepo := account.NewMockRepositoryInterface(ctrl)
 		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
 		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
 		ctx := context.Background()
 
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+
 		acc := &account.Account{
 			ID:                   1,
 			Document:             "123456",
 			AvailableCreditLimit: decimal.NewFromInt(1000),
 		}
 
-		accountRepo.EXPECT().FindById(ctx, 1).Return(acc, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
 
 		transactionDate := time.Now()
 		transaction := &Transaction{
@@ -185,8 +220,8 @@ func TestService_Create(t *testing.T) {
 			OperationDate:   transactionDate,
 		}
 
-		accountService := account.NewService(accountRepo)
-		transactionService := NewService(transactionRepo, accountService, clockMock)
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := NewService(transactionRepo, accountService, installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
 
 		err := transactionService.Create(ctx, &Transaction{
 			AccountID:       transaction.AccountID,
@@ -198,11 +233,127 @@ func TestService_Create(t *testing.T) {
 		assert.ErrorIs(t, err, ErrOperationTypeNotFound)
 	})
 
+	t.Run("inactive operation type error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&account.Account{ID: 1, AvailableCreditLimit: decimal.NewFromInt(1000)}, nil).Times(1)
+
+		operationTypeService := testutil.NewOperationTypeService(ctrl, seededOperationTypes(operationtype.OperationType{
+			ID: 10, Description: "SEGURO", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: false,
+		})...)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), operationTypeService, newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		err := transactionService.Create(ctx, &Transaction{AccountID: 1, OperationTypeID: 10, Amount: decimal.NewFromInt(10)})
+
+		assert.ErrorIs(t, err, ErrOperationTypeNotFound)
+	})
+
+	t.Run("operation type that doesn't consume the credit limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		transactionDate := time.Now()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&account.Account{ID: 1, AvailableCreditLimit: decimal.Zero}, nil).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Times(0)
+		clockMock.EXPECT().Now().Return(transactionDate).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), &Transaction{
+			AccountID:       1,
+			OperationTypeID: 10,
+			Amount:          decimal.NewFromInt(-10),
+			Balance:         decimal.NewFromInt(-10),
+			OperationDate:   transactionDate,
+			Status:          StatusPosted,
+		}).Return(nil).Times(1)
+
+		operationTypeService := testutil.NewOperationTypeService(ctrl, seededOperationTypes(operationtype.OperationType{
+			ID: 10, Description: "TARIFA", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: false, Active: true,
+		})...)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), operationTypeService, newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		err := transactionService.Create(ctx, &Transaction{AccountID: 1, OperationTypeID: 10, Amount: decimal.NewFromInt(10)})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("account status errors", func(t *testing.T) {
+		cases := map[string]struct {
+			status          string
+			operationTypeID int
+			expectedErr     error
+		}{
+			"debit on blocked account":  {account.StatusBlocked, OperationTypeCashBuy, ErrAccountBlocked},
+			"debit on closed account":   {account.StatusClosed, OperationTypeWithdraw, ErrAccountClosed},
+			"payment on closed account": {account.StatusClosed, OperationTypePayment, ErrAccountClosed},
+		}
+
+		for name, c := range cases {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				accountRepo := account.NewMockRepositoryInterface(ctrl)
+				transactionRepo := NewMockRepositoryInterface(ctrl)
+				installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+				clockMock := clockmock.NewMockClock(ctrl)
+				txManager := dbmock.NewMockTxManager(ctrl)
+				ctx := context.Background()
+
+				txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+				accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&account.Account{ID: 1, Status: c.status, AvailableCreditLimit: decimal.NewFromInt(1000)}, nil).Times(1)
+				accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Times(0)
+				transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+				transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+				err := transactionService.Create(ctx, &Transaction{AccountID: 1, OperationTypeID: c.operationTypeID, Amount: decimal.NewFromInt(10)})
+
+				assert.ErrorIs(t, err, c.expectedErr)
+			})
+		}
+	})
+
+	t.Run("blocked account still accepts payments", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&account.Account{ID: 1, Status: account.StatusBlocked, AvailableCreditLimit: decimal.Zero}, nil).Times(1)
+		transactionRepo.EXPECT().FindOutstandingByAccount(gomock.Any(), 1).Return(nil, nil).Times(1)
+		clockMock.EXPECT().Now().Return(time.Now()).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		err := transactionService.Create(ctx, &Transaction{AccountID: 1, OperationTypeID: OperationTypePayment, Amount: decimal.NewFromInt(10)})
+
+		assert.Nil(t, err)
+	})
+
 	t.Run("create installment buy transaction successfully", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		accountRepo := account.NewMockRepositoryInterface(ctrl)
 		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
 		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
 		ctx := context.Background()
 
 		acc := &account.Account{
@@ -211,26 +362,32 @@ func TestService_Create(t *testing.T) {
 			AvailableCreditLimit: decimal.NewFromInt(1000),
 		}
 
-		findAccountById := accountRepo.EXPECT().FindById(ctx, 1).Return(acc, nil).Times(1)
+		findAccountById := accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
 
 		transactionDate := time.Now()
 		transaction := &Transaction{
 			AccountID:       1,
 			OperationTypeID: OperationTypeInstallmentBuy,
 			Amount:          decimal.NewFromFloat(float64(657.89)).Neg(),
+			Balance:         decimal.NewFromFloat(float64(657.89)).Neg(),
 			OperationDate:   transactionDate,
+			Status:          StatusPosted,
+			Installments:    1,
 		}
 
 		clock := clockMock.EXPECT().Now().Return(transactionDate).Times(1).After(findAccountById)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
 
 		updatedAccount := *acc
 		updatedAccount.AvailableCreditLimit = updatedAccount.AvailableCreditLimit.Add(transaction.Amount)
 
-		updateAccount := accountRepo.EXPECT().UpdateAvailableLimit(ctx, &updatedAccount).Return(nil).After(clock).Times(1)
-		transactionRepo.EXPECT().Create(ctx, transaction).Return(nil).After(clock).Times(1).After(updateAccount)
+		updateAccount := accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), &updatedAccount).Return(nil).After(clock).Times(1)
+		createTransaction := transactionRepo.EXPECT().Create(gomock.Any(), transaction).Return(nil).After(clock).Times(1).After(updateAccount)
+		installmentRepo.EXPECT().CreatePlan(gomock.Any(), gomock.Any()).Return(nil).After(createTransaction).Times(1)
 
-		accountService := account.NewService(accountRepo)
-		transactionService := NewService(transactionRepo, accountService, clockMock)
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := NewService(transactionRepo, accountService, installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
 
 		err := transactionService.Create(ctx, &Transaction{
 			AccountID:       transaction.AccountID,
@@ -242,11 +399,124 @@ func TestService_Create(t *testing.T) {
 		assert.Nil(t, err)
 	})
 
+	t.Run("create installment buy with installment plan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		acc := &account.Account{
+			ID:                   1,
+			Document:             "123456",
+			AvailableCreditLimit: decimal.NewFromInt(1000),
+		}
+
+		transactionDate := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		clockMock.EXPECT().Now().Return(transactionDate).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, a *account.Account) error {
+			assert.Equal(t, "700.00", a.AvailableCreditLimit.StringFixed(2))
+			return nil
+		}).Times(1)
+		createTransaction := transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, tr *Transaction) error {
+			tr.ID = 7
+			return nil
+		}).Times(1)
+		installmentRepo.EXPECT().CreatePlan(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, plan *installment.InstallmentPlan) error {
+			assert.Equal(t, 7, plan.TransactionID)
+			assert.Equal(t, 3, plan.InstallmentCount)
+			assert.True(t, plan.Principal.Equal(decimal.NewFromInt(300)))
+			assert.Equal(t, "330.48", plan.Total.StringFixed(2))
+			assert.Equal(t, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), plan.Installments[0].DueDate)
+			return nil
+		}).After(createTransaction).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+
+		err := transactionService.Create(ctx, &Transaction{
+			AccountID:       1,
+			OperationTypeID: OperationTypeInstallmentBuy,
+			Amount:          decimal.NewFromInt(300),
+			Installments:    3,
+			InterestRate:    decimal.NewFromFloat(0.05),
+		})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("invalid installment count error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		acc := &account.Account{
+			ID:                   1,
+			Document:             "123456",
+			AvailableCreditLimit: decimal.NewFromInt(1000),
+		}
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+
+		err := transactionService.Create(ctx, &Transaction{
+			AccountID:       1,
+			OperationTypeID: OperationTypeInstallmentBuy,
+			Amount:          decimal.NewFromInt(300),
+			Installments:    installment.MaxInstallments + 1,
+		})
+
+		assert.ErrorIs(t, err, installment.ErrInvalidInstallmentCount)
+	})
+
+	t.Run("installments not allowed error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		acc := &account.Account{
+			ID:                   1,
+			Document:             "123456",
+			AvailableCreditLimit: decimal.NewFromInt(1000),
+		}
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+
+		err := transactionService.Create(ctx, &Transaction{
+			AccountID:       1,
+			OperationTypeID: OperationTypeCashBuy,
+			Amount:          decimal.NewFromInt(300),
+			Installments:    3,
+		})
+
+		assert.ErrorIs(t, err, ErrInstallmentsNotAllowed)
+	})
+
 	t.Run("create withdraw transaction successfully", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		accountRepo := account.NewMockRepositoryInterface(ctrl)
 		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
 		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
 		ctx := context.Background()
 
 		acc := &account.Account{
@@ -255,26 +525,30 @@ func TestService_Create(t *testing.T) {
 			AvailableCreditLimit: decimal.NewFromInt(1000),
 		}
 
-		findAccountById := accountRepo.EXPECT().FindById(ctx, 1).Return(acc, nil).Times(1)
+		findAccountById := accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
 
 		transactionDate := time.Now()
 		transaction := &Transaction{
 			AccountID:       1,
 			OperationTypeID: OperationTypeWithdraw,
 			Amount:          decimal.NewFromFloat(float64(654.32)).Neg(),
+			Balance:         decimal.NewFromFloat(float64(654.32)).Neg(),
 			OperationDate:   transactionDate,
+			Status:          StatusPosted,
 		}
 
 		clock := clockMock.EXPECT().Now().Return(transactionDate).Times(1).After(findAccountById)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
 
 		updatedAccount := *acc
 		updatedAccount.AvailableCreditLimit = updatedAccount.AvailableCreditLimit.Add(transaction.Amount)
 
-		updateAccount := accountRepo.EXPECT().UpdateAvailableLimit(ctx, &updatedAccount).Return(nil).After(clock).Times(1)
-		transactionRepo.EXPECT().Create(ctx, transaction).Return(nil).After(clock).Times(1).After(updateAccount)
+		updateAccount := accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), &updatedAccount).Return(nil).After(clock).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), transaction).Return(nil).After(clock).Times(1).After(updateAccount)
 
-		accountService := account.NewService(accountRepo)
-		transactionService := NewService(transactionRepo, accountService, clockMock)
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := NewService(transactionRepo, accountService, installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
 
 		err := transactionService.Create(ctx, &Transaction{
 			AccountID:       transaction.AccountID,
@@ -285,4 +559,1016 @@ func TestService_Create(t *testing.T) {
 
 		assert.Nil(t, err)
 	})
+
+	t.Run("error updating account limit does not create transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		expectedError := errors.New("database error")
+		ctx := context.Background()
+
+		acc := &account.Account{
+			ID:                   1,
+			Document:             "123456",
+			AvailableCreditLimit: decimal.NewFromInt(1000),
+		}
+
+		findAccountById := accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+
+		transactionDate := time.Now()
+		clock := clockMock.EXPECT().Now().Return(transactionDate).Times(1).After(findAccountById)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(expectedError).After(clock).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := NewService(transactionRepo, accountService, installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+
+		err := transactionService.Create(ctx, &Transaction{
+			AccountID:       1,
+			OperationTypeID: OperationTypeCashBuy,
+			Amount:          decimal.NewFromFloat(float64(123.45)),
+		})
+
+		assert.ErrorIs(t, err, expectedError)
+	})
+
+	t.Run("error creating transaction is returned to the unit of work", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		expectedError := errors.New("database error")
+		ctx := context.Background()
+
+		acc := &account.Account{
+			ID:                   1,
+			Document:             "123456",
+			AvailableCreditLimit: decimal.NewFromInt(1000),
+		}
+
+		findAccountById := accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+
+		transactionDate := time.Now()
+		clock := clockMock.EXPECT().Now().Return(transactionDate).Times(1).After(findAccountById)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			err := fn(ctx)
+			assert.ErrorIs(t, err, expectedError)
+			return err
+		}).Times(1)
+		updateAccount := accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).After(clock).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(expectedError).After(updateAccount).Times(1)
+
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := NewService(transactionRepo, accountService, installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+
+		err := transactionService.Create(ctx, &Transaction{
+			AccountID:       1,
+			OperationTypeID: OperationTypeCashBuy,
+			Amount:          decimal.NewFromFloat(float64(123.45)),
+		})
+
+		assert.ErrorIs(t, err, expectedError)
+	})
+
+	t.Run("error recording ledger entry is returned to the unit of work", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		ledgerRepo := ledger.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		expectedError := errors.New("database error")
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			err := fn(ctx)
+			assert.ErrorIs(t, err, expectedError)
+			return err
+		}).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&account.Account{ID: 1, AvailableCreditLimit: decimal.NewFromInt(1000)}, nil).Times(1)
+		clockMock.EXPECT().Now().Return(time.Now()).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		create := transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, t *Transaction) error {
+			t.ID = 1
+			return nil
+		}).Times(1)
+		ledgerRepo.EXPECT().CreateEntry(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, e *ledger.JournalEntry) error {
+			assert.Equal(t, 1, e.TransactionID)
+			assert.Equal(t, ledger.LedgerReceivables, e.Postings[0].Ledger)
+			assert.Equal(t, ledger.DirectionDebit, e.Postings[0].Direction)
+			assert.True(t, e.Postings[0].Amount.Equal(decimal.NewFromInt(10)))
+			return expectedError
+		}).After(create).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), ledger.NewService(ledgerRepo), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		err := transactionService.Create(ctx, &Transaction{AccountID: 1, OperationTypeID: OperationTypeCashBuy, Amount: decimal.NewFromInt(10)})
+
+		assert.ErrorIs(t, err, expectedError)
+	})
+	t.Run("record a TransactionCreated event with the transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		outboxRepo := outbox.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		acc := &account.Account{ID: 1, AvailableCreditLimit: decimal.NewFromInt(1000)}
+
+		clockMock.EXPECT().Now().Return(time.Now()).Times(1)
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		create := transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, t *Transaction) error {
+			t.ID = 9
+			return nil
+		}).Times(1)
+		outboxRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, e *outbox.Event) error {
+			assert.Equal(t, outbox.EventTransactionCreated, e.Type)
+			assert.Equal(t, outbox.AggregateTransaction, e.AggregateType)
+			assert.Equal(t, 9, e.AggregateID)
+			assert.Equal(t, 1, e.Version)
+			assert.Contains(t, string(e.Payload), `"account_id":1`)
+			assert.NotContains(t, string(e.Payload), "deleted_at")
+			return nil
+		}).After(create).Times(1)
+
+		outboxService := outbox.NewService(outboxRepo, clock.NewClock())
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, outboxService, clockMock, txManager), installment.NewService(installment.NewMockRepositoryInterface(ctrl)), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), outboxService, newMetrics(), clockMock, txManager)
+
+		err := transactionService.Create(ctx, &Transaction{AccountID: 1, OperationTypeID: OperationTypeCashBuy, Amount: decimal.NewFromInt(10)})
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_Charge(t *testing.T) {
+	lateFee := operationtype.OperationType{ID: 6, Description: "MULTA POR ATRASO", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true, Internal: true}
+
+	t.Run("charge fee past the available limit of a blocked account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+		now := time.Now()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&account.Account{
+			ID:                   1,
+			Status:               account.StatusBlocked,
+			AvailableCreditLimit: decimal.NewFromInt(5),
+		}, nil).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, a *account.Account) error {
+			assert.True(t, decimal.NewFromInt(-5).Equal(a.AvailableCreditLimit))
+			return nil
+		}).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, tr *Transaction) error {
+			assert.True(t, decimal.NewFromInt(-10).Equal(tr.Amount))
+			assert.True(t, decimal.NewFromInt(-10).Equal(tr.Balance))
+			return nil
+		}).Times(1)
+
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := NewService(transactionRepo, accountService, installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes(lateFee)...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+
+		err := transactionService.Charge(ctx, &Transaction{AccountID: 1, OperationTypeID: lateFee.ID, Amount: decimal.NewFromInt(10)})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("only internal debits can be charged", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(2)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&account.Account{ID: 1, Status: account.StatusActive}, nil).Times(2)
+
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := NewService(transactionRepo, accountService, installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes(lateFee)...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+
+		err := transactionService.Charge(ctx, &Transaction{AccountID: 1, OperationTypeID: OperationTypeCashBuy, Amount: decimal.NewFromInt(10)})
+		assert.ErrorIs(t, err, ErrOperationTypeNotAllowed)
+
+		err = transactionService.Charge(ctx, &Transaction{AccountID: 1, OperationTypeID: OperationTypeReversal, Amount: decimal.NewFromInt(10)})
+		assert.ErrorIs(t, err, ErrOperationTypeNotAllowed)
+	})
+}
+
+func TestService_CreatePaymentDischarge(t *testing.T) {
+	newOutstanding := func() []Transaction {
+		date := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+		return []Transaction{
+			{ID: 1, AccountID: 1, OperationTypeID: OperationTypeCashBuy, Amount: decimal.NewFromInt(-50), Balance: decimal.NewFromInt(-50), OperationDate: date},
+			{ID: 2, AccountID: 1, OperationTypeID: OperationTypeInstallmentBuy, Amount: decimal.NewFromFloat(-23.5), Balance: decimal.NewFromFloat(-23.5), OperationDate: date.AddDate(0, 0, 1)},
+			{ID: 3, AccountID: 1, OperationTypeID: OperationTypeWithdraw, Amount: decimal.NewFromFloat(-18.7), Balance: decimal.NewFromFloat(-18.7), OperationDate: date.AddDate(0, 0, 2)},
+		}
+	}
+
+	t.Run("payment discharges oldest transactions first and partially settles the last one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		acc := &account.Account{ID: 1, Document: "123456", AvailableCreditLimit: decimal.NewFromInt(1000)}
+		outstanding := newOutstanding()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		transactionRepo.EXPECT().FindOutstandingByAccount(gomock.Any(), 1).Return(outstanding, nil).Times(1)
+
+		expectedBalances := map[int]decimal.Decimal{1: decimal.Zero, 2: decimal.NewFromFloat(-13.5)}
+		transactionRepo.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, o *Transaction) error {
+			assert.True(t, expectedBalances[o.ID].Equal(o.Balance), o.Balance.String())
+			delete(expectedBalances, o.ID)
+			return nil
+		}).Times(2)
+		clockMock.EXPECT().Now().Return(time.Now()).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		create := transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, p *Transaction) error {
+			assert.True(t, p.Balance.IsZero())
+			p.ID = 4
+			return nil
+		}).Times(1)
+
+		createDischarges := transactionRepo.EXPECT().CreateDischarges(gomock.Any(), []Discharge{
+			{PaymentTransactionID: 4, TransactionID: 1, Amount: decimal.NewFromInt(50)},
+			{PaymentTransactionID: 4, TransactionID: 2, Amount: decimal.NewFromInt(10)},
+		}).Return(nil).After(create).Times(1)
+
+		ledgerRepo := ledger.NewMockRepositoryInterface(ctrl)
+		ledgerRepo.EXPECT().CreateEntry(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, e *ledger.JournalEntry) error {
+			assert.Equal(t, 4, e.TransactionID)
+			assert.Len(t, e.Postings, 2)
+			assert.Equal(t, ledger.LedgerCash, e.Postings[0].Ledger)
+			assert.Equal(t, ledger.LedgerReceivables, e.Postings[1].Ledger)
+			assert.True(t, e.Postings[1].Amount.Equal(decimal.NewFromInt(60)))
+			return nil
+		}).After(createDischarges).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), ledger.NewService(ledgerRepo), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		err := transactionService.Create(ctx, &Transaction{AccountID: 1, OperationTypeID: OperationTypePayment, Amount: decimal.NewFromInt(60)})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("payment leftover is kept as positive balance", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		acc := &account.Account{ID: 1, Document: "123456", AvailableCreditLimit: decimal.NewFromInt(1000)}
+		outstanding := newOutstanding()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		transactionRepo.EXPECT().FindOutstandingByAccount(gomock.Any(), 1).Return(outstanding, nil).Times(1)
+		transactionRepo.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, o *Transaction) error {
+			assert.True(t, o.Balance.IsZero())
+			return nil
+		}).Times(3)
+		clockMock.EXPECT().Now().Return(time.Now()).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		create := transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, p *Transaction) error {
+			assert.True(t, p.Balance.Equal(decimal.NewFromFloat(7.8)), p.Balance.String())
+			p.ID = 4
+			return nil
+		}).Times(1)
+
+		transactionRepo.EXPECT().CreateDischarges(gomock.Any(), []Discharge{
+			{PaymentTransactionID: 4, TransactionID: 1, Amount: decimal.NewFromInt(50)},
+			{PaymentTransactionID: 4, TransactionID: 2, Amount: decimal.NewFromFloat(23.5)},
+			{PaymentTransactionID: 4, TransactionID: 3, Amount: decimal.NewFromFloat(18.7)},
+		}).Return(nil).After(create).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		err := transactionService.Create(ctx, &Transaction{AccountID: 1, OperationTypeID: OperationTypePayment, Amount: decimal.NewFromInt(100)})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("error updating discharged balance", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		expectedErr := errors.New("database error")
+		ctx := context.Background()
+
+		acc := &account.Account{ID: 1, Document: "123456", AvailableCreditLimit: decimal.NewFromInt(1000)}
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		transactionRepo.EXPECT().FindOutstandingByAccount(gomock.Any(), 1).Return(newOutstanding(), nil).Times(1)
+		transactionRepo.EXPECT().UpdateBalance(gomock.Any(), gomock.Any()).Return(expectedErr).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		err := transactionService.Create(ctx, &Transaction{AccountID: 1, OperationTypeID: OperationTypePayment, Amount: decimal.NewFromInt(100)})
+
+		assert.ErrorIs(t, err, expectedErr)
+	})
+}
+
+func TestService_FindDischarges(t *testing.T) {
+	t.Run("find discharges successfully", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		discharges := []Discharge{{ID: 1, PaymentTransactionID: 4, TransactionID: 1, Amount: decimal.NewFromInt(50)}}
+
+		transactionRepo.EXPECT().FindDischargesByTransaction(gomock.Any(), 4).Return(discharges, nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		d, err := transactionService.FindDischarges(ctx, 4)
+
+		assert.Nil(t, err)
+		assert.Equal(t, discharges, d)
+	})
+}
+
+func TestService_Reverse(t *testing.T) {
+	newPurchase := func(operationTypeID int) *Transaction {
+		return &Transaction{
+			ID:              7,
+			AccountID:       1,
+			OperationTypeID: operationTypeID,
+			Amount:          decimal.NewFromInt(-100),
+			Balance:         decimal.NewFromInt(-40),
+			Status:          StatusPosted,
+			ReversedAmount:  decimal.Zero,
+			OperationDate:   time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+		}
+	}
+
+	t.Run("full reversal settles the original and restores the limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		acc := &account.Account{ID: 1, Document: "123456", AvailableCreditLimit: decimal.NewFromInt(900)}
+		reversalDate := time.Date(2024, 1, 20, 10, 0, 0, 0, time.UTC)
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		findTransaction := transactionRepo.EXPECT().FindById(gomock.Any(), 7).Return(newPurchase(OperationTypeCashBuy), nil).Times(1)
+		lockAccount := accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).After(findTransaction).Times(1)
+		transactionRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 7).Return(newPurchase(OperationTypeCashBuy), nil).After(lockAccount).Times(1)
+		transactionRepo.EXPECT().UpdateReversal(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, o *Transaction) error {
+			assert.Equal(t, StatusReversed, o.Status)
+			assert.True(t, o.ReversedAmount.Equal(decimal.NewFromInt(100)))
+			assert.True(t, o.Balance.IsZero())
+			return nil
+		}).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, a *account.Account) error {
+			assert.True(t, a.AvailableCreditLimit.Equal(decimal.NewFromInt(1000)))
+			return nil
+		}).Times(1)
+		clockMock.EXPECT().Now().Return(reversalDate).Times(1)
+		createReversal := transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, r *Transaction) error {
+			r.ID = 8
+			return nil
+		}).Times(1)
+		transactionRepo.EXPECT().CreateDischarges(gomock.Any(), []Discharge{
+			{PaymentTransactionID: 8, TransactionID: 7, Amount: decimal.NewFromInt(40)},
+		}).Return(nil).After(createReversal).Times(1)
+
+		ledgerRepo := ledger.NewMockRepositoryInterface(ctrl)
+		ledgerRepo.EXPECT().CreateEntry(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, e *ledger.JournalEntry) error {
+			assert.Equal(t, 8, e.TransactionID)
+			assert.Len(t, e.Postings, 3)
+			assert.True(t, e.Postings[1].Amount.Equal(decimal.NewFromInt(40)))
+			assert.Equal(t, ledger.LedgerAccount, e.Postings[2].Ledger)
+			assert.True(t, e.Postings[2].Amount.Equal(decimal.NewFromInt(60)))
+			return nil
+		}).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), ledger.NewService(ledgerRepo), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		reversal, err := transactionService.Reverse(ctx, 7, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 8, reversal.ID)
+		assert.Equal(t, OperationTypeReversal, reversal.OperationTypeID)
+		assert.Equal(t, 7, *reversal.ReversedTransactionID)
+		assert.True(t, reversal.Amount.Equal(decimal.NewFromInt(100)))
+		assert.True(t, reversal.Balance.Equal(decimal.NewFromInt(60)))
+		assert.Equal(t, reversalDate, reversal.OperationDate)
+	})
+
+	t.Run("partial reversal of an installment buy keeps its plan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		acc := &account.Account{ID: 1, Document: "123456", AvailableCreditLimit: decimal.NewFromInt(900)}
+		amount := decimal.NewFromInt(30)
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		transactionRepo.EXPECT().FindById(gomock.Any(), 7).Return(newPurchase(OperationTypeInstallmentBuy), nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		transactionRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 7).Return(newPurchase(OperationTypeInstallmentBuy), nil).Times(1)
+		transactionRepo.EXPECT().UpdateReversal(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, o *Transaction) error {
+			assert.Equal(t, StatusPartiallyReversed, o.Status)
+			assert.True(t, o.Balance.Equal(decimal.NewFromInt(-10)))
+			return nil
+		}).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		clockMock.EXPECT().Now().Return(time.Now()).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		installmentRepo.EXPECT().CancelScheduledInstallments(gomock.Any(), gomock.Any()).Times(0)
+		transactionRepo.EXPECT().CreateDischarges(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		reversal, err := transactionService.Reverse(ctx, 7, &amount)
+
+		assert.Nil(t, err)
+		assert.True(t, reversal.Balance.IsZero())
+	})
+
+	t.Run("full reversal of an installment buy cancels its plan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		acc := &account.Account{ID: 1, Document: "123456", AvailableCreditLimit: decimal.NewFromInt(900)}
+		purchase := newPurchase(OperationTypeInstallmentBuy)
+		purchase.ReversedAmount = decimal.NewFromInt(30)
+		purchase.Balance = decimal.NewFromInt(-10)
+		purchase.Status = StatusPartiallyReversed
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		txManager.EXPECT().AfterCommit(gomock.Any(), gomock.Any()).Do(testutil.RunAfterCommit).Times(1)
+		transactionRepo.EXPECT().FindById(gomock.Any(), 7).Return(purchase, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		transactionRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 7).Return(purchase, nil).Times(1)
+		transactionRepo.EXPECT().UpdateReversal(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		clockMock.EXPECT().Now().Return(time.Now()).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		installmentRepo.EXPECT().CancelScheduledInstallments(gomock.Any(), 7).Return(nil).Times(1)
+		transactionRepo.EXPECT().CreateDischarges(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		reversal, err := transactionService.Reverse(ctx, 7, nil)
+
+		assert.Nil(t, err)
+		assert.True(t, reversal.Amount.Equal(decimal.NewFromInt(70)))
+	})
+
+	t.Run("reversal amount errors", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			amount      decimal.Decimal
+			expectedErr error
+		}{
+			"exceeds reversible amount": {decimal.NewFromFloat(100.01), ErrReversalExceedsAmount},
+			"zero amount":               {decimal.Zero, ErrInvalidReversalAmount},
+			"negative amount":           {decimal.NewFromInt(-10), ErrInvalidReversalAmount},
+		} {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				accountRepo := account.NewMockRepositoryInterface(ctrl)
+				transactionRepo := NewMockRepositoryInterface(ctrl)
+				installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+				clockMock := clockmock.NewMockClock(ctrl)
+				txManager := dbmock.NewMockTxManager(ctrl)
+				ctx := context.Background()
+
+				acc := &account.Account{ID: 1, Document: "123456", AvailableCreditLimit: decimal.NewFromInt(900)}
+
+				txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+				transactionRepo.EXPECT().FindById(gomock.Any(), 7).Return(newPurchase(OperationTypeCashBuy), nil).Times(1)
+				accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+				transactionRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 7).Return(newPurchase(OperationTypeCashBuy), nil).Times(1)
+
+				transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+				reversal, err := transactionService.Reverse(ctx, 7, &tc.amount)
+
+				assert.Nil(t, reversal)
+				assert.ErrorIs(t, err, tc.expectedErr)
+			})
+		}
+	})
+
+	t.Run("payments can't be reversed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		acc := &account.Account{ID: 1, Document: "123456", AvailableCreditLimit: decimal.NewFromInt(900)}
+		payment := &Transaction{ID: 7, AccountID: 1, OperationTypeID: OperationTypePayment, Amount: decimal.NewFromInt(100)}
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		transactionRepo.EXPECT().FindById(gomock.Any(), 7).Return(payment, nil).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+		transactionRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 7).Return(payment, nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		reversal, err := transactionService.Reverse(ctx, 7, nil)
+
+		assert.Nil(t, reversal)
+		assert.ErrorIs(t, err, ErrTransactionNotReversible)
+	})
+
+	t.Run("transaction not found error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		transactionRepo.EXPECT().FindById(gomock.Any(), 7).Return(nil, nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		reversal, err := transactionService.Reverse(ctx, 7, nil)
+
+		assert.Nil(t, reversal)
+		assert.ErrorIs(t, err, ErrTransactionNotFound)
+	})
+
+	t.Run("reversal operation type can't be created directly", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		acc := &account.Account{ID: 1, Document: "123456", AvailableCreditLimit: decimal.NewFromInt(900)}
+
+		txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).Times(1)
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(acc, nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		err := transactionService.Create(ctx, &Transaction{AccountID: 1, OperationTypeID: OperationTypeReversal, Amount: decimal.NewFromInt(10)})
+
+		assert.ErrorIs(t, err, ErrOperationTypeNotAllowed)
+	})
+}
+
+func TestService_CreateWithinTransaction(t *testing.T) {
+	t.Run("transaction is only counted once the outer transaction commits", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+
+		accountRepo.EXPECT().FindByIdForUpdate(gomock.Any(), 1).Return(&account.Account{ID: 1, AvailableCreditLimit: decimal.NewFromInt(1000)}, nil).Times(2)
+		accountRepo.EXPECT().UpdateAvailableLimit(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		clockMock.EXPECT().Now().Return(time.Now()).Times(2)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+		txManager := &nestedTxManager{}
+		metrics := newMetrics()
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), metrics, clockMock, txManager)
+
+		expectedErr := errors.New("database error")
+		err := txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := transactionService.Create(ctx, &Transaction{AccountID: 1, OperationTypeID: OperationTypeCashBuy, Amount: decimal.NewFromInt(10)}); err != nil {
+				return err
+			}
+
+			// the caller fails after the transaction is booked, rolling it back
+			return expectedErr
+		})
+
+		assert.ErrorIs(t, err, expectedErr)
+		assert.Equal(t, 0, promtestutil.CollectAndCount(metrics.created))
+		assert.Equal(t, 0, promtestutil.CollectAndCount(metrics.rejected))
+
+		err = txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			return transactionService.Create(ctx, &Transaction{AccountID: 1, OperationTypeID: OperationTypeCashBuy, Amount: decimal.NewFromInt(10)})
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, float64(1), promtestutil.ToFloat64(metrics.created.WithLabelValues("1")))
+	})
+}
+
+func TestService_CreateConcurrently(t *testing.T) {
+	t.Run("parallel debits never overdraw the account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		ctx := context.Background()
+
+		accountRepo := newLockingAccountRepository(&account.Account{
+			ID:                   1,
+			Document:             "123456",
+			AvailableCreditLimit: decimal.NewFromInt(1000),
+		})
+
+		clockMock.EXPECT().Now().Return(time.Now()).AnyTimes()
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		txManager := &lockingTxManager{}
+		accountService := account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager)
+		transactionService := NewService(transactionRepo, accountService, installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var succeeded, rejected int
+
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				err := transactionService.Create(ctx, &Transaction{
+					AccountID:       1,
+					OperationTypeID: OperationTypeCashBuy,
+					Amount:          decimal.NewFromInt(100),
+				})
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if err == nil {
+					succeeded++
+					return
+				}
+
+				assert.ErrorIs(t, err, ErrInsuficientFunds)
+				rejected++
+			}()
+		}
+
+		wg.Wait()
+
+		acc, err := accountRepo.FindById(ctx, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, 10, succeeded)
+		assert.Equal(t, 40, rejected)
+		assert.True(t, acc.AvailableCreditLimit.Equal(decimal.Zero))
+	})
+}
+
+type nestedTxKey struct{}
+
+// nestedTxManager joins nested calls to the outermost one and runs the after-commit hooks only when it succeeds,
+// like the database's TxManager does.
+type nestedTxManager struct{}
+
+func (m *nestedTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(nestedTxKey{}).(*[]func()); ok {
+		return fn(ctx)
+	}
+
+	var afterCommit []func()
+	if err := fn(context.WithValue(ctx, nestedTxKey{}, &afterCommit)); err != nil {
+		return err
+	}
+
+	for _, f := range afterCommit {
+		f()
+	}
+
+	return nil
+}
+
+func (m *nestedTxManager) AfterCommit(ctx context.Context, fn func()) {
+	if afterCommit, ok := ctx.Value(nestedTxKey{}).(*[]func()); ok {
+		*afterCommit = append(*afterCommit, fn)
+		return
+	}
+
+	fn()
+}
+
+type lockingTxKey struct{}
+
+// lockingTxManager releases the row locks taken during a unit of work once it finishes, mimicking
+// how postgres holds SELECT ... FOR UPDATE locks until commit.
+type lockingTxManager struct{}
+
+func (m *lockingTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	var unlocks []func()
+	defer func() {
+		for _, unlock := range unlocks {
+			unlock()
+		}
+	}()
+
+	return fn(context.WithValue(ctx, lockingTxKey{}, &unlocks))
+}
+
+func (m *lockingTxManager) AfterCommit(ctx context.Context, fn func()) {
+	fn()
+}
+
+type lockingAccountRepository struct {
+	account.RepositoryInterface
+	mu       sync.Mutex
+	rowLocks map[int]*sync.Mutex
+	accounts map[int]account.Account
+}
+
+func newLockingAccountRepository(accounts ...*account.Account) *lockingAccountRepository {
+	r := &lockingAccountRepository{
+		rowLocks: map[int]*sync.Mutex{},
+		accounts: map[int]account.Account{},
+	}
+
+	for _, acc := range accounts {
+		r.rowLocks[acc.ID] = &sync.Mutex{}
+		r.accounts[acc.ID] = *acc
+	}
+
+	return r
+}
+
+func (r *lockingAccountRepository) FindById(ctx context.Context, id int) (*account.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	acc, ok := r.accounts[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &acc, nil
+}
+
+func (r *lockingAccountRepository) FindByIdForUpdate(ctx context.Context, id int) (*account.Account, error) {
+	if lock, ok := r.rowLocks[id]; ok {
+		lock.Lock()
+		unlocks := ctx.Value(lockingTxKey{}).(*[]func())
+		*unlocks = append(*unlocks, lock.Unlock)
+	}
+
+	acc, err := r.FindById(ctx, id)
+
+	// give other goroutines the chance to interleave between the read and the write
+	time.Sleep(time.Millisecond)
+
+	return acc, err
+}
+
+func (r *lockingAccountRepository) UpdateAvailableLimit(ctx context.Context, acc *account.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.accounts[acc.ID] = *acc
+	return nil
+}
+
+func TestService_FindById(t *testing.T) {
+	t.Run("find by id successfully", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		transaction := &Transaction{
+			ID:              1,
+			AccountID:       1,
+			OperationTypeID: OperationTypeCashBuy,
+			Amount:          decimal.NewFromInt(-10),
+			OperationDate:   time.Now(),
+		}
+
+		transactionRepo.EXPECT().FindById(gomock.Any(), 1).Return(transaction, nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		tr, err := transactionService.FindById(ctx, 1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, transaction, tr)
+	})
+
+	t.Run("transaction not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		transactionRepo.EXPECT().FindById(gomock.Any(), 1).Return(nil, nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		tr, err := transactionService.FindById(ctx, 1)
+
+		assert.Nil(t, err)
+		assert.Nil(t, tr)
+	})
+}
+
+func TestService_List(t *testing.T) {
+	acc := &account.Account{
+		ID:                   1,
+		Document:             "123456",
+		AvailableCreditLimit: decimal.NewFromInt(1000),
+	}
+
+	newTransactions := func(n int) []Transaction {
+		var transactions []Transaction
+		date := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+
+		for i := n; i > 0; i-- {
+			transactions = append(transactions, Transaction{
+				ID:              i,
+				AccountID:       1,
+				OperationTypeID: OperationTypeCashBuy,
+				Amount:          decimal.NewFromInt(-10),
+				OperationDate:   date.AddDate(0, 0, -(n - i)),
+			})
+		}
+
+		return transactions
+	}
+
+	t.Run("list first page with next cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		transactions := newTransactions(3)
+
+		findAccount := accountRepo.EXPECT().FindById(gomock.Any(), 1).Return(acc, nil).Times(1)
+		transactionRepo.EXPECT().FindByAccount(gomock.Any(), Filter{AccountID: 1, OperationTypeID: OperationTypeCashBuy, Limit: 3}).
+			Return(transactions, nil).After(findAccount).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		page, err := transactionService.List(ctx, Filter{AccountID: 1, OperationTypeID: OperationTypeCashBuy, Limit: 2})
+
+		assert.Nil(t, err)
+		assert.Equal(t, transactions[:2], page.Transactions)
+
+		cursor, err := DecodeCursor(page.NextCursor)
+		assert.Nil(t, err)
+		assert.Equal(t, transactions[1].ID, cursor.ID)
+		assert.True(t, transactions[1].OperationDate.Equal(cursor.OperationDate))
+	})
+
+	t.Run("list last page without next cursor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		transactions := newTransactions(2)
+		cursor := &Cursor{OperationDate: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), ID: 3}
+
+		accountRepo.EXPECT().FindById(gomock.Any(), 1).Return(acc, nil).Times(1)
+		transactionRepo.EXPECT().FindByAccount(gomock.Any(), Filter{AccountID: 1, After: cursor, Limit: DefaultPageSize + 1}).
+			Return(transactions, nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		page, err := transactionService.List(ctx, Filter{AccountID: 1, After: cursor})
+
+		assert.Nil(t, err)
+		assert.Equal(t, transactions, page.Transactions)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("page size is capped", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		accountRepo.EXPECT().FindById(gomock.Any(), 1).Return(acc, nil).Times(1)
+		transactionRepo.EXPECT().FindByAccount(gomock.Any(), Filter{AccountID: 1, Limit: MaxPageSize + 1}).Return(nil, nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		page, err := transactionService.List(ctx, Filter{AccountID: 1, Limit: 1000})
+
+		assert.Nil(t, err)
+		assert.Empty(t, page.Transactions)
+	})
+
+	t.Run("account not found error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountRepo := account.NewMockRepositoryInterface(ctrl)
+		transactionRepo := NewMockRepositoryInterface(ctrl)
+		installmentRepo := installment.NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		accountRepo.EXPECT().FindById(gomock.Any(), 1).Return(nil, nil).Times(1)
+
+		transactionService := NewService(transactionRepo, account.NewService(accountRepo, testutil.NewOutboxService(ctrl), clockMock, txManager), installment.NewService(installmentRepo), testutil.NewOperationTypeService(ctrl, seededOperationTypes()...), newLedgerService(ctrl), testutil.NewOutboxService(ctrl), newMetrics(), clockMock, txManager)
+		page, err := transactionService.List(ctx, Filter{AccountID: 1})
+
+		assert.Nil(t, page)
+		assert.ErrorIs(t, err, ErrAccountNotFound)
+	})
+}
+
+func TestDecodeCursor(t *testing.T) {
+	t.Run("decode encoded cursor", func(t *testing.T) {
+		cursor := Cursor{OperationDate: time.Date(2024, 1, 31, 10, 0, 0, 123000, time.UTC), ID: 42}
+
+		decoded, err := DecodeCursor(cursor.Encode())
+		assert.Nil(t, err)
+		assert.Equal(t, &cursor, decoded)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		decoded, err := DecodeCursor("not a cursor")
+		assert.Nil(t, decoded)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
+
+// seededOperationTypes are the operation types seeded by the migrations, plus any extra ones.
+func seededOperationTypes(extra ...operationtype.OperationType) []operationtype.OperationType {
+	operationTypes := []operationtype.OperationType{
+		{ID: OperationTypeCashBuy, Description: "COMPRA A VISTA", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true},
+		{ID: OperationTypeInstallmentBuy, Description: "COMPRA PARCELADA", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true},
+		{ID: OperationTypeWithdraw, Description: "SAQUE", Direction: operationtype.DirectionDebit, ConsumesCreditLimit: true, Active: true},
+		{ID: OperationTypePayment, Description: "PAGAMENTO", Direction: operationtype.DirectionCredit, ConsumesCreditLimit: true, Active: true},
+		{ID: OperationTypeReversal, Description: "ESTORNO", Direction: operationtype.DirectionCredit, ConsumesCreditLimit: true, Active: true, Internal: true},
+	}
+
+	return append(operationTypes, extra...)
+}
+
+func newMetrics() *Metrics {
+	return NewMetrics(prometheus.NewRegistry())
+}
+
+// newLedgerService accepts any journal entry, for tests that don't look at the ledger.
+func newLedgerService(ctrl *gomock.Controller) *ledger.Service {
+	repo := ledger.NewMockRepositoryInterface(ctrl)
+	repo.EXPECT().CreateEntry(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return ledger.NewService(repo)
 }
@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// reservedNetworks are blocks that aren't covered by net.IP's own checks but still don't reach the internet.
+var reservedNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("192.0.0.0/24"),
+	mustParseCIDR("198.18.0.0/15"),
+}
+
+// publicIP tells whether ip is reachable on the internet. Deliveries only go to public addresses, so a webhook
+// can't be used to reach the application's own network.
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, n := range reservedNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkHost refuses hosts that are, or resolve to, addresses that aren't public, unless allowed lets them through.
+// Names that don't resolve yet are accepted, as deliveries check the address again when they connect.
+func checkHost(ctx context.Context, allowed AllowList, host string) error {
+	if allowed.allowsHost(host) {
+		return nil
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if !allowed.allowsIP(ip) {
+			return ErrForbiddenHost
+		}
+
+		return nil
+	}
+
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+
+	for _, a := range addresses {
+		if !allowed.allowsIP(a.IP) {
+			return ErrForbiddenHost
+		}
+	}
+
+	return nil
+}
+
+// checkDial returns the check run before every connection a delivery makes, redirects included, on the address
+// actually dialed, so a host can't be pointed at a private address after its webhook was created.
+func checkDial(allowed AllowList) func(string, string, syscall.RawConn) error {
+	return func(_ string, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+
+		if ip := net.ParseIP(host); ip == nil || !allowed.allowsIP(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenHost, host)
+		}
+
+		return nil
+	}
+}
+
+// newClient builds the HTTP client deliveries are sent with. It connects directly, without proxies from the
+// environment, so every address it dials goes through checkDial. Only the host names in allowed skip it, as the
+// check only sees the address they resolved to.
+func newClient(allowed AllowList) *http.Client {
+	checked := &net.Dialer{Timeout: DeliveryTimeout, Control: checkDial(allowed)}
+	unchecked := &net.Dialer{Timeout: DeliveryTimeout}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network string, address string) (net.Conn, error) {
+		if host, _, err := net.SplitHostPort(address); err == nil && allowed.allowsHost(host) {
+			return unchecked.DialContext(ctx, network, address)
+		}
+
+		return checked.DialContext(ctx, network, address)
+	}
+
+	return &http.Client{Timeout: DeliveryTimeout, Transport: transport}
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return n
+}
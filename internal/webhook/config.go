@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"fmt"
+	"github.com/kelseyhightower/envconfig"
+	"net"
+	"strings"
+)
+
+type Config struct {
+	// AllowedHosts lists networks(CIDRs), addresses and host names deliveries may reach even though they aren't
+	// public, such as a partner reached through a private link, separated by commas.
+	AllowedHosts AllowList `envconfig:"webhook_allowed_hosts"`
+}
+
+func NewConfig() (cfg Config, err error) {
+	err = envconfig.Process("", &cfg)
+	return
+}
+
+// AllowList holds the networks and host names exempt from the public address check.
+type AllowList struct {
+	networks []*net.IPNet
+	hosts    []string
+}
+
+// Decode parses a comma separated list of CIDRs, addresses and host names, as read by envconfig.
+func (a *AllowList) Decode(value string) error {
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		switch {
+		case entry == "":
+			continue
+		case strings.Contains(entry, "/"):
+			_, n, err := net.ParseCIDR(entry)
+			if err != nil {
+				return fmt.Errorf("invalid network %q: %w", entry, err)
+			}
+
+			a.networks = append(a.networks, n)
+		case net.ParseIP(entry) != nil:
+			ip := net.ParseIP(entry)
+			a.networks = append(a.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		default:
+			a.hosts = append(a.hosts, normalizeHost(entry))
+		}
+	}
+
+	return nil
+}
+
+// allowsHost tells whether host is one of the allowed host names.
+func (a AllowList) allowsHost(host string) bool {
+	host = normalizeHost(host)
+
+	for _, h := range a.hosts {
+		if h == host {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowsIP tells whether ip is public or in one of the allowed networks.
+func (a AllowList) allowsIP(ip net.IP) bool {
+	if publicIP(ip) {
+		return true
+	}
+
+	for _, n := range a.networks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
@@ -0,0 +1,33 @@
+package webhook
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+)
+
+func TestAllowList_Decode(t *testing.T) {
+	t.Run("networks, addresses and host names", func(t *testing.T) {
+		var allowed AllowList
+
+		err := allowed.Decode("10.20.0.0/16, 192.168.1.7,Partner.Internal., ")
+
+		assert.Nil(t, err)
+		assert.True(t, allowed.allowsIP(net.ParseIP("10.20.3.4")))
+		assert.True(t, allowed.allowsIP(net.ParseIP("192.168.1.7")))
+		assert.True(t, allowed.allowsIP(net.ParseIP("8.8.8.8")))
+		assert.False(t, allowed.allowsIP(net.ParseIP("10.21.0.1")))
+		assert.False(t, allowed.allowsIP(net.ParseIP("192.168.1.8")))
+		assert.False(t, allowed.allowsIP(net.ParseIP("127.0.0.1")))
+		assert.True(t, allowed.allowsHost("partner.internal"))
+		assert.False(t, allowed.allowsHost("other.internal"))
+	})
+
+	t.Run("invalid network", func(t *testing.T) {
+		var allowed AllowList
+
+		err := allowed.Decode("10.0.0.0/33")
+
+		assert.ErrorContains(t, err, `invalid network "10.0.0.0/33"`)
+	})
+}
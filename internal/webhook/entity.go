@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	StatusPending   = "PENDING"
+	StatusDelivered = "DELIVERED"
+	StatusFailed    = "FAILED"
+)
+
+const (
+	// MaxAttempts is how many times a delivery is tried before it's given up as FAILED.
+	MaxAttempts = 8
+	// RetryBaseDelay is the wait after the first failed attempt. It doubles after each one, up to MaxRetryDelay.
+	RetryBaseDelay = 30 * time.Second
+	MaxRetryDelay  = time.Hour
+	// DeliveryTimeout bounds how long a receiver has to answer each attempt.
+	DeliveryTimeout = 10 * time.Second
+	// MaxDeliveriesListed caps the delivery log returned per subscription, newest first.
+	MaxDeliveriesListed = 100
+)
+
+// Headers sent with every delivery. Receivers check the signature by computing Sign with their secret over the
+// timestamp and the raw body.
+const (
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Subscription asks for the events of the given types to be posted to URL. With an AccountID only the events of
+// that account are sent.
+type Subscription struct {
+	ID         int        `json:"id" gorm:"primaryKey"`
+	URL        string     `json:"url"`
+	EventTypes []string   `json:"event_types" gorm:"serializer:json"`
+	AccountID  *int       `json:"account_id"`
+	Secret     string     `json:"-"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+// Delivery is an event to be posted to a subscription, along with the outcome of its last attempt.
+type Delivery struct {
+	ID             int               `json:"id" gorm:"primaryKey"`
+	SubscriptionID int               `json:"subscription_id"`
+	EventID        int               `json:"event_id"`
+	EventType      string            `json:"event_type"`
+	Payload        json.RawMessage   `json:"payload"`
+	Status         string            `json:"status"`
+	AttemptCount   int               `json:"attempt_count"`
+	NextAttemptAt  *time.Time        `json:"next_attempt_at"`
+	LastStatusCode *int              `json:"last_status_code"`
+	LastError      *string           `json:"last_error"`
+	DeliveredAt    *time.Time        `json:"delivered_at"`
+	ClaimedUntil   *time.Time        `json:"-"`
+	Attempts       []DeliveryAttempt `json:"attempts,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      *time.Time        `json:"updated_at"`
+}
+
+// DeliveryAttempt logs one try at posting a delivery: the status code the receiver answered, or why it couldn't.
+type DeliveryAttempt struct {
+	ID          int       `json:"id" gorm:"primaryKey"`
+	DeliveryID  int       `json:"delivery_id"`
+	StatusCode  *int      `json:"status_code"`
+	Error       *string   `json:"error"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// Sign returns the signature of a delivery: the hex HMAC-SHA256, keyed by the subscription's secret, of the
+// timestamp and the body joined by a dot.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff is how long to wait before trying a delivery again after its attempt-th failed attempt.
+func backoff(attempt int) time.Duration {
+	delay := RetryBaseDelay
+
+	for i := 1; i < attempt && delay < MaxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, MaxRetryDelay)
+}
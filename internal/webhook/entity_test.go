@@ -0,0 +1,23 @@
+package webhook
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body, _ := json.Marshal(map[string]int{"id": 1})
+
+	assert.Equal(t, "sha256=694e0cd0a2282c910466c86f5036be46ecc966527343adb20b3224f53fa87901", Sign("s3cr3t", 1710928800, body))
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, backoff(1))
+	assert.Equal(t, time.Minute, backoff(2))
+	assert.Equal(t, 4*time.Minute, backoff(4))
+	assert.Equal(t, 32*time.Minute, backoff(7))
+	assert.Equal(t, time.Hour, backoff(8))
+	assert.Equal(t, time.Hour, backoff(20))
+}
@@ -0,0 +1,12 @@
+package webhook
+
+import "errors"
+
+var (
+	ErrSubscriptionNotFound = errors.New("Webhook not found")
+	ErrDeliveryNotFound     = errors.New("Delivery not found")
+	ErrDeliveryInProgress   = errors.New("Delivery is being sent, try again shortly")
+	ErrInvalidURL           = errors.New("URL must be an absolute http or https URL")
+	ErrForbiddenHost        = errors.New("URL can't point to a loopback, private or link-local address")
+	ErrInvalidEventTypes    = errors.New("Event types must be AccountCreated, TransactionCreated or CreditLimitChanged")
+)
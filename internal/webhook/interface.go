@@ -0,0 +1,22 @@
+//go:generate mockgen -destination=mock.go -source=interface.go -package=webhook
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+type RepositoryInterface interface {
+	CreateSubscription(ctx context.Context, subscription *Subscription) error
+	FindSubscriptions(ctx context.Context) ([]Subscription, error)
+	FindSubscriptionById(ctx context.Context, id int) (*Subscription, error)
+	FindSubscriptionsByEvent(ctx context.Context, eventType string) ([]Subscription, error)
+	UpdateSubscription(ctx context.Context, subscription *Subscription) error
+	CreateDeliveries(ctx context.Context, deliveries []Delivery) error
+	FindDeliveries(ctx context.Context, subscriptionID int, limit int) ([]Delivery, error)
+	FindDeliveryById(ctx context.Context, id int) (*Delivery, error)
+	FindDeliveryForUpdate(ctx context.Context, id int) (*Delivery, error)
+	FindDueDeliveryIDs(ctx context.Context, now time.Time, limit int) ([]int, error)
+	UpdateDelivery(ctx context.Context, delivery *Delivery) error
+	CreateAttempt(ctx context.Context, attempt *DeliveryAttempt) error
+}
@@ -0,0 +1,211 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interface.go
+
+// Package webhook is a generated GoMock package.
+package webhook
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRepositoryInterface is a mock of RepositoryInterface interface.
+type MockRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryInterfaceMockRecorder
+}
+
+// MockRepositoryInterfaceMockRecorder is the mock recorder for MockRepositoryInterface.
+type MockRepositoryInterfaceMockRecorder struct {
+	mock *MockRepositoryInterface
+}
+
+// NewMockRepositoryInterface creates a new mock instance.
+func NewMockRepositoryInterface(ctrl *gomock.Controller) *MockRepositoryInterface {
+	mock := &MockRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CreateAttempt mocks base method.
+func (m *MockRepositoryInterface) CreateAttempt(ctx context.Context, attempt *DeliveryAttempt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAttempt", ctx, attempt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAttempt indicates an expected call of CreateAttempt.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateAttempt(ctx, attempt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAttempt", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateAttempt), ctx, attempt)
+}
+
+// CreateDeliveries mocks base method.
+func (m *MockRepositoryInterface) CreateDeliveries(ctx context.Context, deliveries []Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeliveries", ctx, deliveries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeliveries indicates an expected call of CreateDeliveries.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateDeliveries(ctx, deliveries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeliveries", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateDeliveries), ctx, deliveries)
+}
+
+// CreateSubscription mocks base method.
+func (m *MockRepositoryInterface) CreateSubscription(ctx context.Context, subscription *Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", ctx, subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateSubscription(ctx, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateSubscription), ctx, subscription)
+}
+
+// FindDeliveries mocks base method.
+func (m *MockRepositoryInterface) FindDeliveries(ctx context.Context, subscriptionID, limit int) ([]Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDeliveries", ctx, subscriptionID, limit)
+	ret0, _ := ret[0].([]Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDeliveries indicates an expected call of FindDeliveries.
+func (mr *MockRepositoryInterfaceMockRecorder) FindDeliveries(ctx, subscriptionID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDeliveries", reflect.TypeOf((*MockRepositoryInterface)(nil).FindDeliveries), ctx, subscriptionID, limit)
+}
+
+// FindDeliveryById mocks base method.
+func (m *MockRepositoryInterface) FindDeliveryById(ctx context.Context, id int) (*Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDeliveryById", ctx, id)
+	ret0, _ := ret[0].(*Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDeliveryById indicates an expected call of FindDeliveryById.
+func (mr *MockRepositoryInterfaceMockRecorder) FindDeliveryById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDeliveryById", reflect.TypeOf((*MockRepositoryInterface)(nil).FindDeliveryById), ctx, id)
+}
+
+// FindDeliveryForUpdate mocks base method.
+func (m *MockRepositoryInterface) FindDeliveryForUpdate(ctx context.Context, id int) (*Delivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDeliveryForUpdate", ctx, id)
+	ret0, _ := ret[0].(*Delivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDeliveryForUpdate indicates an expected call of FindDeliveryForUpdate.
+func (mr *MockRepositoryInterfaceMockRecorder) FindDeliveryForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDeliveryForUpdate", reflect.TypeOf((*MockRepositoryInterface)(nil).FindDeliveryForUpdate), ctx, id)
+}
+
+// FindDueDeliveryIDs mocks base method.
+func (m *MockRepositoryInterface) FindDueDeliveryIDs(ctx context.Context, now time.Time, limit int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDueDeliveryIDs", ctx, now, limit)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDueDeliveryIDs indicates an expected call of FindDueDeliveryIDs.
+func (mr *MockRepositoryInterfaceMockRecorder) FindDueDeliveryIDs(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDueDeliveryIDs", reflect.TypeOf((*MockRepositoryInterface)(nil).FindDueDeliveryIDs), ctx, now, limit)
+}
+
+// FindSubscriptionById mocks base method.
+func (m *MockRepositoryInterface) FindSubscriptionById(ctx context.Context, id int) (*Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubscriptionById", ctx, id)
+	ret0, _ := ret[0].(*Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSubscriptionById indicates an expected call of FindSubscriptionById.
+func (mr *MockRepositoryInterfaceMockRecorder) FindSubscriptionById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubscriptionById", reflect.TypeOf((*MockRepositoryInterface)(nil).FindSubscriptionById), ctx, id)
+}
+
+// FindSubscriptions mocks base method.
+func (m *MockRepositoryInterface) FindSubscriptions(ctx context.Context) ([]Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubscriptions", ctx)
+	ret0, _ := ret[0].([]Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSubscriptions indicates an expected call of FindSubscriptions.
+func (mr *MockRepositoryInterfaceMockRecorder) FindSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubscriptions", reflect.TypeOf((*MockRepositoryInterface)(nil).FindSubscriptions), ctx)
+}
+
+// FindSubscriptionsByEvent mocks base method.
+func (m *MockRepositoryInterface) FindSubscriptionsByEvent(ctx context.Context, eventType string) ([]Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubscriptionsByEvent", ctx, eventType)
+	ret0, _ := ret[0].([]Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSubscriptionsByEvent indicates an expected call of FindSubscriptionsByEvent.
+func (mr *MockRepositoryInterfaceMockRecorder) FindSubscriptionsByEvent(ctx, eventType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubscriptionsByEvent", reflect.TypeOf((*MockRepositoryInterface)(nil).FindSubscriptionsByEvent), ctx, eventType)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockRepositoryInterface) UpdateDelivery(ctx context.Context, delivery *Delivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateDelivery(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateDelivery), ctx, delivery)
+}
+
+// UpdateSubscription mocks base method.
+func (m *MockRepositoryInterface) UpdateSubscription(ctx context.Context, subscription *Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscription", ctx, subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubscription indicates an expected call of UpdateSubscription.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateSubscription(ctx, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscription", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateSubscription), ctx, subscription)
+}
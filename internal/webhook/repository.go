@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+	"time"
+)
+
+type Repository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewRepository(db *gorm.DB, logger *slog.Logger) *Repository {
+	return &Repository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *Repository) CreateSubscription(ctx context.Context, subscription *Subscription) error {
+	return database.Conn(ctx, r.db).Create(subscription).Error
+}
+
+func (r *Repository) FindSubscriptions(ctx context.Context) ([]Subscription, error) {
+	var subscriptions []Subscription
+
+	if err := database.Conn(ctx, r.db).Where("active").Order("id").Find(&subscriptions).Error; err != nil {
+		r.logger.ErrorContext(ctx, "error finding webhook subscriptions", slog.Any("error", err))
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *Repository) FindSubscriptionById(ctx context.Context, id int) (*Subscription, error) {
+	var subscription *Subscription
+
+	if err := database.Conn(ctx, r.db).First(&subscription, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		r.logger.ErrorContext(ctx, "error finding webhook subscription", slog.Any("error", err))
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// FindSubscriptionsByEvent lists the active subscriptions that asked for events of eventType.
+func (r *Repository) FindSubscriptionsByEvent(ctx context.Context, eventType string) ([]Subscription, error) {
+	var subscriptions []Subscription
+
+	eventTypes, _ := json.Marshal([]string{eventType})
+
+	err := database.Conn(ctx, r.db).
+		Where("active and event_types @> ?", string(eventTypes)).
+		Order("id").
+		Find(&subscriptions).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding webhook subscriptions by event", slog.Any("error", err))
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *Repository) UpdateSubscription(ctx context.Context, subscription *Subscription) error {
+	return database.Conn(ctx, r.db).
+		Model(subscription).
+		Select("active").
+		Updates(subscription).Error
+}
+
+// CreateDeliveries queues the deliveries, skipping the ones already queued for the same subscription and event.
+func (r *Repository) CreateDeliveries(ctx context.Context, deliveries []Delivery) error {
+	return database.Conn(ctx, r.db).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "subscription_id"}, {Name: "event_id"}}, DoNothing: true}).
+		Create(&deliveries).Error
+}
+
+func (r *Repository) FindDeliveries(ctx context.Context, subscriptionID int, limit int) ([]Delivery, error) {
+	var deliveries []Delivery
+
+	err := database.Conn(ctx, r.db).
+		Where("subscription_id = ?", subscriptionID).
+		Order("id desc").
+		Limit(limit).
+		Find(&deliveries).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding webhook deliveries", slog.Any("error", err))
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *Repository) FindDeliveryById(ctx context.Context, id int) (*Delivery, error) {
+	var delivery *Delivery
+
+	err := database.Conn(ctx, r.db).
+		Preload("Attempts", func(db *gorm.DB) *gorm.DB {
+			return db.Order("attempted_at, id")
+		}).
+		First(&delivery, "id = ?", id).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		r.logger.ErrorContext(ctx, "error finding webhook delivery", slog.Any("error", err))
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// FindDeliveryForUpdate locks the delivery until the surrounding transaction ends. It returns nil when another
+// worker already holds it, so the same delivery isn't sent twice at once.
+func (r *Repository) FindDeliveryForUpdate(ctx context.Context, id int) (*Delivery, error) {
+	var delivery *Delivery
+
+	err := database.Conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		First(&delivery, "id = ?", id).Error
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+
+		r.logger.ErrorContext(ctx, "error finding webhook delivery for update", slog.Any("error", err))
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// FindDueDeliveryIDs lists the pending deliveries whose next attempt is due by now and that no worker holds, the
+// longest waiting first.
+func (r *Repository) FindDueDeliveryIDs(ctx context.Context, now time.Time, limit int) ([]int, error) {
+	var ids []int
+
+	err := database.Conn(ctx, r.db).
+		Model(&Delivery{}).
+		Where("status = ? and next_attempt_at <= ? and (claimed_until is null or claimed_until <= ?)", StatusPending, now, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+
+	if err != nil {
+		r.logger.ErrorContext(ctx, "error finding due webhook deliveries", slog.Any("error", err))
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *Repository) UpdateDelivery(ctx context.Context, delivery *Delivery) error {
+	return database.Conn(ctx, r.db).
+		Model(delivery).
+		Select("status", "attempt_count", "next_attempt_at", "last_status_code", "last_error", "delivered_at", "claimed_until").
+		Updates(delivery).Error
+}
+
+func (r *Repository) CreateAttempt(ctx context.Context, attempt *DeliveryAttempt) error {
+	return database.Conn(ctx, r.db).Create(attempt).Error
+}
@@ -0,0 +1,404 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/supwr/pismo-transactions/internal/outbox"
+	"github.com/supwr/pismo-transactions/pkg/clock"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// claimDuration is how long a worker holds the delivery it's sending. It must outlast DeliveryTimeout, or another
+// worker may send the same delivery again.
+const claimDuration = time.Minute
+
+var eventTypes = []string{outbox.EventAccountCreated, outbox.EventTransactionCreated, outbox.EventCreditLimitChanged}
+
+type Service struct {
+	repository RepositoryInterface
+	client     *http.Client
+	clock      clock.Clock
+	txManager  database.TxManager
+	allowed    AllowList
+}
+
+func NewService(r RepositoryInterface, c clock.Clock, tm database.TxManager, cfg Config) *Service {
+	return &Service{
+		repository: r,
+		client:     newClient(cfg.AllowedHosts),
+		clock:      c,
+		txManager:  tm,
+		allowed:    cfg.AllowedHosts,
+	}
+}
+
+func (s *Service) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	return s.repository.FindSubscriptions(ctx)
+}
+
+func (s *Service) FindSubscriptionById(ctx context.Context, id int) (*Subscription, error) {
+	return s.repository.FindSubscriptionById(ctx, id)
+}
+
+// CreateSubscription validates and stores the subscription. Its URL must point to a public address, or to one the
+// configuration allows. A random secret is generated when none is given; it is only shown back at creation.
+func (s *Service) CreateSubscription(ctx context.Context, subscription *Subscription) error {
+	u, err := url.Parse(subscription.URL)
+	if err != nil || !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+
+	if err = checkHost(ctx, s.allowed, u.Hostname()); err != nil {
+		return err
+	}
+
+	if len(subscription.EventTypes) == 0 {
+		return ErrInvalidEventTypes
+	}
+
+	for _, t := range subscription.EventTypes {
+		if !slices.Contains(eventTypes, t) {
+			return ErrInvalidEventTypes
+		}
+	}
+
+	if subscription.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err = rand.Read(secret); err != nil {
+			return err
+		}
+
+		subscription.Secret = hex.EncodeToString(secret)
+	}
+
+	subscription.Active = true
+
+	return s.repository.CreateSubscription(ctx, subscription)
+}
+
+// DeleteSubscription deactivates the subscription. Deliveries still pending for it are given up.
+func (s *Service) DeleteSubscription(ctx context.Context, id int) error {
+	subscription, err := s.repository.FindSubscriptionById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if subscription == nil || !subscription.Active {
+		return ErrSubscriptionNotFound
+	}
+
+	subscription.Active = false
+
+	return s.repository.UpdateSubscription(ctx, subscription)
+}
+
+// Publish queues event for delivery to every active subscription that asked for it. It's meant to be the outbox
+// relay's publisher; an event relayed again gets no second delivery, since each subscription gets one per event.
+func (s *Service) Publish(ctx context.Context, event outbox.Event) error {
+	subscriptions, err := s.repository.FindSubscriptionsByEvent(ctx, event.Type)
+	if err != nil {
+		return err
+	}
+
+	accountID, err := accountOf(event)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+
+	var deliveries []Delivery
+
+	for _, sub := range subscriptions {
+		if sub.AccountID != nil && *sub.AccountID != accountID {
+			continue
+		}
+
+		deliveries = append(deliveries, Delivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         StatusPending,
+			NextAttemptAt:  &now,
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return s.repository.CreateDeliveries(ctx, deliveries)
+}
+
+// Deliveries lists the subscription's latest deliveries, newest first.
+func (s *Service) Deliveries(ctx context.Context, subscriptionID int) ([]Delivery, error) {
+	subscription, err := s.repository.FindSubscriptionById(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subscription == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	return s.repository.FindDeliveries(ctx, subscriptionID, MaxDeliveriesListed)
+}
+
+// FindDeliveryById returns the subscription's delivery with the log of its attempts.
+func (s *Service) FindDeliveryById(ctx context.Context, subscriptionID int, id int) (*Delivery, error) {
+	delivery, err := s.repository.FindDeliveryById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery == nil || delivery.SubscriptionID != subscriptionID {
+		return nil, ErrDeliveryNotFound
+	}
+
+	return delivery, nil
+}
+
+// Redeliver schedules the delivery to be sent again right away, with a fresh set of attempts, whatever its status.
+func (s *Service) Redeliver(ctx context.Context, subscriptionID int, id int) (*Delivery, error) {
+	var delivery *Delivery
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		subscription, err := s.repository.FindSubscriptionById(ctx, subscriptionID)
+		if err != nil {
+			return err
+		}
+
+		if subscription == nil || !subscription.Active {
+			return ErrSubscriptionNotFound
+		}
+
+		if delivery, err = s.repository.FindDeliveryById(ctx, id); err != nil {
+			return err
+		}
+
+		if delivery == nil || delivery.SubscriptionID != subscriptionID {
+			return ErrDeliveryNotFound
+		}
+
+		if delivery, err = s.repository.FindDeliveryForUpdate(ctx, id); err != nil {
+			return err
+		}
+
+		now := s.clock.Now()
+
+		// a worker holding the lock or the claim is sending it right now
+		if delivery == nil || claimed(delivery, now) {
+			return ErrDeliveryInProgress
+		}
+
+		delivery.Status = StatusPending
+		delivery.AttemptCount = 0
+		delivery.NextAttemptAt = &now
+
+		return s.repository.UpdateDelivery(ctx, delivery)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// DeliverDue sends up to limit deliveries whose next attempt is due and returns how many were accepted by their
+// receivers. Failed attempts are retried with exponential backoff, up to MaxAttempts.
+func (s *Service) DeliverDue(ctx context.Context, limit int) (int, error) {
+	ids, err := s.repository.FindDueDeliveryIDs(ctx, s.clock.Now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		delivered int
+		errs      []error
+	)
+
+	for _, id := range ids {
+		d, subscription, err := s.claim(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("delivery %d: %w", id, err))
+			continue
+		}
+
+		// taken by another worker, or redelivered and sent meanwhile
+		if d == nil {
+			continue
+		}
+
+		ok, err := s.deliver(ctx, d, subscription)
+		if ok {
+			delivered++
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("delivery %d: %w", id, err))
+		}
+	}
+
+	return delivered, errors.Join(errs...)
+}
+
+// claim holds the delivery for claimDuration, along with the subscription it goes to, in a transaction of its own,
+// so no row stays locked while it's posted. It returns nil when the delivery isn't pending or another worker holds
+// it; a delivery whose worker stopped before recording its attempt is claimed again once its claim runs out.
+func (s *Service) claim(ctx context.Context, id int) (*Delivery, *Subscription, error) {
+	var (
+		delivery     *Delivery
+		subscription *Subscription
+	)
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		d, err := s.repository.FindDeliveryForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		now := s.clock.Now()
+
+		if d == nil || d.Status != StatusPending || claimed(d, now) {
+			return nil
+		}
+
+		if subscription, err = s.repository.FindSubscriptionById(ctx, d.SubscriptionID); err != nil {
+			return err
+		}
+
+		until := now.Add(claimDuration)
+		d.ClaimedUntil = &until
+
+		if err = s.repository.UpdateDelivery(ctx, d); err != nil {
+			return err
+		}
+
+		delivery = d
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return delivery, subscription, nil
+}
+
+// deliver makes one attempt at posting the claimed delivery d to its subscription, outside of any transaction, then
+// logs it, schedules the next one if it failed and gives the claim back.
+func (s *Service) deliver(ctx context.Context, d *Delivery, subscription *Subscription) (bool, error) {
+	now := s.clock.Now()
+	attempt := &DeliveryAttempt{DeliveryID: d.ID, AttemptedAt: now}
+
+	if subscription == nil || !subscription.Active {
+		message := "webhook was deleted"
+		attempt.Error = &message
+	} else {
+		attempt.StatusCode, attempt.Error = s.post(ctx, subscription, d, now.Unix())
+	}
+
+	d.AttemptCount++
+	d.LastStatusCode = attempt.StatusCode
+	d.LastError = attempt.Error
+	d.ClaimedUntil = nil
+
+	ok := attempt.Error == nil
+
+	switch {
+	case ok:
+		d.Status = StatusDelivered
+		d.DeliveredAt = &now
+		d.NextAttemptAt = nil
+	case d.AttemptCount >= MaxAttempts || subscription == nil || !subscription.Active:
+		d.Status = StatusFailed
+		d.NextAttemptAt = nil
+	default:
+		next := now.Add(backoff(d.AttemptCount))
+		d.NextAttemptAt = &next
+	}
+
+	err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.CreateAttempt(ctx, attempt); err != nil {
+			return err
+		}
+
+		return s.repository.UpdateDelivery(ctx, d)
+	})
+
+	return ok, err
+}
+
+// post sends the signed delivery. Anything but a 2xx answer counts as a failure.
+func (s *Service) post(ctx context.Context, subscription *Subscription, d *Delivery, timestamp int64) (*int, *string) {
+	fail := func(message string) *string {
+		return &message
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return nil, fail(err.Error())
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderDelivery, strconv.Itoa(d.ID))
+	request.Header.Set(HeaderEvent, d.EventType)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, d.Payload))
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, fail(err.Error())
+	}
+	defer response.Body.Close()
+
+	// drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return &response.StatusCode, fail(fmt.Sprintf("receiver answered %d", response.StatusCode))
+	}
+
+	return &response.StatusCode, nil
+}
+
+// claimed tells whether a worker still holds d at now.
+func claimed(d *Delivery, now time.Time) bool {
+	return d.ClaimedUntil != nil && d.ClaimedUntil.After(now)
+}
+
+// accountOf tells which account the event is about, so subscriptions scoped to an account only get its events.
+func accountOf(event outbox.Event) (int, error) {
+	if event.AggregateType == outbox.AggregateAccount {
+		return event.AggregateID, nil
+	}
+
+	var payload struct {
+		AccountID int `json:"account_id"`
+	}
+
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return 0, err
+	}
+
+	return payload.AccountID, nil
+}
@@ -0,0 +1,561 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/supwr/pismo-transactions/internal/outbox"
+	"github.com/supwr/pismo-transactions/internal/testutil"
+	clockmock "github.com/supwr/pismo-transactions/pkg/clock/mock"
+	dbmock "github.com/supwr/pismo-transactions/pkg/database/mock"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC)
+
+// receiver is a local endpoint that checks each delivery's signature and answers with the next status in statuses.
+type receiver struct {
+	t        *testing.T
+	secret   string
+	statuses []int
+	bodies   []string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	timestamp, _ := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+
+	assert.Equal(r.t, Sign(r.secret, timestamp, body), req.Header.Get(HeaderSignature))
+	assert.Equal(r.t, outbox.EventTransactionCreated, req.Header.Get(HeaderEvent))
+	assert.Equal(r.t, "application/json", req.Header.Get("Content-Type"))
+
+	r.bodies = append(r.bodies, string(body))
+
+	status := r.statuses[0]
+	r.statuses = r.statuses[1:]
+	w.WriteHeader(status)
+}
+
+func newService(ctrl *gomock.Controller) (*Service, *MockRepositoryInterface) {
+	repo := NewMockRepositoryInterface(ctrl)
+	clockMock := clockmock.NewMockClock(ctrl)
+	txManager := dbmock.NewMockTxManager(ctrl)
+
+	clockMock.EXPECT().Now().Return(now).AnyTimes()
+	txManager.EXPECT().WithinTransaction(gomock.Any(), gomock.Any()).DoAndReturn(testutil.RunInTransaction).AnyTimes()
+
+	return NewService(repo, clockMock, txManager, Config{}), repo
+}
+
+func pendingDelivery() *Delivery {
+	return &Delivery{
+		ID:             5,
+		SubscriptionID: 1,
+		EventID:        9,
+		EventType:      outbox.EventTransactionCreated,
+		Payload:        []byte(`{"id":9,"type":"TransactionCreated"}`),
+		Status:         StatusPending,
+		NextAttemptAt:  &now,
+	}
+}
+
+func TestService_CreateSubscription(t *testing.T) {
+	t.Run("create subscription with a generated secret", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().CreateSubscription(ctx, gomock.Any()).Return(nil).Times(1)
+
+		subscription := &Subscription{URL: "https://partner.example/hooks", EventTypes: []string{outbox.EventTransactionCreated}}
+		err := service.CreateSubscription(ctx, subscription)
+
+		assert.Nil(t, err)
+		assert.True(t, subscription.Active)
+		assert.Len(t, subscription.Secret, 64)
+	})
+
+	t.Run("invalid subscriptions", func(t *testing.T) {
+		cases := map[string]struct {
+			subscription Subscription
+			err          error
+		}{
+			"relative url":       {Subscription{URL: "/hooks", EventTypes: []string{outbox.EventAccountCreated}}, ErrInvalidURL},
+			"unsupported scheme": {Subscription{URL: "ftp://partner.example", EventTypes: []string{outbox.EventAccountCreated}}, ErrInvalidURL},
+			"no event types":     {Subscription{URL: "https://partner.example"}, ErrInvalidEventTypes},
+			"loopback address":   {Subscription{URL: "http://127.0.0.1:8080/hooks", EventTypes: []string{outbox.EventAccountCreated}}, ErrForbiddenHost},
+			"loopback name":      {Subscription{URL: "http://localhost/hooks", EventTypes: []string{outbox.EventAccountCreated}}, ErrForbiddenHost},
+			"ipv6 loopback":      {Subscription{URL: "http://[::1]/hooks", EventTypes: []string{outbox.EventAccountCreated}}, ErrForbiddenHost},
+			"private address":    {Subscription{URL: "https://10.0.0.5/hooks", EventTypes: []string{outbox.EventAccountCreated}}, ErrForbiddenHost},
+			"link-local address": {Subscription{URL: "http://169.254.169.254/latest/meta-data", EventTypes: []string{outbox.EventAccountCreated}}, ErrForbiddenHost},
+			"unknown event type": {Subscription{URL: "https://partner.example", EventTypes: []string{"AccountDeleted"}}, ErrInvalidEventTypes},
+		}
+
+		for name, c := range cases {
+			t.Run(name, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				service, _ := newService(ctrl)
+				ctx := context.Background()
+
+				err := service.CreateSubscription(ctx, &c.subscription)
+
+				assert.ErrorIs(t, err, c.err)
+			})
+		}
+	})
+
+	t.Run("private addresses the configuration allows", func(t *testing.T) {
+		var allowed AllowList
+		assert.Nil(t, allowed.Decode("10.0.0.0/8, localhost"))
+
+		for _, u := range []string{"https://10.0.0.5/hooks", "http://localhost:8080/hooks"} {
+			t.Run(u, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				repo := NewMockRepositoryInterface(ctrl)
+				service := NewService(repo, clockmock.NewMockClock(ctrl), dbmock.NewMockTxManager(ctrl), Config{AllowedHosts: allowed})
+				ctx := context.Background()
+
+				repo.EXPECT().CreateSubscription(ctx, gomock.Any()).Return(nil).Times(1)
+
+				err := service.CreateSubscription(ctx, &Subscription{URL: u, EventTypes: []string{outbox.EventAccountCreated}})
+
+				assert.Nil(t, err)
+			})
+		}
+	})
+}
+
+func TestService_Publish(t *testing.T) {
+	t.Run("queue the event for the subscriptions of its account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+		account, otherAccount := 1, 2
+
+		event := outbox.Event{
+			ID:            9,
+			Type:          outbox.EventTransactionCreated,
+			AggregateType: outbox.AggregateTransaction,
+			AggregateID:   7,
+			Version:       1,
+			Payload:       []byte(`{"id":7,"account_id":1}`),
+		}
+
+		repo.EXPECT().FindSubscriptionsByEvent(ctx, outbox.EventTransactionCreated).Return([]Subscription{
+			{ID: 1, Active: true},
+			{ID: 2, Active: true, AccountID: &account},
+			{ID: 3, Active: true, AccountID: &otherAccount},
+		}, nil).Times(1)
+		repo.EXPECT().CreateDeliveries(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, deliveries []Delivery) error {
+			assert.Len(t, deliveries, 2)
+			assert.Equal(t, 1, deliveries[0].SubscriptionID)
+			assert.Equal(t, 2, deliveries[1].SubscriptionID)
+
+			for _, d := range deliveries {
+				assert.Equal(t, StatusPending, d.Status)
+				assert.Equal(t, now, *d.NextAttemptAt)
+				assert.JSONEq(t, `{"id":9,"type":"TransactionCreated","aggregate_type":"transaction","aggregate_id":7,"version":1,"payload":{"id":7,"account_id":1},"occurred_at":"0001-01-01T00:00:00Z"}`, string(d.Payload))
+			}
+
+			return nil
+		}).Times(1)
+
+		err := service.Publish(ctx, event)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("nothing to queue without subscriptions", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindSubscriptionsByEvent(ctx, outbox.EventAccountCreated).Return(nil, nil).Times(1)
+
+		err := service.Publish(ctx, outbox.Event{ID: 1, Type: outbox.EventAccountCreated, AggregateType: outbox.AggregateAccount, AggregateID: 1})
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_DeliverDue(t *testing.T) {
+	t.Run("deliver signed payload", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+		r := &receiver{t: t, secret: "s3cr3t", statuses: []int{http.StatusNoContent}}
+		server := httptest.NewServer(r)
+		defer server.Close()
+
+		// the receiver listens on loopback, which the delivery client refuses
+		service.client = server.Client()
+
+		d := pendingDelivery()
+
+		repo.EXPECT().FindDueDeliveryIDs(ctx, now, 10).Return([]int{5}, nil).Times(1)
+		repo.EXPECT().FindDeliveryForUpdate(ctx, 5).Return(d, nil).Times(1)
+		repo.EXPECT().FindSubscriptionById(ctx, 1).Return(&Subscription{ID: 1, URL: server.URL, Secret: "s3cr3t", Active: true}, nil).Times(1)
+		claim := repo.EXPECT().UpdateDelivery(ctx, d).DoAndReturn(func(ctx context.Context, d *Delivery) error {
+			assert.Equal(t, now.Add(claimDuration), *d.ClaimedUntil)
+			return nil
+		}).Times(1)
+		repo.EXPECT().CreateAttempt(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, a *DeliveryAttempt) error {
+			assert.Equal(t, http.StatusNoContent, *a.StatusCode)
+			assert.Nil(t, a.Error)
+			return nil
+		}).After(claim).Times(1)
+		repo.EXPECT().UpdateDelivery(ctx, d).DoAndReturn(func(ctx context.Context, d *Delivery) error {
+			assert.Equal(t, StatusDelivered, d.Status)
+			assert.Equal(t, 1, d.AttemptCount)
+			assert.Equal(t, now, *d.DeliveredAt)
+			assert.Nil(t, d.NextAttemptAt)
+			assert.Nil(t, d.ClaimedUntil)
+			return nil
+		}).After(claim).Times(1)
+
+		delivered, err := service.DeliverDue(ctx, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, delivered)
+		assert.Equal(t, []string{`{"id":9,"type":"TransactionCreated"}`}, r.bodies)
+	})
+
+	t.Run("retry with backoff when the receiver fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+		server := httptest.NewServer(&receiver{t: t, secret: "s3cr3t", statuses: []int{http.StatusServiceUnavailable}})
+		defer server.Close()
+
+		// the receiver listens on loopback, which the delivery client refuses
+		service.client = server.Client()
+
+		d := pendingDelivery()
+		d.AttemptCount = 2
+
+		repo.EXPECT().FindDueDeliveryIDs(ctx, now, 10).Return([]int{5}, nil).Times(1)
+		repo.EXPECT().FindDeliveryForUpdate(ctx, 5).Return(d, nil).Times(1)
+		repo.EXPECT().FindSubscriptionById(ctx, 1).Return(&Subscription{ID: 1, URL: server.URL, Secret: "s3cr3t", Active: true}, nil).Times(1)
+		claim := repo.EXPECT().UpdateDelivery(ctx, d).DoAndReturn(func(ctx context.Context, d *Delivery) error {
+			assert.Equal(t, now.Add(claimDuration), *d.ClaimedUntil)
+			return nil
+		}).Times(1)
+		repo.EXPECT().CreateAttempt(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, a *DeliveryAttempt) error {
+			assert.Equal(t, http.StatusServiceUnavailable, *a.StatusCode)
+			assert.Equal(t, "receiver answered 503", *a.Error)
+			return nil
+		}).After(claim).Times(1)
+		repo.EXPECT().UpdateDelivery(ctx, d).Return(nil).After(claim).Times(1)
+
+		delivered, err := service.DeliverDue(ctx, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, delivered)
+		assert.Equal(t, StatusPending, d.Status)
+		assert.Equal(t, 3, d.AttemptCount)
+		assert.Equal(t, now.Add(2*time.Minute), *d.NextAttemptAt)
+	})
+
+	t.Run("give up after the last attempt", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+		server := httptest.NewServer(&receiver{t: t, secret: "s3cr3t", statuses: []int{http.StatusInternalServerError}})
+		defer server.Close()
+
+		// the receiver listens on loopback, which the delivery client refuses
+		service.client = server.Client()
+
+		d := pendingDelivery()
+		d.AttemptCount = MaxAttempts - 1
+
+		repo.EXPECT().FindDueDeliveryIDs(ctx, now, 10).Return([]int{5}, nil).Times(1)
+		repo.EXPECT().FindDeliveryForUpdate(ctx, 5).Return(d, nil).Times(1)
+		repo.EXPECT().FindSubscriptionById(ctx, 1).Return(&Subscription{ID: 1, URL: server.URL, Secret: "s3cr3t", Active: true}, nil).Times(1)
+		claim := repo.EXPECT().UpdateDelivery(ctx, d).DoAndReturn(func(ctx context.Context, d *Delivery) error {
+			assert.Equal(t, now.Add(claimDuration), *d.ClaimedUntil)
+			return nil
+		}).Times(1)
+		repo.EXPECT().CreateAttempt(ctx, gomock.Any()).Return(nil).After(claim).Times(1)
+		repo.EXPECT().UpdateDelivery(ctx, d).Return(nil).After(claim).Times(1)
+
+		_, err := service.DeliverDue(ctx, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, StatusFailed, d.Status)
+		assert.Nil(t, d.NextAttemptAt)
+	})
+
+	t.Run("unreachable receiver counts as a failed attempt", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		// the receiver listens on loopback, which the delivery client refuses
+		service.client = server.Client()
+
+		d := pendingDelivery()
+
+		repo.EXPECT().FindDueDeliveryIDs(ctx, now, 10).Return([]int{5}, nil).Times(1)
+		repo.EXPECT().FindDeliveryForUpdate(ctx, 5).Return(d, nil).Times(1)
+		repo.EXPECT().FindSubscriptionById(ctx, 1).Return(&Subscription{ID: 1, URL: server.URL, Secret: "s3cr3t", Active: true}, nil).Times(1)
+		claim := repo.EXPECT().UpdateDelivery(ctx, d).DoAndReturn(func(ctx context.Context, d *Delivery) error {
+			assert.Equal(t, now.Add(claimDuration), *d.ClaimedUntil)
+			return nil
+		}).Times(1)
+		repo.EXPECT().CreateAttempt(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, a *DeliveryAttempt) error {
+			assert.Nil(t, a.StatusCode)
+			assert.NotNil(t, a.Error)
+			return nil
+		}).After(claim).Times(1)
+		repo.EXPECT().UpdateDelivery(ctx, d).Return(nil).After(claim).Times(1)
+
+		_, err := service.DeliverDue(ctx, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, now.Add(RetryBaseDelay), *d.NextAttemptAt)
+	})
+
+	t.Run("refuse to connect to a private address", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+		r := &receiver{t: t, secret: "s3cr3t"}
+		server := httptest.NewServer(r)
+		defer server.Close()
+
+		d := pendingDelivery()
+
+		repo.EXPECT().FindDueDeliveryIDs(ctx, now, 10).Return([]int{5}, nil).Times(1)
+		repo.EXPECT().FindDeliveryForUpdate(ctx, 5).Return(d, nil).Times(1)
+		repo.EXPECT().FindSubscriptionById(ctx, 1).Return(&Subscription{ID: 1, URL: server.URL, Secret: "s3cr3t", Active: true}, nil).Times(1)
+		claim := repo.EXPECT().UpdateDelivery(ctx, d).DoAndReturn(func(ctx context.Context, d *Delivery) error {
+			assert.Equal(t, now.Add(claimDuration), *d.ClaimedUntil)
+			return nil
+		}).Times(1)
+		repo.EXPECT().CreateAttempt(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, a *DeliveryAttempt) error {
+			assert.Nil(t, a.StatusCode)
+			assert.Contains(t, *a.Error, ErrForbiddenHost.Error())
+			return nil
+		}).After(claim).Times(1)
+		repo.EXPECT().UpdateDelivery(ctx, d).Return(nil).After(claim).Times(1)
+
+		delivered, err := service.DeliverDue(ctx, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, delivered)
+		assert.Empty(t, r.bodies)
+	})
+
+	t.Run("deliver to a private address the configuration allows", func(t *testing.T) {
+		// allowed entry and the host the webhook's URL names
+		cases := map[string]string{
+			"127.0.0.0/8": "127.0.0.1",
+			"127.0.0.1":   "127.0.0.1",
+			"localhost":   "localhost",
+		}
+
+		for entry, host := range cases {
+			t.Run(entry, func(t *testing.T) {
+				ctrl := gomock.NewController(t)
+				service, repo := newService(ctrl)
+				ctx := context.Background()
+				r := &receiver{t: t, secret: "s3cr3t", statuses: []int{http.StatusOK}}
+				server := httptest.NewServer(r)
+				defer server.Close()
+
+				var allowed AllowList
+				assert.Nil(t, allowed.Decode(entry))
+
+				// a client with the allowed entry, its connections still go through checkDial
+				service.client = newClient(allowed)
+
+				_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+				d := pendingDelivery()
+
+				repo.EXPECT().FindDueDeliveryIDs(ctx, now, 10).Return([]int{5}, nil).Times(1)
+				repo.EXPECT().FindDeliveryForUpdate(ctx, 5).Return(d, nil).Times(1)
+				repo.EXPECT().FindSubscriptionById(ctx, 1).Return(&Subscription{ID: 1, URL: "http://" + net.JoinHostPort(host, port), Secret: "s3cr3t", Active: true}, nil).Times(1)
+				repo.EXPECT().CreateAttempt(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, a *DeliveryAttempt) error {
+					assert.Nil(t, a.Error)
+					return nil
+				}).Times(1)
+				repo.EXPECT().UpdateDelivery(ctx, d).Return(nil).Times(2)
+
+				delivered, err := service.DeliverDue(ctx, 10)
+
+				assert.Nil(t, err)
+				assert.Equal(t, 1, delivered)
+				assert.Len(t, r.bodies, 1)
+			})
+		}
+	})
+
+	t.Run("post outside of the transactions that claim and record the delivery", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := NewMockRepositoryInterface(ctrl)
+		clockMock := clockmock.NewMockClock(ctrl)
+		txManager := dbmock.NewMockTxManager(ctrl)
+		ctx := context.Background()
+
+		var inTransaction bool
+
+		clockMock.EXPECT().Now().Return(now).AnyTimes()
+		txManager.EXPECT().WithinTransaction(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+			inTransaction = true
+			defer func() { inTransaction = false }()
+
+			return fn(ctx)
+		}).Times(2)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			assert.False(t, inTransaction)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		service := NewService(repo, clockMock, txManager, Config{})
+		// the receiver listens on loopback, which the delivery client refuses
+		service.client = server.Client()
+
+		d := pendingDelivery()
+
+		repo.EXPECT().FindDueDeliveryIDs(ctx, now, 10).Return([]int{5}, nil).Times(1)
+		repo.EXPECT().FindDeliveryForUpdate(ctx, 5).Return(d, nil).Times(1)
+		repo.EXPECT().FindSubscriptionById(ctx, 1).Return(&Subscription{ID: 1, URL: server.URL, Secret: "s3cr3t", Active: true}, nil).Times(1)
+		repo.EXPECT().CreateAttempt(ctx, gomock.Any()).Return(nil).Times(1)
+		repo.EXPECT().UpdateDelivery(ctx, d).Return(nil).Times(2)
+
+		delivered, err := service.DeliverDue(ctx, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, delivered)
+	})
+
+	t.Run("skip deliveries another worker holds and keep going on errors", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+
+		claimed := pendingDelivery()
+		claimed.ID = 7
+		until := now.Add(time.Second)
+		claimed.ClaimedUntil = &until
+
+		repo.EXPECT().FindDueDeliveryIDs(ctx, now, 10).Return([]int{5, 6, 7}, nil).Times(1)
+		repo.EXPECT().FindDeliveryForUpdate(ctx, 5).Return(nil, errors.New("connection reset")).Times(1)
+		repo.EXPECT().FindDeliveryForUpdate(ctx, 6).Return(nil, nil).Times(1)
+		repo.EXPECT().FindDeliveryForUpdate(ctx, 7).Return(claimed, nil).Times(1)
+
+		delivered, err := service.DeliverDue(ctx, 10)
+
+		assert.ErrorContains(t, err, "delivery 5: connection reset")
+		assert.Equal(t, 0, delivered)
+	})
+}
+
+func TestService_Redeliver(t *testing.T) {
+	t.Run("schedule a failed delivery again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+
+		d := pendingDelivery()
+		d.Status = StatusFailed
+		d.AttemptCount = MaxAttempts
+		d.NextAttemptAt = nil
+
+		repo.EXPECT().FindSubscriptionById(ctx, 1).Return(&Subscription{ID: 1, Active: true}, nil).Times(1)
+		repo.EXPECT().FindDeliveryById(ctx, 5).Return(d, nil).Times(1)
+		repo.EXPECT().FindDeliveryForUpdate(ctx, 5).Return(d, nil).Times(1)
+		repo.EXPECT().UpdateDelivery(ctx, d).Return(nil).Times(1)
+
+		delivery, err := service.Redeliver(ctx, 1, 5)
+
+		assert.Nil(t, err)
+		assert.Equal(t, StatusPending, delivery.Status)
+		assert.Equal(t, 0, delivery.AttemptCount)
+		assert.Equal(t, now, *delivery.NextAttemptAt)
+	})
+
+	t.Run("delivery of another subscription", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindSubscriptionById(ctx, 2).Return(&Subscription{ID: 2, Active: true}, nil).Times(1)
+		repo.EXPECT().FindDeliveryById(ctx, 5).Return(pendingDelivery(), nil).Times(1)
+
+		_, err := service.Redeliver(ctx, 2, 5)
+
+		assert.ErrorIs(t, err, ErrDeliveryNotFound)
+	})
+
+	t.Run("delivery being sent", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindSubscriptionById(ctx, 1).Return(&Subscription{ID: 1, Active: true}, nil).Times(1)
+		repo.EXPECT().FindDeliveryById(ctx, 5).Return(pendingDelivery(), nil).Times(1)
+		repo.EXPECT().FindDeliveryForUpdate(ctx, 5).Return(nil, nil).Times(1)
+
+		_, err := service.Redeliver(ctx, 1, 5)
+
+		assert.ErrorIs(t, err, ErrDeliveryInProgress)
+	})
+
+	t.Run("delivery claimed by a worker", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+
+		d := pendingDelivery()
+		until := now.Add(time.Second)
+		d.ClaimedUntil = &until
+
+		repo.EXPECT().FindSubscriptionById(ctx, 1).Return(&Subscription{ID: 1, Active: true}, nil).Times(1)
+		repo.EXPECT().FindDeliveryById(ctx, 5).Return(d, nil).Times(1)
+		repo.EXPECT().FindDeliveryForUpdate(ctx, 5).Return(d, nil).Times(1)
+
+		_, err := service.Redeliver(ctx, 1, 5)
+
+		assert.ErrorIs(t, err, ErrDeliveryInProgress)
+	})
+}
+
+func TestService_DeleteSubscription(t *testing.T) {
+	t.Run("deactivate subscription", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindSubscriptionById(ctx, 1).Return(&Subscription{ID: 1, Active: true}, nil).Times(1)
+		repo.EXPECT().UpdateSubscription(ctx, &Subscription{ID: 1, Active: false}).Return(nil).Times(1)
+
+		err := service.DeleteSubscription(ctx, 1)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("subscription not found", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		service, repo := newService(ctrl)
+		ctx := context.Background()
+
+		repo.EXPECT().FindSubscriptionById(ctx, 1).Return(nil, nil).Times(1)
+
+		err := service.DeleteSubscription(ctx, 1)
+
+		assert.ErrorIs(t, err, ErrSubscriptionNotFound)
+	})
+}
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS sc_pismo.idempotency_keys (
+    "key" VARCHAR(255) NOT NULL,
+    "request_hash" VARCHAR(64) NOT NULL,
+    "response_status" INT NOT NULL,
+    "response_body" BYTEA NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    CONSTRAINT "PK_IdempotencyKeys" PRIMARY KEY ("key")
+);
\ No newline at end of file
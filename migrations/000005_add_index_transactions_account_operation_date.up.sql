@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS "IX_Transactions_AccountId_OperationDate_Id" ON sc_pismo.transactions ("account_id", "operation_date" DESC, "id" DESC);
\ No newline at end of file
@@ -0,0 +1,17 @@
+ALTER TABLE sc_pismo.transactions ADD COLUMN IF NOT EXISTS balance DECIMAL(10,2);
+UPDATE sc_pismo.transactions SET balance = amount WHERE balance IS NULL;
+ALTER TABLE sc_pismo.transactions ALTER COLUMN balance SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS "IX_Transactions_AccountId_Outstanding" ON sc_pismo.transactions ("account_id", "operation_date", "id") WHERE "balance" < 0;
+
+CREATE TABLE IF NOT EXISTS sc_pismo.discharges (
+    "id" BIGSERIAL NOT NULL,
+    "payment_transaction_id" BIGINT NOT NULL,
+    "transaction_id" BIGINT NOT NULL,
+    "amount" DECIMAL(10,2) NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    CONSTRAINT "PK_Discharges" PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "IX_Discharges_PaymentTransactionId" ON sc_pismo.discharges ("payment_transaction_id");
+CREATE INDEX IF NOT EXISTS "IX_Discharges_TransactionId" ON sc_pismo.discharges ("transaction_id");
\ No newline at end of file
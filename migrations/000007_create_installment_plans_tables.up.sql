@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS sc_pismo.installment_plans (
+    "id" BIGSERIAL NOT NULL,
+    "transaction_id" BIGINT NOT NULL,
+    "account_id" BIGINT NOT NULL,
+    "principal" DECIMAL(10,2) NOT NULL,
+    "interest_rate" DECIMAL(7,4) NOT NULL,
+    "installment_count" INT NOT NULL,
+    "total" DECIMAL(10,2) NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    "updated_at" TIMESTAMP NULL,
+    CONSTRAINT "PK_InstallmentPlans" PRIMARY KEY ("id"),
+    CONSTRAINT "UQ_InstallmentPlans_TransactionId" UNIQUE ("transaction_id")
+);
+
+CREATE TABLE IF NOT EXISTS sc_pismo.installments (
+    "id" BIGSERIAL NOT NULL,
+    "installment_plan_id" BIGINT NOT NULL,
+    "number" INT NOT NULL,
+    "amount" DECIMAL(10,2) NOT NULL,
+    "due_date" DATE NOT NULL,
+    "status" VARCHAR(20) NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    "updated_at" TIMESTAMP NULL,
+    CONSTRAINT "PK_Installments" PRIMARY KEY ("id"),
+    CONSTRAINT "FK_Installments_InstallmentPlans" FOREIGN KEY ("installment_plan_id") REFERENCES sc_pismo.installment_plans ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "IX_Installments_InstallmentPlanId" ON sc_pismo.installments ("installment_plan_id");
\ No newline at end of file
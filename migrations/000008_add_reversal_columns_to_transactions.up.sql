@@ -0,0 +1,5 @@
+ALTER TABLE sc_pismo.transactions ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'POSTED';
+ALTER TABLE sc_pismo.transactions ADD COLUMN IF NOT EXISTS reversed_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE sc_pismo.transactions ADD COLUMN IF NOT EXISTS reversed_transaction_id BIGINT NULL;
+
+CREATE INDEX IF NOT EXISTS "IX_Transactions_ReversedTransactionId" ON sc_pismo.transactions ("reversed_transaction_id") WHERE "reversed_transaction_id" IS NOT NULL;
\ No newline at end of file
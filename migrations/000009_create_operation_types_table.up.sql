@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS sc_pismo.operation_types (
+    "id" BIGSERIAL NOT NULL,
+    "description" VARCHAR(100) NOT NULL,
+    "direction" VARCHAR(10) NOT NULL,
+    "consumes_credit_limit" BOOLEAN NOT NULL DEFAULT TRUE,
+    "active" BOOLEAN NOT NULL DEFAULT TRUE,
+    "internal" BOOLEAN NOT NULL DEFAULT FALSE,
+    "created_at" TIMESTAMP NOT NULL DEFAULT now(),
+    "updated_at" TIMESTAMP NULL,
+    CONSTRAINT "PK_OperationTypes" PRIMARY KEY ("id"),
+    CONSTRAINT "CK_OperationTypes_Direction" CHECK ("direction" IN ('DEBIT', 'CREDIT'))
+);
+
+INSERT INTO sc_pismo.operation_types ("id", "description", "direction", "consumes_credit_limit", "active", "internal") VALUES
+    (1, 'COMPRA A VISTA', 'DEBIT', TRUE, TRUE, FALSE),
+    (2, 'COMPRA PARCELADA', 'DEBIT', TRUE, TRUE, FALSE),
+    (3, 'SAQUE', 'DEBIT', TRUE, TRUE, FALSE),
+    (4, 'PAGAMENTO', 'CREDIT', TRUE, TRUE, FALSE),
+    (5, 'ESTORNO', 'CREDIT', TRUE, TRUE, TRUE)
+ON CONFLICT ("id") DO NOTHING;
+
+SELECT setval(pg_get_serial_sequence('sc_pismo.operation_types', 'id'), (SELECT MAX("id") FROM sc_pismo.operation_types));
+
+ALTER TABLE sc_pismo.transactions ADD CONSTRAINT "FK_Transactions_OperationTypes" FOREIGN KEY ("operation_type_id") REFERENCES sc_pismo.operation_types ("id");
\ No newline at end of file
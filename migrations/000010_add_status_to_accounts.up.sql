@@ -0,0 +1,5 @@
+ALTER TABLE sc_pismo.accounts ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE';
+ALTER TABLE sc_pismo.accounts ADD COLUMN IF NOT EXISTS status_reason VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE sc_pismo.accounts ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP NULL;
+
+UPDATE sc_pismo.accounts SET status = 'CLOSED', status_changed_at = deleted_at WHERE deleted_at IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,27 @@
+ALTER TABLE sc_pismo.accounts ADD COLUMN IF NOT EXISTS credit_limit DECIMAL(10,2) NULL;
+
+-- every transaction booked so far moved the available limit by its amount
+UPDATE sc_pismo.accounts a SET credit_limit = COALESCE(a.available_credit_limit, 0) - COALESCE(
+    (SELECT SUM(t.amount) FROM sc_pismo.transactions t WHERE t.account_id = a.id AND t.deleted_at IS NULL), 0
+);
+
+UPDATE sc_pismo.accounts SET available_credit_limit = 0 WHERE available_credit_limit IS NULL;
+
+ALTER TABLE sc_pismo.accounts ALTER COLUMN credit_limit SET NOT NULL;
+ALTER TABLE sc_pismo.accounts ALTER COLUMN available_credit_limit SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS sc_pismo.credit_limit_changes (
+    "id" BIGSERIAL NOT NULL,
+    "account_id" BIGINT NOT NULL,
+    "previous_credit_limit" DECIMAL(10,2) NOT NULL,
+    "credit_limit" DECIMAL(10,2) NOT NULL,
+    "previous_available_credit_limit" DECIMAL(10,2) NOT NULL,
+    "available_credit_limit" DECIMAL(10,2) NOT NULL,
+    "reason" VARCHAR(255) NOT NULL,
+    "actor" VARCHAR(255) NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    CONSTRAINT "PK_CreditLimitChanges" PRIMARY KEY ("id"),
+    CONSTRAINT "FK_CreditLimitChanges_Accounts" FOREIGN KEY ("account_id") REFERENCES sc_pismo.accounts ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "IX_CreditLimitChanges_AccountId" ON sc_pismo.credit_limit_changes ("account_id", "created_at" DESC);
\ No newline at end of file
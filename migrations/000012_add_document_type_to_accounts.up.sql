@@ -0,0 +1,29 @@
+ALTER TABLE sc_pismo.accounts ADD COLUMN IF NOT EXISTS document_type VARCHAR(4) NULL;
+
+-- accounts created before documents were normalized may still be stored with punctuation
+UPDATE sc_pismo.accounts SET document = REGEXP_REPLACE(document, '[.\-/ ]', '', 'g') WHERE document ~ '^[0-9.\-/ ]+$';
+
+-- same as account.verificationDigit: the weighted sum of the digits, modulo 11
+CREATE FUNCTION pg_temp.verification_digit(digits TEXT, weights INT[]) RETURNS INT AS $$
+    SELECT CASE WHEN total % 11 >= 2 THEN 11 - total % 11 ELSE 0 END
+    FROM (
+        SELECT SUM(SUBSTRING(digits, i, 1)::INT * weights[i]) AS total FROM GENERATE_SUBSCRIPTS(weights, 1) AS i
+    ) AS sums;
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- the type is only set for a valid CPF or CNPJ, as account.Document.Type does, so documents created before
+-- validation keep a null type
+UPDATE sc_pismo.accounts SET document_type = CASE
+    WHEN LENGTH(document) = 11
+        AND pg_temp.verification_digit(LEFT(document, 9), ARRAY[10, 9, 8, 7, 6, 5, 4, 3, 2]) = SUBSTRING(document, 10, 1)::INT
+        AND pg_temp.verification_digit(LEFT(document, 10), ARRAY[11, 10, 9, 8, 7, 6, 5, 4, 3, 2]) = SUBSTRING(document, 11, 1)::INT
+        THEN 'CPF'
+    WHEN LENGTH(document) = 14
+        AND pg_temp.verification_digit(LEFT(document, 12), ARRAY[5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2]) = SUBSTRING(document, 13, 1)::INT
+        AND pg_temp.verification_digit(LEFT(document, 13), ARRAY[6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2]) = SUBSTRING(document, 14, 1)::INT
+        THEN 'CNPJ'
+END
+-- digits only, and not all the same digit, which Type rejects even when the verification digits match
+WHERE document ~ '^[0-9]+$' AND document !~ '^(.)\1*$';
+
+DROP FUNCTION pg_temp.verification_digit(TEXT, INT[]);
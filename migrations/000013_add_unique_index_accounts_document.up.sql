@@ -0,0 +1,5 @@
+-- accounts created before documents were normalized may still be stored with punctuation
+UPDATE sc_pismo.accounts SET document = REGEXP_REPLACE(document, '[.\-/ ]', '', 'g') WHERE document ~ '^[0-9.\-/ ]+$';
+
+-- fails if there are open accounts sharing a document, which have to be merged or closed before migrating
+CREATE UNIQUE INDEX IF NOT EXISTS "UQ_Accounts_Document" ON sc_pismo.accounts ("document") WHERE "deleted_at" IS NULL;
\ No newline at end of file
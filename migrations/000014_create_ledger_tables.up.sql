@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS sc_pismo.journal_entries (
+    "id" BIGSERIAL NOT NULL,
+    "transaction_id" BIGINT NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    CONSTRAINT "PK_JournalEntries" PRIMARY KEY ("id"),
+    CONSTRAINT "FK_JournalEntries_Transactions" FOREIGN KEY ("transaction_id") REFERENCES sc_pismo.transactions ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "IX_JournalEntries_TransactionId" ON sc_pismo.journal_entries ("transaction_id");
+
+CREATE TABLE IF NOT EXISTS sc_pismo.postings (
+    "id" BIGSERIAL NOT NULL,
+    "journal_entry_id" BIGINT NOT NULL,
+    "account_id" BIGINT NOT NULL,
+    "ledger" VARCHAR(20) NOT NULL,
+    "direction" VARCHAR(10) NOT NULL,
+    "amount" DECIMAL(10,2) NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    CONSTRAINT "PK_Postings" PRIMARY KEY ("id"),
+    CONSTRAINT "FK_Postings_JournalEntries" FOREIGN KEY ("journal_entry_id") REFERENCES sc_pismo.journal_entries ("id"),
+    CONSTRAINT "CK_Postings_Direction" CHECK ("direction" IN ('DEBIT', 'CREDIT')),
+    CONSTRAINT "CK_Postings_Amount" CHECK ("amount" > 0)
+);
+
+CREATE INDEX IF NOT EXISTS "IX_Postings_JournalEntryId" ON sc_pismo.postings ("journal_entry_id");
+CREATE INDEX IF NOT EXISTS "IX_Postings_AccountId_Ledger" ON sc_pismo.postings ("account_id", "ledger", "direction");
+
+-- book the transactions created before the ledger existed, the same way the application does
+INSERT INTO sc_pismo.journal_entries ("transaction_id", "created_at")
+SELECT t.id, t.created_at FROM sc_pismo.transactions t WHERE t.deleted_at IS NULL ORDER BY t.id;
+
+INSERT INTO sc_pismo.postings ("journal_entry_id", "account_id", "ledger", "direction", "amount", "created_at")
+SELECT p.journal_entry_id, p.account_id, p.ledger, p.direction, p.amount, p.created_at FROM (
+    SELECT e.id AS journal_entry_id, t.account_id, 'RECEIVABLES' AS ledger, 'DEBIT' AS direction, -t.amount AS amount, e.created_at, 1 AS position
+    FROM sc_pismo.transactions t JOIN sc_pismo.journal_entries e ON e.transaction_id = t.id WHERE t.amount < 0
+    UNION ALL
+    SELECT e.id, t.account_id, 'CASH', 'CREDIT', -t.amount, e.created_at, 2
+    FROM sc_pismo.transactions t JOIN sc_pismo.journal_entries e ON e.transaction_id = t.id WHERE t.amount < 0
+    UNION ALL
+    SELECT e.id, t.account_id, 'CASH', 'DEBIT', t.amount, e.created_at, 1
+    FROM sc_pismo.transactions t JOIN sc_pismo.journal_entries e ON e.transaction_id = t.id WHERE t.amount > 0
+    UNION ALL
+    SELECT e.id, t.account_id, 'RECEIVABLES', 'CREDIT', t.amount - t.balance, e.created_at, 2
+    FROM sc_pismo.transactions t JOIN sc_pismo.journal_entries e ON e.transaction_id = t.id WHERE t.amount > 0 AND t.amount > t.balance
+    UNION ALL
+    SELECT e.id, t.account_id, 'ACCOUNT', 'CREDIT', t.balance, e.created_at, 3
+    FROM sc_pismo.transactions t JOIN sc_pismo.journal_entries e ON e.transaction_id = t.id WHERE t.amount > 0 AND t.balance > 0
+) p ORDER BY p.journal_entry_id, p.position;
\ No newline at end of file
@@ -0,0 +1,42 @@
+ALTER TABLE sc_pismo.accounts ADD COLUMN IF NOT EXISTS closing_day INT NOT NULL DEFAULT 3;
+ALTER TABLE sc_pismo.accounts ADD COLUMN IF NOT EXISTS due_day INT NOT NULL DEFAULT 10;
+
+ALTER TABLE sc_pismo.accounts ADD CONSTRAINT "CK_Accounts_BillingDays"
+    CHECK ("closing_day" BETWEEN 1 AND 28 AND "due_day" BETWEEN 1 AND 28 AND "closing_day" <> "due_day");
+
+CREATE TABLE IF NOT EXISTS sc_pismo.invoices (
+    "id" BIGSERIAL NOT NULL,
+    "account_id" BIGINT NOT NULL,
+    "period_start" TIMESTAMP NOT NULL,
+    "period_end" TIMESTAMP NOT NULL,
+    "due_date" DATE NOT NULL,
+    "previous_balance" DECIMAL(10,2) NOT NULL,
+    "charges" DECIMAL(10,2) NOT NULL,
+    "credits" DECIMAL(10,2) NOT NULL,
+    "total" DECIMAL(10,2) NOT NULL,
+    "minimum_payment" DECIMAL(10,2) NOT NULL,
+    "paid_amount" DECIMAL(10,2) NOT NULL DEFAULT 0,
+    "status" VARCHAR(20) NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    "updated_at" TIMESTAMP NULL,
+    CONSTRAINT "PK_Invoices" PRIMARY KEY ("id"),
+    CONSTRAINT "FK_Invoices_Accounts" FOREIGN KEY ("account_id") REFERENCES sc_pismo.accounts ("id"),
+    CONSTRAINT "UQ_Invoices_AccountId_PeriodEnd" UNIQUE ("account_id", "period_end")
+);
+
+CREATE TABLE IF NOT EXISTS sc_pismo.invoice_items (
+    "id" BIGSERIAL NOT NULL,
+    "invoice_id" BIGINT NOT NULL,
+    "transaction_id" BIGINT NULL,
+    "installment_id" BIGINT NULL,
+    "description" VARCHAR(255) NOT NULL,
+    "amount" DECIMAL(10,2) NOT NULL,
+    "operation_date" TIMESTAMP NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    CONSTRAINT "PK_InvoiceItems" PRIMARY KEY ("id"),
+    CONSTRAINT "FK_InvoiceItems_Invoices" FOREIGN KEY ("invoice_id") REFERENCES sc_pismo.invoices ("id"),
+    CONSTRAINT "FK_InvoiceItems_Transactions" FOREIGN KEY ("transaction_id") REFERENCES sc_pismo.transactions ("id"),
+    CONSTRAINT "FK_InvoiceItems_Installments" FOREIGN KEY ("installment_id") REFERENCES sc_pismo.installments ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "IX_InvoiceItems_InvoiceId" ON sc_pismo.invoice_items ("invoice_id");
\ No newline at end of file
@@ -0,0 +1,54 @@
+CREATE TABLE IF NOT EXISTS sc_pismo.fee_rates (
+    "id" BIGSERIAL NOT NULL,
+    "kind" VARCHAR(30) NOT NULL,
+    "operation_type_id" BIGINT NOT NULL,
+    "rate" DECIMAL(7,4) NOT NULL,
+    "valid_from" DATE NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    CONSTRAINT "PK_FeeRates" PRIMARY KEY ("id"),
+    CONSTRAINT "FK_FeeRates_OperationTypes" FOREIGN KEY ("operation_type_id") REFERENCES sc_pismo.operation_types ("id"),
+    CONSTRAINT "CK_FeeRates_Kind" CHECK ("kind" IN ('REVOLVING_INTEREST', 'LATE_FEE', 'LATE_INTEREST')),
+    CONSTRAINT "CK_FeeRates_Rate" CHECK ("rate" >= 0)
+);
+
+-- fees are internal debits, so only the accrual job can post them
+WITH types AS (
+    INSERT INTO sc_pismo.operation_types ("description", "direction", "consumes_credit_limit", "active", "internal") VALUES
+        ('JUROS ROTATIVO', 'DEBIT', TRUE, TRUE, TRUE),
+        ('MULTA POR ATRASO', 'DEBIT', TRUE, TRUE, TRUE),
+        ('JUROS DE MORA', 'DEBIT', TRUE, TRUE, TRUE)
+    RETURNING "id", "description"
+)
+INSERT INTO sc_pismo.fee_rates ("kind", "operation_type_id", "rate", "valid_from", "created_at")
+SELECT CASE t.description
+           WHEN 'JUROS ROTATIVO' THEN 'REVOLVING_INTEREST'
+           WHEN 'MULTA POR ATRASO' THEN 'LATE_FEE'
+           ELSE 'LATE_INTEREST'
+       END,
+       t.id,
+       CASE t.description
+           WHEN 'JUROS ROTATIVO' THEN 0.1400
+           WHEN 'MULTA POR ATRASO' THEN 0.0200
+           ELSE 0.0100
+       END,
+       DATE '2000-01-01',
+       now()
+FROM types t;
+
+CREATE TABLE IF NOT EXISTS sc_pismo.fee_accruals (
+    "id" BIGSERIAL NOT NULL,
+    "invoice_id" BIGINT NOT NULL,
+    "account_id" BIGINT NOT NULL,
+    "kind" VARCHAR(30) NOT NULL,
+    "accrual_date" DATE NOT NULL,
+    "base" DECIMAL(10,2) NOT NULL,
+    "rate" DECIMAL(7,4) NOT NULL,
+    "amount" DECIMAL(10,2) NOT NULL,
+    "operation_type_id" BIGINT NOT NULL,
+    "transaction_id" BIGINT NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    CONSTRAINT "PK_FeeAccruals" PRIMARY KEY ("id"),
+    CONSTRAINT "FK_FeeAccruals_Invoices" FOREIGN KEY ("invoice_id") REFERENCES sc_pismo.invoices ("id"),
+    CONSTRAINT "FK_FeeAccruals_Transactions" FOREIGN KEY ("transaction_id") REFERENCES sc_pismo.transactions ("id"),
+    CONSTRAINT "UQ_FeeAccruals_InvoiceId_Kind_AccrualDate" UNIQUE ("invoice_id", "kind", "accrual_date")
+);
\ No newline at end of file
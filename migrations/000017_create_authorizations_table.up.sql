@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS sc_pismo.authorizations (
+    "id" BIGSERIAL NOT NULL,
+    "account_id" BIGINT NOT NULL,
+    "operation_type_id" BIGINT NOT NULL,
+    "amount" DECIMAL(10,2) NOT NULL,
+    "reserved" DECIMAL(10,2) NOT NULL DEFAULT 0,
+    "captured_amount" DECIMAL(10,2) NOT NULL DEFAULT 0,
+    "installments" INT NOT NULL DEFAULT 1,
+    "interest_rate" DECIMAL(7,4) NOT NULL DEFAULT 0,
+    "status" VARCHAR(20) NOT NULL,
+    "transaction_id" BIGINT NULL,
+    "expires_at" TIMESTAMP NOT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    "updated_at" TIMESTAMP NULL,
+    CONSTRAINT "PK_Authorizations" PRIMARY KEY ("id"),
+    CONSTRAINT "FK_Authorizations_Accounts" FOREIGN KEY ("account_id") REFERENCES sc_pismo.accounts ("id"),
+    CONSTRAINT "FK_Authorizations_OperationTypes" FOREIGN KEY ("operation_type_id") REFERENCES sc_pismo.operation_types ("id"),
+    CONSTRAINT "FK_Authorizations_Transactions" FOREIGN KEY ("transaction_id") REFERENCES sc_pismo.transactions ("id"),
+    CONSTRAINT "CK_Authorizations_Status" CHECK ("status" IN ('AUTHORIZED', 'CAPTURED', 'VOIDED', 'EXPIRED')),
+    CONSTRAINT "CK_Authorizations_Amount" CHECK ("amount" > 0 AND "reserved" >= 0 AND "captured_amount" <= "amount")
+);
+
+-- the expiry job only looks at pending holds
+CREATE INDEX IF NOT EXISTS "IX_Authorizations_ExpiresAt" ON sc_pismo.authorizations ("expires_at", "id") WHERE "status" = 'AUTHORIZED';
+
+-- reconciliation sums the pending holds of each account
+CREATE INDEX IF NOT EXISTS "IX_Authorizations_AccountId" ON sc_pismo.authorizations ("account_id") WHERE "status" = 'AUTHORIZED';
\ No newline at end of file
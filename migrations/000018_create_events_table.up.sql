@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS sc_pismo.events (
+    "id" BIGSERIAL NOT NULL,
+    "type" VARCHAR(50) NOT NULL,
+    "aggregate_type" VARCHAR(50) NOT NULL,
+    "aggregate_id" BIGINT NOT NULL,
+    "version" INT NOT NULL,
+    "payload" JSONB NOT NULL,
+    "occurred_at" TIMESTAMP NOT NULL,
+    "published_at" TIMESTAMP NULL,
+    "claimed_until" TIMESTAMP NULL,
+    "attempts" INT NOT NULL DEFAULT 0,
+    "last_error" TEXT NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    CONSTRAINT "PK_Events" PRIMARY KEY ("id")
+);
+
+-- the relay only looks at events that weren't published yet
+CREATE INDEX IF NOT EXISTS "IX_Events_Pending" ON sc_pismo.events ("id") WHERE "published_at" IS NULL;
\ No newline at end of file
@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS sc_pismo.subscriptions (
+    "id" BIGSERIAL NOT NULL,
+    "url" VARCHAR(2048) NOT NULL,
+    "event_types" JSONB NOT NULL,
+    "account_id" BIGINT NULL,
+    "secret" VARCHAR(255) NOT NULL,
+    "active" BOOLEAN NOT NULL DEFAULT TRUE,
+    "created_at" TIMESTAMP NOT NULL,
+    "updated_at" TIMESTAMP NULL,
+    CONSTRAINT "PK_Subscriptions" PRIMARY KEY ("id"),
+    CONSTRAINT "FK_Subscriptions_Accounts" FOREIGN KEY ("account_id") REFERENCES sc_pismo.accounts ("id")
+);
+
+CREATE TABLE IF NOT EXISTS sc_pismo.deliveries (
+    "id" BIGSERIAL NOT NULL,
+    "subscription_id" BIGINT NOT NULL,
+    "event_id" BIGINT NOT NULL,
+    "event_type" VARCHAR(50) NOT NULL,
+    "payload" JSONB NOT NULL,
+    "status" VARCHAR(20) NOT NULL,
+    "attempt_count" INT NOT NULL DEFAULT 0,
+    "next_attempt_at" TIMESTAMP NULL,
+    "last_status_code" INT NULL,
+    "last_error" TEXT NULL,
+    "delivered_at" TIMESTAMP NULL,
+    "created_at" TIMESTAMP NOT NULL,
+    "updated_at" TIMESTAMP NULL,
+    CONSTRAINT "PK_Deliveries" PRIMARY KEY ("id"),
+    CONSTRAINT "FK_Deliveries_Subscriptions" FOREIGN KEY ("subscription_id") REFERENCES sc_pismo.subscriptions ("id"),
+    CONSTRAINT "FK_Deliveries_Events" FOREIGN KEY ("event_id") REFERENCES sc_pismo.events ("id"),
+    CONSTRAINT "UQ_Deliveries_SubscriptionId_EventId" UNIQUE ("subscription_id", "event_id"),
+    CONSTRAINT "CK_Deliveries_Status" CHECK ("status" IN ('PENDING', 'DELIVERED', 'FAILED'))
+);
+
+-- the delivery worker only looks at pending deliveries
+CREATE INDEX IF NOT EXISTS "IX_Deliveries_NextAttemptAt" ON sc_pismo.deliveries ("next_attempt_at", "id") WHERE "status" = 'PENDING';
+CREATE INDEX IF NOT EXISTS "IX_Deliveries_SubscriptionId" ON sc_pismo.deliveries ("subscription_id", "id" DESC);
+
+CREATE TABLE IF NOT EXISTS sc_pismo.delivery_attempts (
+    "id" BIGSERIAL NOT NULL,
+    "delivery_id" BIGINT NOT NULL,
+    "status_code" INT NULL,
+    "error" TEXT NULL,
+    "attempted_at" TIMESTAMP NOT NULL,
+    CONSTRAINT "PK_DeliveryAttempts" PRIMARY KEY ("id"),
+    CONSTRAINT "FK_DeliveryAttempts_Deliveries" FOREIGN KEY ("delivery_id") REFERENCES sc_pismo.deliveries ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "IX_DeliveryAttempts_DeliveryId" ON sc_pismo.delivery_attempts ("delivery_id");
\ No newline at end of file
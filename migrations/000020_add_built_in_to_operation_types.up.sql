@@ -0,0 +1,6 @@
+ALTER TABLE sc_pismo.operation_types ADD COLUMN IF NOT EXISTS "built_in" BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- the types seeded with the catalogue and the fee types seeded by 000016, whose rates are valid since 2000-01-01
+UPDATE sc_pismo.operation_types SET "built_in" = TRUE
+WHERE "id" <= 5
+   OR "id" IN (SELECT "operation_type_id" FROM sc_pismo.fee_rates WHERE "valid_from" = DATE '2000-01-01');
@@ -0,0 +1,22 @@
+ALTER TABLE sc_pismo.operation_types ADD COLUMN IF NOT EXISTS "code" VARCHAR(40) NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "UQ_OperationTypes_Code" ON sc_pismo.operation_types ("code");
+
+-- the interest of installment buys is booked as each installment is billed, so only billing can post it
+INSERT INTO sc_pismo.operation_types ("description", "direction", "consumes_credit_limit", "active", "internal", "built_in", "code")
+VALUES ('JUROS DE PARCELAMENTO', 'DEBIT', TRUE, TRUE, TRUE, TRUE, 'INSTALLMENT_INTEREST');
+
+ALTER TABLE sc_pismo.installments ADD COLUMN IF NOT EXISTS "interest" DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE sc_pismo.installments ADD COLUMN IF NOT EXISTS "interest_transaction_id" BIGINT NULL;
+ALTER TABLE sc_pismo.installments ADD CONSTRAINT "FK_Installments_Transactions"
+    FOREIGN KEY ("interest_transaction_id") REFERENCES sc_pismo.transactions ("id");
+
+-- the plans already created get their interest split evenly, the rounding difference going to the last installment
+UPDATE sc_pismo.installments i
+SET "interest" = CASE
+        WHEN i."number" = p."installment_count"
+            THEN (p."total" - p."principal") - ROUND((p."total" - p."principal") / p."installment_count", 2) * (p."installment_count" - 1)
+        ELSE ROUND((p."total" - p."principal") / p."installment_count", 2)
+    END
+FROM sc_pismo.installment_plans p
+WHERE p."id" = i."installment_plan_id" AND p."total" > p."principal";
@@ -0,0 +1,2 @@
+-- a worker claims a delivery before posting it, so the post doesn't run inside a transaction holding its row lock
+ALTER TABLE sc_pismo.deliveries ADD COLUMN IF NOT EXISTS "claimed_until" TIMESTAMP NULL;
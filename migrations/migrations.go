@@ -0,0 +1,7 @@
+// Package migrations embeds the database migrations, so the code knows the schema version it expects.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
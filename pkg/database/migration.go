@@ -1,21 +1,44 @@
 package database
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
-	_ "github.com/lib/pq"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/supwr/pismo-transactions/migrations"
 	"gorm.io/gorm"
+	"io/fs"
 	"log/slog"
+	"sync"
 )
 
 type Migration struct {
 	cfg    Config
 	db     *gorm.DB
 	logger *slog.Logger
+
+	mu sync.Mutex
+	// instance is what Status reads the version with: the one Migrate ran, or one Status created when there's none.
+	instance *migrate.Migrate
+	// ownsInstance tells whether Status created instance, on a connection of its own it can close.
+	ownsInstance bool
+}
+
+// MigrationStatus is the version the database is migrated to and the latest one shipped with the code.
+type MigrationStatus struct {
+	Version  uint
+	Expected uint
+	Dirty    bool
+}
+
+// UpToDate tells whether the database has every migration the code expects. A newer schema is fine, as migrations
+// must keep working with the code running before them.
+func (s *MigrationStatus) UpToDate() bool {
+	return !s.Dirty && s.Version >= s.Expected
 }
 
 func NewMigration(db *gorm.DB, cfg Config, log *slog.Logger) *Migration {
@@ -42,6 +65,12 @@ func (m *Migration) Migrate() {
 	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
 		m.logger.Error("error executing migration", slog.Any("error", err))
 	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closeInstance()
+	m.instance, m.ownsInstance = migration, false
 }
 
 func (m *Migration) getMigrationInstance(dir string) (*migrate.Migrate, error) {
@@ -65,3 +94,138 @@ func (m *Migration) getDriver() (database.Driver, error) {
 		SchemaName: m.cfg.DatabaseSchema,
 	})
 }
+
+// Status compares the version of the database with the latest migration embedded in the binary. The version is read
+// by the migrate instance, on its own goroutine, so a slow database doesn't hold the caller past ctx.
+func (m *Migration) Status(ctx context.Context) (*MigrationStatus, error) {
+	instance, err := m.getInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		version uint
+		dirty   bool
+		err     error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		var r result
+		r.version, r.dirty, r.err = instance.Version()
+		done <- r
+	}()
+
+	var r result
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r = <-done:
+	}
+
+	if r.err != nil && !errors.Is(r.err, migrate.ErrNilVersion) {
+		// the connection may be gone, start over with a new one next time
+		m.mu.Lock()
+		if m.instance == instance {
+			m.closeInstance()
+		}
+		m.mu.Unlock()
+
+		return nil, r.err
+	}
+
+	status := &MigrationStatus{Version: r.version, Dirty: r.dirty}
+
+	if status.Expected, err = latestVersion(); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// getInstance returns the migrate instance Migrate ran, creating one for the embedded migrations when there's none.
+func (m *Migration) getInstance(ctx context.Context) (*migrate.Migrate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.instance != nil {
+		return m.instance, nil
+	}
+
+	instance, err := m.getStatusInstance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.instance, m.ownsInstance = instance, true
+
+	return instance, nil
+}
+
+// getStatusInstance creates a migrate instance of the embedded migrations on a connection of its own. Unlike
+// getDriver, closing it closes only that connection and not the whole pool.
+func (m *Migration) getStatusInstance(ctx context.Context) (*migrate.Migrate, error) {
+	db, err := m.db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{
+		SchemaName: m.cfg.DatabaseSchema,
+	})
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		_ = driver.Close()
+		return nil, err
+	}
+
+	return migrate.NewWithInstance("iofs", source, m.cfg.DatabaseDBName, driver)
+}
+
+// closeInstance drops the instance, closing it when Status created it. The one Migrate ran shares the pool, so it's
+// left open. It must be called with mu held.
+func (m *Migration) closeInstance() {
+	if m.instance != nil && m.ownsInstance {
+		_, _ = m.instance.Close()
+	}
+
+	m.instance, m.ownsInstance = nil, false
+}
+
+func latestVersion() (uint, error) {
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		version = next
+	}
+}
@@ -8,6 +8,7 @@ func Module() fx.Option {
 			NewConfig,
 			NewConnection,
 			NewMigration,
+			NewTxManager,
 		),
 	)
 }
@@ -1,14 +1,18 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"go.uber.org/fx"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 )
 
-func NewConnection(cfg Config) (*gorm.DB, error) {
+// NewConnection opens the connection pool, which is closed when the app stops. Since everything else depends on
+// it, it's closed only after whatever uses it has stopped.
+func NewConnection(lc fx.Lifecycle, cfg Config) (*gorm.DB, error) {
 	var err error
 	var conn *gorm.DB
 
@@ -16,7 +20,8 @@ func NewConnection(cfg Config) (*gorm.DB, error) {
 		cfg.DatabaseHost, cfg.DatabaseUsername, cfg.DatabasePassword, cfg.DatabaseDBName, cfg.DatabasePort, cfg.DatabaseSchema)
 
 	conn, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: gormlogger.Discard,
+		Logger:         gormlogger.Discard,
+		TranslateError: true,
 		NamingStrategy: schema.NamingStrategy{
 			TablePrefix:   fmt.Sprintf("%s.", cfg.DatabaseSchema),
 			SingularTable: false,
@@ -37,5 +42,11 @@ func NewConnection(cfg Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return db.Close()
+		},
+	})
+
 	return conn, nil
 }
@@ -0,0 +1,73 @@
+//go:generate mockgen -destination=mock/tx.go -source=tx.go -package=mock
+package database
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// tx is the transaction bound to a context, along with what must run once it commits.
+type tx struct {
+	db          *gorm.DB
+	afterCommit []func()
+}
+
+type TxManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	AfterCommit(ctx context.Context, fn func())
+}
+
+type txManager struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManager{db: db}
+}
+
+// WithinTransaction runs fn inside a database transaction carried by the context handed to it.
+// Calls made while a transaction is already open join it instead of starting a new one, so the
+// outermost caller decides when everything commits or rolls back.
+func (m *txManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*tx); ok {
+		return fn(ctx)
+	}
+
+	t := &tx{}
+	err := m.db.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+		t.db = db
+		return fn(context.WithValue(ctx, txKey{}, t))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range t.afterCommit {
+		f()
+	}
+
+	return nil
+}
+
+// AfterCommit runs fn once the outermost transaction bound to ctx commits, and never if it rolls back. Without a
+// transaction, fn runs right away.
+func (m *txManager) AfterCommit(ctx context.Context, fn func()) {
+	if t, ok := ctx.Value(txKey{}).(*tx); ok {
+		t.afterCommit = append(t.afterCommit, fn)
+		return
+	}
+
+	fn()
+}
+
+// Conn returns the transaction bound to ctx, falling back to db when there is none. Statements run on it carry
+// ctx, so they are cancelled along with it and traced as part of the caller's span.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if t, ok := ctx.Value(txKey{}).(*tx); ok {
+		return t.db.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,22 @@
+package logging
+
+import (
+	"github.com/kelseyhightower/envconfig"
+	"log/slog"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+type Config struct {
+	// Level is the least severe level logged: debug, info, warn or error.
+	Level  slog.Level `envconfig:"log_level" default:"info"`
+	Format string     `envconfig:"log_format" default:"json"`
+}
+
+func NewConfig() (cfg Config, err error) {
+	err = envconfig.Process("", &cfg)
+	return
+}
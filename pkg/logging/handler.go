@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+var ErrUnknownFormat = errors.New("Unknown log format")
+
+// NewHandler writes the records of cfg.Level and above to w, as JSON or text. Records logged with the context of
+// a request, like through ErrorContext, carry its request id.
+func NewHandler(w io.Writer, cfg Config) (slog.Handler, error) {
+	options := &slog.HandlerOptions{Level: cfg.Level}
+
+	switch cfg.Format {
+	case FormatJSON:
+		return &requestIDHandler{Handler: slog.NewJSONHandler(w, options)}, nil
+	case FormatText:
+		return &requestIDHandler{Handler: slog.NewTextHandler(w, options)}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, cfg.Format)
+}
+
+type requestIDHandler struct {
+	slog.Handler
+}
+
+func (h *requestIDHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &requestIDHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *requestIDHandler) WithGroup(name string) slog.Handler {
+	return &requestIDHandler{Handler: h.Handler.WithGroup(name)}
+}
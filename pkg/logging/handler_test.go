@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestNewHandler(t *testing.T) {
+	t.Run("log records of the level or above with the request id", func(t *testing.T) {
+		var out bytes.Buffer
+
+		h, err := NewHandler(&out, Config{Level: slog.LevelWarn, Format: FormatJSON})
+		assert.NoError(t, err)
+
+		logger := slog.New(h).With("component", "test")
+		ctx := WithRequestID(context.Background(), "abc123")
+
+		logger.InfoContext(ctx, "ignored")
+		logger.WarnContext(ctx, "logged")
+
+		assert.NotContains(t, out.String(), "ignored")
+		assert.Contains(t, out.String(), `"msg":"logged"`)
+		assert.Contains(t, out.String(), `"component":"test"`)
+		assert.Contains(t, out.String(), `"request_id":"abc123"`)
+	})
+
+	t.Run("log as text", func(t *testing.T) {
+		var out bytes.Buffer
+
+		h, err := NewHandler(&out, Config{Level: slog.LevelInfo, Format: FormatText})
+		assert.NoError(t, err)
+
+		slog.New(h).Info("logged")
+
+		assert.Contains(t, out.String(), "msg=logged")
+		assert.NotContains(t, out.String(), "request_id")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		h, err := NewHandler(&bytes.Buffer{}, Config{Format: "xml"})
+
+		assert.Nil(t, h)
+		assert.ErrorIs(t, err, ErrUnknownFormat)
+	})
+}
@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// serve answers with the request id the handler found in the request context
+	serve := func(id string) *httptest.ResponseRecorder {
+		api := gin.New()
+		api.Use(RequestIDMiddleware())
+		api.GET("/", func(ctx *gin.Context) {
+			ctx.String(http.StatusOK, RequestID(ctx.Request.Context()))
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if id != "" {
+			req.Header.Set(RequestIDHeader, id)
+		}
+
+		res := httptest.NewRecorder()
+		api.ServeHTTP(res, req)
+
+		return res
+	}
+
+	t.Run("keep the id sent by the client", func(t *testing.T) {
+		res := serve("abc-123")
+
+		assert.Equal(t, "abc-123", res.Body.String())
+		assert.Equal(t, "abc-123", res.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("generate an id when there is none", func(t *testing.T) {
+		res := serve("")
+
+		assert.Len(t, res.Body.String(), 32)
+		assert.Equal(t, res.Body.String(), res.Header().Get(RequestIDHeader))
+		assert.NotEqual(t, res.Body.String(), serve("").Body.String())
+	})
+
+	t.Run("replace invalid ids", func(t *testing.T) {
+		for _, id := range []string{"forged\nline", "with space", strings.Repeat("a", 129)} {
+			res := serve(id)
+
+			assert.NotEqual(t, id, res.Body.String())
+			assert.Len(t, res.Body.String(), 32)
+		}
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// serve logs the requests to everything but /healthz as JSON and returns the records logged
+	serve := func(path string, handler gin.HandlerFunc) []map[string]any {
+		var out bytes.Buffer
+
+		h, _ := NewHandler(&out, Config{Level: slog.LevelInfo, Format: FormatJSON})
+		logger := slog.New(h)
+
+		api := gin.New()
+		api.Use(RequestIDMiddleware(), AccessLog(logger, func(r *http.Request) bool {
+			return r.URL.Path != "/healthz"
+		}), Recovery(logger))
+		api.GET("/accounts/:accountId", handler)
+		api.GET("/healthz", handler)
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(RequestIDHeader, "abc123")
+		api.ServeHTTP(httptest.NewRecorder(), req)
+
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			if line == "" {
+				continue
+			}
+
+			var record map[string]any
+			assert.NoError(t, json.Unmarshal([]byte(line), &record))
+			records = append(records, record)
+		}
+
+		return records
+	}
+
+	t.Run("log a record per request", func(t *testing.T) {
+		records := serve("/accounts/1", func(ctx *gin.Context) {
+			ctx.String(http.StatusOK, "ok")
+		})
+
+		assert.Len(t, records, 1)
+		assert.Equal(t, "INFO", records[0]["level"])
+		assert.Equal(t, "request", records[0]["msg"])
+		assert.Equal(t, "abc123", records[0]["request_id"])
+		assert.Equal(t, "GET", records[0]["method"])
+		assert.Equal(t, "/accounts/1", records[0]["path"])
+		assert.Equal(t, "/accounts/:accountId", records[0]["route"])
+		assert.Equal(t, float64(http.StatusOK), records[0]["status"])
+		assert.Equal(t, float64(2), records[0]["bytes"])
+		assert.Contains(t, records[0], "duration_ms")
+	})
+
+	t.Run("log server errors as errors", func(t *testing.T) {
+		records := serve("/accounts/1", func(ctx *gin.Context) {
+			panic("boom")
+		})
+
+		assert.Len(t, records, 2)
+
+		assert.Equal(t, "handler panicked", records[0]["msg"])
+		assert.Equal(t, "boom", records[0]["panic"])
+		assert.Equal(t, "abc123", records[0]["request_id"])
+
+		assert.Equal(t, "ERROR", records[1]["level"])
+		assert.Equal(t, float64(http.StatusInternalServerError), records[1]["status"])
+		assert.Equal(t, "panic: boom", records[1]["error"])
+	})
+
+	t.Run("skip requests that aren't logged", func(t *testing.T) {
+		records := serve("/healthz", func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		assert.Empty(t, records)
+	})
+}
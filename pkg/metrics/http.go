@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute labels the requests to routes that don't exist, so scanners can't blow up the number of series.
+const unmatchedRoute = "unmatched"
+
+type HTTP struct {
+	duration *prometheus.HistogramVec
+}
+
+func NewHTTP(r prometheus.Registerer) *HTTP {
+	h := &HTTP{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the HTTP requests, by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+	}
+
+	r.MustRegister(h.duration)
+
+	return h
+}
+
+// Middleware observes the duration of every request. Requests are labelled with the route pattern, like
+// /accounts/:accountId, rather than the path, which would make a series per id.
+func (h *HTTP) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		h.duration.
+			WithLabelValues(ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
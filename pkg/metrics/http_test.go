@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTP_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// requests counts the requests observed with the route and status labels
+	requests := func(r *prometheus.Registry, route string, status string) uint64 {
+		families, _ := r.Gather()
+
+		for _, f := range families {
+			if f.GetName() != "pismo_http_request_duration_seconds" {
+				continue
+			}
+
+			for _, m := range f.GetMetric() {
+				labels := map[string]string{}
+				for _, l := range m.GetLabel() {
+					labels[l.GetName()] = l.GetValue()
+				}
+
+				if labels["route"] == route && labels["status"] == status {
+					return m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+
+		return 0
+	}
+
+	t.Run("observe requests by route pattern and status", func(t *testing.T) {
+		r := prometheus.NewRegistry()
+		h := NewHTTP(r)
+
+		api := gin.New()
+		api.Use(h.Middleware())
+		api.GET("/accounts/:accountId", func(ctx *gin.Context) {
+			if ctx.Param("accountId") == "0" {
+				ctx.Status(http.StatusNotFound)
+				return
+			}
+
+			ctx.Status(http.StatusOK)
+		})
+
+		for _, path := range []string{"/accounts/1", "/accounts/2", "/accounts/0", "/unknown/1", "/unknown/2"} {
+			api.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		assert.Equal(t, uint64(2), requests(r, "/accounts/:accountId", "200"))
+		assert.Equal(t, uint64(1), requests(r, "/accounts/:accountId", "404"))
+		assert.Equal(t, uint64(2), requests(r, unmatchedRoute, "404"))
+	})
+}
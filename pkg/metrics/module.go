@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+)
+
+func Module() fx.Option {
+	return fx.Module("metrics",
+		fx.Provide(
+			NewRegistry,
+			NewHTTP,
+			newRegisterer,
+		),
+		fx.Invoke(RegisterDBStats),
+	)
+}
+
+func newRegisterer(r *prometheus.Registry) prometheus.Registerer {
+	return r
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/supwr/pismo-transactions/pkg/database"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// Namespace prefixes the metrics of the application, telling them apart from the Go runtime and process ones.
+const Namespace = "pismo"
+
+// NewRegistry creates the registry every metric is registered to, along with the Go runtime and process metrics.
+func NewRegistry() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return r
+}
+
+// RegisterDBStats exports the connection pool stats of db, such as open, in use and idle connections and how long
+// callers waited for one.
+func RegisterDBStats(r prometheus.Registerer, db *gorm.DB, cfg database.Config) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+
+	return r.Register(collectors.NewDBStatsCollector(sqlDB, cfg.DatabaseDBName))
+}
+
+// Handler serves the metrics of r in the Prometheus exposition format.
+func Handler(r *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(r, promhttp.HandlerOpts{Registry: r})
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"github.com/kelseyhightower/envconfig"
+	"time"
+)
+
+const defaultPort = "8000"
+
+type Config struct {
+	Addr              string        `envconfig:"http_addr"`
+	ReadHeaderTimeout time.Duration `envconfig:"http_read_header_timeout" default:"5s"`
+	ReadTimeout       time.Duration `envconfig:"http_read_timeout" default:"10s"`
+	WriteTimeout      time.Duration `envconfig:"http_write_timeout" default:"30s"`
+	IdleTimeout       time.Duration `envconfig:"http_idle_timeout" default:"60s"`
+	// ShutdownDelay is how long the server keeps serving, while failing readiness checks, before it starts
+	// shutting down, giving load balancers time to stop sending it requests.
+	ShutdownDelay time.Duration `envconfig:"http_shutdown_delay" default:"5s"`
+	// ShutdownTimeout is how long in-flight requests have to finish once the server is asked to stop.
+	ShutdownTimeout time.Duration `envconfig:"http_shutdown_timeout" default:"10s"`
+	// Port is what the server listened on before HTTP_ADDR. It's still honoured when HTTP_ADDR isn't set.
+	Port string `envconfig:"port"`
+}
+
+func NewConfig() (cfg Config, err error) {
+	if err = envconfig.Process("", &cfg); err != nil {
+		return
+	}
+
+	if cfg.Addr == "" {
+		port := cfg.Port
+		if port == "" {
+			port = defaultPort
+		}
+
+		cfg.Addr = ":" + port
+	}
+
+	return
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewConfig(t *testing.T) {
+	cases := map[string]struct {
+		addr string
+		port string
+		want string
+	}{
+		"address":                {addr: "127.0.0.1:9000", want: "127.0.0.1:9000"},
+		"address wins over port": {addr: ":9000", port: "8080", want: ":9000"},
+		"port without address":   {port: "8080", want: ":8080"},
+		"neither":                {want: ":8000"},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("HTTP_ADDR", c.addr)
+			t.Setenv("PORT", c.port)
+
+			cfg, err := NewConfig()
+
+			assert.Nil(t, err)
+			assert.Equal(t, c.want, cfg.Addr)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/fx"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// NewServer creates the HTTP server of handler and ties it to the app lifecycle. It starts listening when the app
+// starts. When the app stops, it flags state as stopping and keeps serving for cfg.ShutdownDelay, then stops
+// accepting connections and waits up to cfg.ShutdownTimeout for the requests in flight before closing the ones left.
+func NewServer(
+	lc fx.Lifecycle,
+	s fx.Shutdowner,
+	cfg Config,
+	state *State,
+	handler http.Handler,
+	logger *slog.Logger,
+) *http.Server {
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// listening here makes a busy port fail the app start instead of the goroutine below
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+
+			// the actual address, in case the port was left for the system to pick
+			srv.Addr = ln.Addr().String()
+			logger.InfoContext(ctx, "http server listening", slog.String("addr", srv.Addr))
+
+			go func() {
+				if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Error("http server stopped unexpectedly", slog.Any("error", err))
+					_ = s.Shutdown(fx.ExitCode(1))
+				}
+			}()
+
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			state.stopping.Store(true)
+
+			if cfg.ShutdownDelay > 0 {
+				logger.InfoContext(ctx, "http server stopping, waiting before shutting down", slog.Duration("delay", cfg.ShutdownDelay))
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(cfg.ShutdownDelay):
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, cfg.ShutdownTimeout)
+			defer cancel()
+
+			logger.InfoContext(ctx, "http server shutting down, draining requests in flight")
+
+			if err := srv.Shutdown(ctx); err != nil {
+				logger.ErrorContext(ctx, "requests still in flight after the shutdown timeout", slog.Any("error", err))
+				return srv.Close()
+			}
+
+			return nil
+		},
+	})
+
+	return srv
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewServer(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	newApp := func(t *testing.T, cfg Config, handler http.Handler) (*fxtest.App, *http.Server, *State) {
+		var srv *http.Server
+		var state *State
+
+		app := fxtest.New(t,
+			fx.NopLogger,
+			fx.Supply(cfg, logger),
+			fx.Provide(NewState),
+			fx.Provide(func(lc fx.Lifecycle, s fx.Shutdowner, state *State) *http.Server {
+				return NewServer(lc, s, cfg, state, handler, logger)
+			}),
+			fx.Populate(&srv, &state),
+		)
+
+		return app, srv, state
+	}
+
+	t.Run("serve until the app stops", func(t *testing.T) {
+		app, srv, _ := newApp(t, Config{Addr: "127.0.0.1:0", ShutdownTimeout: time.Second}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		}))
+
+		app.RequireStart()
+
+		res, err := http.Get("http://" + srv.Addr)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusTeapot, res.StatusCode)
+		_ = res.Body.Close()
+
+		app.RequireStop()
+
+		_, err = http.Get("http://" + srv.Addr)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("keep serving while stopping during the shutdown delay", func(t *testing.T) {
+		app, srv, state := newApp(t, Config{Addr: "127.0.0.1:0", ShutdownDelay: 300 * time.Millisecond, ShutdownTimeout: time.Second}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		app.RequireStart()
+		assert.False(t, state.Stopping())
+
+		stopped := make(chan error, 1)
+		go func() { stopped <- app.Stop(context.Background()) }()
+
+		assert.Eventually(t, state.Stopping, time.Second, 10*time.Millisecond)
+
+		res, err := http.Get("http://" + srv.Addr)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		_ = res.Body.Close()
+
+		assert.Nil(t, <-stopped)
+	})
+
+	t.Run("drain requests in flight on stop", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		app, srv, _ := newApp(t, Config{Addr: "127.0.0.1:0", ShutdownTimeout: 5 * time.Second}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		app.RequireStart()
+
+		status := make(chan int, 1)
+		go func() {
+			res, err := http.Get("http://" + srv.Addr)
+			if err != nil {
+				status <- 0
+				return
+			}
+
+			_ = res.Body.Close()
+			status <- res.StatusCode
+		}()
+
+		<-started
+
+		stopped := make(chan error, 1)
+		go func() { stopped <- app.Stop(context.Background()) }()
+
+		// the request is still running, so the app can't be done stopping yet
+		select {
+		case <-stopped:
+			t.Fatal("app stopped before the request in flight finished")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		close(release)
+
+		assert.Equal(t, http.StatusOK, <-status)
+		assert.Nil(t, <-stopped)
+	})
+
+	t.Run("close requests still in flight after the shutdown timeout", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+
+		app, srv, _ := newApp(t, Config{Addr: "127.0.0.1:0", ShutdownTimeout: 50 * time.Millisecond}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		}))
+
+		app.RequireStart()
+
+		failed := make(chan error, 1)
+		go func() {
+			_, err := http.Get("http://" + srv.Addr)
+			failed <- err
+		}()
+
+		<-started
+
+		assert.Nil(t, app.Stop(context.Background()))
+		assert.NotNil(t, <-failed)
+	})
+
+	t.Run("fail to start when the address is taken", func(t *testing.T) {
+		app, srv, _ := newApp(t, Config{Addr: "127.0.0.1:0"}, http.NotFoundHandler())
+		app.RequireStart()
+		defer app.RequireStop()
+
+		other, _, _ := newApp(t, Config{Addr: srv.Addr}, http.NotFoundHandler())
+		assert.NotNil(t, other.Start(context.Background()))
+	})
+}
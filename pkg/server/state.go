@@ -0,0 +1,17 @@
+package server
+
+import "sync/atomic"
+
+// State tells whether the server is shutting down, so readiness checks can turn away new traffic while the
+// requests in flight drain.
+type State struct {
+	stopping atomic.Bool
+}
+
+func NewState() *State {
+	return &State{}
+}
+
+func (s *State) Stopping() bool {
+	return s.stopping.Load()
+}
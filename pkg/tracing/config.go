@@ -0,0 +1,24 @@
+package tracing
+
+import "github.com/kelseyhightower/envconfig"
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+type Config struct {
+	// Exporter is where spans are sent: otlp, to the collector set by the standard OTEL_EXPORTER_OTLP_* variables,
+	// stdout, for local runs, or none, which still creates spans so logs carry trace ids.
+	Exporter    string `envconfig:"trace_exporter" default:"none"`
+	ServiceName string `envconfig:"trace_service_name" default:"pismo-transactions"`
+	// SampleRatio is the share of the traces started here that are recorded. Traces started upstream follow the
+	// caller's decision.
+	SampleRatio float64 `envconfig:"trace_sample_ratio" default:"1"`
+}
+
+func NewConfig() (cfg Config, err error) {
+	err = envconfig.Process("", &cfg)
+	return
+}
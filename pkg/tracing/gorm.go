@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"errors"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// gormPlugin starts a span for every statement GORM runs, as a child of the span in the statement's context. Only
+// the SQL with placeholders is recorded, never the values bound to it.
+type gormPlugin struct {
+	tracer trace.Tracer
+}
+
+// InstrumentDB traces the statements run through db and the sessions derived from it.
+func InstrumentDB(db *gorm.DB, tp trace.TracerProvider) error {
+	return db.Use(&gormPlugin{tracer: tp.Tracer("gorm.io/gorm")})
+}
+
+func (p *gormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", p.before("INSERT")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", p.after("INSERT")),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", p.before("SELECT")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", p.after("SELECT")),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", p.before("UPDATE")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", p.after("UPDATE")),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("DELETE")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", p.after("DELETE")),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", p.before("SELECT")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", p.after("SELECT")),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("RAW")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", p.after("RAW")),
+	)
+}
+
+func (p *gormPlugin) before(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := p.tracer.Start(db.Statement.Context, operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation)),
+		)
+
+		db.Statement.Context = ctx
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (p *gormPlugin) after(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(spanKey)
+		if !ok {
+			return
+		}
+
+		span := value.(trace.Span)
+
+		if db.Statement.Table != "" {
+			span.SetName(operation + " " + db.Statement.Table)
+			span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+		}
+
+		span.SetAttributes(
+			semconv.DBQueryText(db.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+		)
+
+		// not finding a row is an answer, not a failure
+		err := db.Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil
+		}
+
+		End(span, err)
+	}
+}
@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+)
+
+type widget struct {
+	ID   int
+	Name string
+}
+
+func TestInstrumentDB(t *testing.T) {
+	// newDB builds the statements without running them, so no database is needed
+	newDB := func(t *testing.T) (*gorm.DB, *tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+		recorder := tracetest.NewSpanRecorder()
+		tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+		db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+			DryRun:               true,
+			DisableAutomaticPing: true,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, InstrumentDB(db, tp))
+
+		return db, recorder, tp
+	}
+
+	t.Run("trace statements as children of the span in their context", func(t *testing.T) {
+		db, recorder, tp := newDB(t)
+
+		ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+		db.WithContext(ctx).Where("name = ?", "secret").Find(&[]widget{})
+		db.WithContext(ctx).Create(&widget{Name: "secret"})
+		parent.End()
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 3)
+
+		query, insert := spans[0], spans[1]
+
+		assert.Equal(t, "SELECT widgets", query.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+		assert.Contains(t, query.Attributes(), attribute.String("db.query.text", `SELECT * FROM "widgets" WHERE name = $1`))
+
+		assert.Equal(t, "INSERT widgets", insert.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), insert.Parent().SpanID())
+	})
+
+	t.Run("record failed statements", func(t *testing.T) {
+		db, recorder, _ := newDB(t)
+
+		db.Callback().Query().Before("tracing:after_query").Register("test:failure", func(db *gorm.DB) {
+			_ = db.AddError(errors.New("connection refused"))
+		})
+
+		db.WithContext(context.Background()).First(&widget{})
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Equal(t, "connection refused", spans[0].Status().Description)
+	})
+
+	t.Run("don't report missing rows as errors", func(t *testing.T) {
+		db, recorder, _ := newDB(t)
+
+		db.Callback().Query().Before("tracing:after_query").Register("test:not_found", func(db *gorm.DB) {
+			_ = db.AddError(gorm.ErrRecordNotFound)
+		})
+
+		db.WithContext(context.Background()).First(&widget{})
+
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	})
+}
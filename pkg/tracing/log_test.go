@@ -0,0 +1,36 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"log/slog"
+	"testing"
+)
+
+func TestLogHandler(t *testing.T) {
+	t.Run("add the ids of the span in context", func(t *testing.T) {
+		var out bytes.Buffer
+		logger := slog.New(NewLogHandler(slog.NewTextHandler(&out, nil))).With("component", "test")
+
+		ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "span")
+		defer span.End()
+
+		logger.ErrorContext(ctx, "failed")
+
+		assert.Contains(t, out.String(), "component=test")
+		assert.Contains(t, out.String(), "trace_id="+span.SpanContext().TraceID().String())
+		assert.Contains(t, out.String(), "span_id="+span.SpanContext().SpanID().String())
+	})
+
+	t.Run("log records without a span as they are", func(t *testing.T) {
+		var out bytes.Buffer
+		logger := slog.New(NewLogHandler(slog.NewTextHandler(&out, nil)))
+
+		logger.ErrorContext(context.Background(), "failed")
+		logger.Error("failed")
+
+		assert.NotContains(t, out.String(), "trace_id")
+	})
+}
@@ -0,0 +1,22 @@
+package tracing
+
+import (
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+)
+
+func Module() fx.Option {
+	return fx.Module("tracing",
+		fx.Provide(
+			NewConfig,
+			NewTracerProvider,
+			newTracerProvider,
+		),
+		fx.Invoke(Register, InstrumentDB),
+	)
+}
+
+func newTracerProvider(tp *sdktrace.TracerProvider) trace.TracerProvider {
+	return tp
+}